@@ -31,13 +31,25 @@ func main() {
 	authHandler := handlers.NewAuthHandler(repo)
 	employeeHandler := handlers.NewEmployeeHandler(repo)
 	positionHandler := handlers.NewPositionHandler(repo)
-	cityHandler := handlers.NewCityHandler()
+	cityHandler := handlers.NewCityHandler(repo)
 	travelRequestHandler := handlers.NewTravelRequestHandler(repo)
 	travelReportHandler := handlers.NewTravelReportHandler(repo)
 	pdfHandler := handlers.NewPDFHandler(repo)
 	excelHandler := handlers.NewExcelHandler(repo)
 	representativeHandler := handlers.NewRepresentativeHandler(repo)
 	atCostHandler := handlers.NewAtCostHandler(repo)
+	adminHandler := handlers.NewAdminHandler(repo)
+	approvalHandler := handlers.NewApprovalHandler(repo)
+	numberingHandler := handlers.NewNumberingHandler(repo)
+	amendmentHandler := handlers.NewAmendmentHandler(repo)
+	advanceHandler := handlers.NewAdvanceHandler(repo)
+	suratTugasHandler := handlers.NewSuratTugasHandler(repo)
+	quotaHandler := handlers.NewQuotaHandler(repo)
+	partialDayHandler := handlers.NewPartialDayHandler(repo)
+	holidayHandler := handlers.NewHolidayHandler(repo)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(repo)
+	policyHandler := handlers.NewPolicyHandler(repo)
+	destinationTierHandler := handlers.NewDestinationTierHandler(repo)
 	healthHandler := handlers.NewHealthHandler()
 
 	// Setup Gin router
@@ -64,11 +76,16 @@ func main() {
 		// Public access for employees to create travel request
 		public.GET("/employees", employeeHandler.GetAllEmployees)
 		public.GET("/employees/:id", employeeHandler.GetEmployeeByID)
+		public.GET("/employees/:id/quota", quotaHandler.GetEmployeeQuota)
 		public.GET("/positions", positionHandler.GetAllPositions)
 		public.GET("/cities", cityHandler.GetAllCities)
+		public.GET("/destination-tiers", destinationTierHandler.GetAllDestinationTiers)
+		public.GET("/holidays", holidayHandler.GetHolidays)
 
 		// Travel requests - public for employees to submit
 		public.POST("/travel-requests", travelRequestHandler.CreateTravelRequest)
+		public.POST("/travel-requests/preview", travelRequestHandler.PreviewTravelRequest)
+		public.POST("/travel-requests/preview/pdf", travelRequestHandler.PreviewTravelRequestPDF)
 		public.GET("/travel-requests/stats/employees", travelRequestHandler.GetEmployeeSPDStats)
 		public.GET("/travel-requests/:id", travelRequestHandler.GetTravelRequestByID)
 		public.GET("/travel-requests", travelRequestHandler.GetAllTravelRequests)
@@ -84,7 +101,12 @@ func main() {
 		public.GET("/pdf/nota-permintaan/:id", pdfHandler.DownloadNotaPermintaan)
 		public.GET("/pdf/berita-acara/:id", pdfHandler.DownloadBeritaAcara)
 		public.GET("/pdf/combined/:id", pdfHandler.DownloadCombinedPDF)
+		public.GET("/pdf/surat-tugas/:id", pdfHandler.DownloadSuratTugas)
 		public.GET("/pdf/nota-atcost/:id", atCostHandler.DownloadNotaAtCost)
+		public.GET("/pdf/amendment/:id", amendmentHandler.DownloadAmendmentLetter)
+		public.GET("/pdf/cancellation/:id", travelRequestHandler.DownloadCancellationLetter)
+		public.GET("/pdf/advance-settlement/:id", advanceHandler.DownloadAdvanceSettlement)
+		public.GET("/pdf/kuitansi/:id", pdfHandler.DownloadKuitansi)
 		public.GET("/pdf/combined-atcost/:id", atCostHandler.DownloadCombinedAtCost)
 	}
 
@@ -97,9 +119,33 @@ func main() {
 		protected.PUT("/employees/:id", employeeHandler.UpdateEmployee)
 		protected.DELETE("/employees/:id", employeeHandler.DeleteEmployee)
 
+		// Position and allowance rate management
+		protected.GET("/positions/:id", positionHandler.GetPositionByID)
+		protected.POST("/positions", positionHandler.CreatePosition)
+		protected.PUT("/positions/:id", positionHandler.UpdatePosition)
+		protected.DELETE("/positions/:id", positionHandler.DeletePosition)
+		protected.POST("/positions/:id/rates", positionHandler.CreatePositionRate)
+		protected.PUT("/positions/:id/rates/:rate_id", positionHandler.UpdatePositionRate)
+		protected.DELETE("/positions/:id/rates/:rate_id", positionHandler.DeletePositionRate)
+		protected.GET("/positions/:id/rate-changes", positionHandler.GetPositionRateChanges)
+
 		// Travel requests management
 		protected.GET("/travel-requests", travelRequestHandler.GetAllTravelRequests)
+		protected.POST("/travel-requests", travelRequestHandler.CreateTravelRequest)
+		protected.GET("/travel-requests/conflicts", travelRequestHandler.GetTravelConflicts)
+		protected.PUT("/travel-requests/:id", travelRequestHandler.UpdateTravelRequest)
 		protected.DELETE("/travel-requests/:id", travelRequestHandler.DeleteTravelRequest)
+		protected.GET("/travel-requests/:id/revisions", travelRequestHandler.GetTravelRequestRevisions)
+		protected.POST("/travel-requests/:id/amendments", amendmentHandler.CreateAmendment)
+		protected.GET("/travel-requests/:id/amendments", amendmentHandler.GetAmendments)
+		protected.POST("/travel-requests/:id/cancel", travelRequestHandler.CancelTravelRequest)
+		protected.POST("/travel-requests/:id/advance", advanceHandler.CreateAdvance)
+		protected.GET("/travel-requests/:id/advance", advanceHandler.GetAdvance)
+		protected.POST("/travel-requests/:id/advance/settle", advanceHandler.SettleAdvance)
+		protected.POST("/travel-requests/:id/surat-tugas", suratTugasHandler.IssueSuratTugas)
+		protected.GET("/travel-requests/:id/surat-tugas", suratTugasHandler.GetSuratTugas)
+		protected.PUT("/travel-requests/:id/status", travelRequestHandler.UpdateTravelRequestStatus)
+		protected.GET("/travel-requests/:id/status-history", travelRequestHandler.GetTravelRequestStatusHistory)
 
 		// Travel reports
 		protected.POST("/travel-reports", travelReportHandler.CreateTravelReport)
@@ -107,10 +153,13 @@ func main() {
 
 		// Excel export
 		protected.GET("/excel/monthly-allowance", excelHandler.ExportMonthlyAllowance)
+		protected.GET("/pdf/kuitansi/monthly", pdfHandler.DownloadMonthlyKuitansi)
 
 		// Representative config
 		protected.GET("/representative-config", representativeHandler.GetRepresentativeConfig)
 		protected.PUT("/representative-config", representativeHandler.UpdateRepresentativeConfig)
+		protected.GET("/surat-tugas-signer", suratTugasHandler.GetSignerConfig)
+		protected.PUT("/surat-tugas-signer", suratTugasHandler.UpdateSignerConfig)
 
 		// At-Cost claims
 		protected.POST("/at-cost/upload-receipt", atCostHandler.UploadReceipt)
@@ -122,6 +171,72 @@ func main() {
 		protected.DELETE("/at-cost/claims/:id", atCostHandler.DeleteAtCostClaim)
 		protected.GET("/at-cost/receipts/:receipt_id/download", atCostHandler.DownloadReceipt)
 		protected.POST("/at-cost/parse-manual", atCostHandler.ParseReceiptManual)
+
+		// Admin accounts (also used as approvers)
+		protected.GET("/admins", adminHandler.GetAllAdmins)
+		protected.POST("/admins", adminHandler.CreateAdmin)
+		protected.PUT("/admins/:id", adminHandler.UpdateAdmin)
+
+		// Approval routes and inbox
+		protected.GET("/approval-routes", approvalHandler.GetAllApprovalRoutes)
+		protected.POST("/approval-routes", approvalHandler.CreateApprovalRoute)
+		protected.PUT("/approval-routes/:id", approvalHandler.UpdateApprovalRoute)
+		protected.DELETE("/approval-routes/:id", approvalHandler.DeleteApprovalRoute)
+		protected.GET("/approvals/inbox", approvalHandler.GetInbox)
+		protected.POST("/approvals/:id/approve", approvalHandler.ApproveStep)
+		protected.POST("/approvals/:id/reject", approvalHandler.RejectStep)
+
+		// Numbering agendas (per year, document type and unit)
+		protected.GET("/numbering/sequences", numberingHandler.GetNumberSequences)
+		protected.PUT("/numbering/sequences", numberingHandler.SeedNumberSequence)
+		protected.GET("/numbering/journal", numberingHandler.GetNumberJournal)
+		protected.GET("/numbering/journal/pdf", numberingHandler.ExportNumberJournalPDF)
+		protected.GET("/numbering/journal/excel", numberingHandler.ExportNumberJournalExcel)
+
+		// Travel quota rules per position level
+		protected.GET("/quota-rules", quotaHandler.GetAllQuotaRules)
+		protected.POST("/quota-rules", quotaHandler.CreateQuotaRule)
+		protected.PUT("/quota-rules/:id", quotaHandler.UpdateQuotaRule)
+		protected.DELETE("/quota-rules/:id", quotaHandler.DeleteQuotaRule)
+
+		// Travel policy rules
+		protected.GET("/policy-rules", policyHandler.GetAllPolicyRules)
+		protected.POST("/policy-rules", policyHandler.CreatePolicyRule)
+		protected.PUT("/policy-rules/:id", policyHandler.UpdatePolicyRule)
+		protected.DELETE("/policy-rules/:id", policyHandler.DeletePolicyRule)
+
+		// Destination tiers (tarif per kota/negara di atas tarif zona)
+		protected.GET("/destination-tiers", destinationTierHandler.GetAllDestinationTiers)
+		protected.POST("/destination-tiers", destinationTierHandler.CreateDestinationTier)
+		protected.PUT("/destination-tiers/:id", destinationTierHandler.UpdateDestinationTier)
+		protected.DELETE("/destination-tiers/:id", destinationTierHandler.DeleteDestinationTier)
+
+		// City master
+		protected.GET("/cities", cityHandler.GetAllCities)
+		protected.POST("/cities", cityHandler.CreateCity)
+		protected.POST("/cities/import", cityHandler.ImportCities)
+		protected.PUT("/cities/:id", cityHandler.UpdateCity)
+		protected.DELETE("/cities/:id", cityHandler.DeleteCity)
+
+		// Partial day rules (uang harian hari berangkat/kembali dan perjalanan singkat)
+		protected.GET("/partial-day-rules", partialDayHandler.GetAllPartialDayRules)
+		protected.POST("/partial-day-rules", partialDayHandler.CreatePartialDayRule)
+		protected.PUT("/partial-day-rules/:id", partialDayHandler.UpdatePartialDayRule)
+		protected.DELETE("/partial-day-rules/:id", partialDayHandler.DeletePartialDayRule)
+
+		// Holiday calendar (libur nasional dan cuti bersama)
+		protected.GET("/holidays", holidayHandler.GetHolidays)
+		protected.POST("/holidays", holidayHandler.CreateHoliday)
+		protected.POST("/holidays/import", holidayHandler.ImportHolidays)
+		protected.PUT("/holidays/:id", holidayHandler.UpdateHoliday)
+		protected.DELETE("/holidays/:id", holidayHandler.DeleteHoliday)
+
+		// Exchange rates (kurs tengah BI) for abroad rates and receipts in a foreign currency
+		protected.GET("/exchange-rates", exchangeRateHandler.GetExchangeRates)
+		protected.POST("/exchange-rates", exchangeRateHandler.CreateExchangeRate)
+		protected.POST("/exchange-rates/import", exchangeRateHandler.ImportExchangeRates)
+		protected.PUT("/exchange-rates/:id", exchangeRateHandler.UpdateExchangeRate)
+		protected.DELETE("/exchange-rates/:id", exchangeRateHandler.DeleteExchangeRate)
 	}
 
 	// Start server
@@ -1,180 +1,276 @@
 package database
 
-// CityData represents a city or country destination
+// CityData represents a city or a foreign destination of the city master seed
 type CityData struct {
-	Name            string
-	DestinationType string // "in_province", "outside_province", "abroad"
+	Name         string
+	ProvinceCode string // Kode provinsi BPS, kosong untuk luar negeri
+	CountryCode  string // Kode negara ISO 3166-1 alpha-2
 }
 
-// AllCities contains Indonesian cities grouped by destination type and foreign countries
+// ProvinceNames maps BPS province codes to province names
+var ProvinceNames = map[string]string{
+	"11": "Aceh",
+	"12": "Sumatera Utara",
+	"13": "Sumatera Barat",
+	"14": "Riau",
+	"15": "Jambi",
+	"16": "Sumatera Selatan",
+	"17": "Bengkulu",
+	"18": "Lampung",
+	"19": "Kepulauan Bangka Belitung",
+	"21": "Kepulauan Riau",
+	"31": "DKI Jakarta",
+	"32": "Jawa Barat",
+	"33": "Jawa Tengah",
+	"34": "DI Yogyakarta",
+	"35": "Jawa Timur",
+	"36": "Banten",
+	"51": "Bali",
+	"52": "Nusa Tenggara Barat",
+	"53": "Nusa Tenggara Timur",
+	"61": "Kalimantan Barat",
+	"62": "Kalimantan Tengah",
+	"63": "Kalimantan Selatan",
+	"64": "Kalimantan Timur",
+	"65": "Kalimantan Utara",
+	"71": "Sulawesi Utara",
+	"72": "Sulawesi Tengah",
+	"73": "Sulawesi Selatan",
+	"74": "Sulawesi Tenggara",
+	"75": "Gorontalo",
+	"76": "Sulawesi Barat",
+	"81": "Maluku",
+	"82": "Maluku Utara",
+	"91": "Papua",
+	"92": "Papua Barat",
+}
+
+// CityTimeZones sets the time zone of cities that are not in the usual zone of their country
+var CityTimeZones = map[string]string{
+	"Los Angeles, Amerika Serikat":   "America/Los_Angeles",
+	"San Francisco, Amerika Serikat": "America/Los_Angeles",
+	"Vancouver, Kanada":              "America/Vancouver",
+	"Perth, Australia":               "Australia/Perth",
+}
+
+// CountryNames maps ISO country codes to Indonesian country names
+var CountryNames = map[string]string{
+	"ID": "Indonesia",
+	"SG": "Singapura",
+	"MY": "Malaysia",
+	"TH": "Thailand",
+	"PH": "Filipina",
+	"VN": "Vietnam",
+	"MM": "Myanmar",
+	"KH": "Kamboja",
+	"LA": "Laos",
+	"BN": "Brunei",
+	"JP": "Jepang",
+	"KR": "Korea Selatan",
+	"CN": "China",
+	"HK": "Hong Kong",
+	"TW": "Taiwan",
+	"IN": "India",
+	"BD": "Bangladesh",
+	"PK": "Pakistan",
+	"LK": "Sri Lanka",
+	"AE": "UAE",
+	"SA": "Arab Saudi",
+	"QA": "Qatar",
+	"KW": "Kuwait",
+	"OM": "Oman",
+	"GB": "Inggris",
+	"FR": "Prancis",
+	"DE": "Jerman",
+	"NL": "Belanda",
+	"BE": "Belgia",
+	"IT": "Italia",
+	"ES": "Spanyol",
+	"RU": "Rusia",
+	"AT": "Austria",
+	"CH": "Swiss",
+	"US": "Amerika Serikat",
+	"CA": "Kanada",
+	"MX": "Meksiko",
+	"BR": "Brazil",
+	"AR": "Argentina",
+	"AU": "Australia",
+	"NZ": "Selandia Baru",
+	"EG": "Mesir",
+	"ZA": "Afrika Selatan",
+	"KE": "Kenya",
+	"NG": "Nigeria",
+}
+
+// AllCities is the initial content of the city master, grouped by region
 var AllCities = []CityData{
-	// Dalam Provinsi Jawa Timur
-	{"Surabaya", "in_province"},
-	{"Malang", "in_province"},
-	{"Sidoarjo", "in_province"},
-	{"Gresik", "in_province"},
-	{"Mojokerto", "in_province"},
-	{"Pasuruan", "in_province"},
-	{"Probolinggo", "in_province"},
-	{"Blitar", "in_province"},
-	{"Kediri", "in_province"},
-	{"Madiun", "in_province"},
-	{"Banyuwangi", "in_province"},
-	{"Jember", "in_province"},
-	{"Situbondo", "in_province"},
-	{"Bondowoso", "in_province"},
-	{"Lumajang", "in_province"},
-	{"Tulungagung", "in_province"},
-	{"Nganjuk", "in_province"},
-	{"Jombang", "in_province"},
-	{"Bojonegoro", "in_province"},
-	{"Tuban", "in_province"},
-	{"Lamongan", "in_province"},
-	{"Bangkalan", "in_province"},
-	{"Sampang", "in_province"},
-	{"Pamekasan", "in_province"},
-	{"Sumenep", "in_province"},
-	{"Ngawi", "in_province"},
-	{"Magetan", "in_province"},
-	{"Ponorogo", "in_province"},
-	{"Pacitan", "in_province"},
-	{"Trenggalek", "in_province"},
-	{"Batu", "in_province"},
-
-	// Luar Provinsi - Pulau Jawa
-	{"Jakarta", "outside_province"},
-	{"Bandung", "outside_province"},
-	{"Semarang", "outside_province"},
-	{"Yogyakarta", "outside_province"},
-	{"Solo (Surakarta)", "outside_province"},
-	{"Bekasi", "outside_province"},
-	{"Tangerang", "outside_province"},
-	{"Depok", "outside_province"},
-	{"Bogor", "outside_province"},
-	{"Cirebon", "outside_province"},
-	{"Sukabumi", "outside_province"},
-	{"Tasikmalaya", "outside_province"},
-	{"Purwokerto", "outside_province"},
-	{"Tegal", "outside_province"},
-	{"Pekalongan", "outside_province"},
-	{"Magelang", "outside_province"},
-	{"Salatiga", "outside_province"},
-	{"Serang", "outside_province"},
-	{"Cilegon", "outside_province"},
-
-	// Luar Provinsi - Sumatera
-	{"Medan", "outside_province"},
-	{"Palembang", "outside_province"},
-	{"Pekanbaru", "outside_province"},
-	{"Padang", "outside_province"},
-	{"Bandar Lampung", "outside_province"},
-	{"Jambi", "outside_province"},
-	{"Bengkulu", "outside_province"},
-	{"Banda Aceh", "outside_province"},
-	{"Batam", "outside_province"},
-	{"Dumai", "outside_province"},
-	{"Bukittinggi", "outside_province"},
-	{"Tanjung Pinang", "outside_province"},
-
-	// Luar Provinsi - Kalimantan
-	{"Balikpapan", "outside_province"},
-	{"Samarinda", "outside_province"},
-	{"Banjarmasin", "outside_province"},
-	{"Pontianak", "outside_province"},
-	{"Palangkaraya", "outside_province"},
-	{"Tarakan", "outside_province"},
-	{"Bontang", "outside_province"},
-	{"Singkawang", "outside_province"},
-
-	// Luar Provinsi - Sulawesi
-	{"Makassar", "outside_province"},
-	{"Manado", "outside_province"},
-	{"Palu", "outside_province"},
-	{"Kendari", "outside_province"},
-	{"Gorontalo", "outside_province"},
-	{"Mamuju", "outside_province"},
-
-	// Luar Provinsi - Bali & Nusa Tenggara
-	{"Denpasar", "outside_province"},
-	{"Mataram", "outside_province"},
-	{"Kupang", "outside_province"},
-
-	// Luar Provinsi - Maluku & Papua
-	{"Ambon", "outside_province"},
-	{"Ternate", "outside_province"},
-	{"Jayapura", "outside_province"},
-	{"Sorong", "outside_province"},
-	{"Manokwari", "outside_province"},
+	// Jawa Timur
+	{"Surabaya", "35", "ID"},
+	{"Malang", "35", "ID"},
+	{"Sidoarjo", "35", "ID"},
+	{"Gresik", "35", "ID"},
+	{"Mojokerto", "35", "ID"},
+	{"Pasuruan", "35", "ID"},
+	{"Probolinggo", "35", "ID"},
+	{"Blitar", "35", "ID"},
+	{"Kediri", "35", "ID"},
+	{"Madiun", "35", "ID"},
+	{"Banyuwangi", "35", "ID"},
+	{"Jember", "35", "ID"},
+	{"Situbondo", "35", "ID"},
+	{"Bondowoso", "35", "ID"},
+	{"Lumajang", "35", "ID"},
+	{"Tulungagung", "35", "ID"},
+	{"Nganjuk", "35", "ID"},
+	{"Jombang", "35", "ID"},
+	{"Bojonegoro", "35", "ID"},
+	{"Tuban", "35", "ID"},
+	{"Lamongan", "35", "ID"},
+	{"Bangkalan", "35", "ID"},
+	{"Sampang", "35", "ID"},
+	{"Pamekasan", "35", "ID"},
+	{"Sumenep", "35", "ID"},
+	{"Ngawi", "35", "ID"},
+	{"Magetan", "35", "ID"},
+	{"Ponorogo", "35", "ID"},
+	{"Pacitan", "35", "ID"},
+	{"Trenggalek", "35", "ID"},
+	{"Batu", "35", "ID"},
+
+	// Pulau Jawa
+	{"Jakarta", "31", "ID"},
+	{"Bandung", "32", "ID"},
+	{"Semarang", "33", "ID"},
+	{"Yogyakarta", "34", "ID"},
+	{"Solo (Surakarta)", "33", "ID"},
+	{"Bekasi", "32", "ID"},
+	{"Tangerang", "36", "ID"},
+	{"Depok", "32", "ID"},
+	{"Bogor", "32", "ID"},
+	{"Cirebon", "32", "ID"},
+	{"Sukabumi", "32", "ID"},
+	{"Tasikmalaya", "32", "ID"},
+	{"Purwokerto", "33", "ID"},
+	{"Tegal", "33", "ID"},
+	{"Pekalongan", "33", "ID"},
+	{"Magelang", "33", "ID"},
+	{"Salatiga", "33", "ID"},
+	{"Serang", "36", "ID"},
+	{"Cilegon", "36", "ID"},
+
+	// Sumatera
+	{"Medan", "12", "ID"},
+	{"Palembang", "16", "ID"},
+	{"Pekanbaru", "14", "ID"},
+	{"Padang", "13", "ID"},
+	{"Bandar Lampung", "18", "ID"},
+	{"Jambi", "15", "ID"},
+	{"Bengkulu", "17", "ID"},
+	{"Banda Aceh", "11", "ID"},
+	{"Batam", "21", "ID"},
+	{"Dumai", "14", "ID"},
+	{"Bukittinggi", "13", "ID"},
+	{"Tanjung Pinang", "21", "ID"},
+
+	// Kalimantan
+	{"Balikpapan", "64", "ID"},
+	{"Samarinda", "64", "ID"},
+	{"Banjarmasin", "63", "ID"},
+	{"Pontianak", "61", "ID"},
+	{"Palangkaraya", "62", "ID"},
+	{"Tarakan", "65", "ID"},
+	{"Bontang", "64", "ID"},
+	{"Singkawang", "61", "ID"},
+
+	// Sulawesi
+	{"Makassar", "73", "ID"},
+	{"Manado", "71", "ID"},
+	{"Palu", "72", "ID"},
+	{"Kendari", "74", "ID"},
+	{"Gorontalo", "75", "ID"},
+	{"Mamuju", "76", "ID"},
+
+	// Bali & Nusa Tenggara
+	{"Denpasar", "51", "ID"},
+	{"Mataram", "52", "ID"},
+	{"Kupang", "53", "ID"},
+
+	// Maluku & Papua
+	{"Ambon", "81", "ID"},
+	{"Ternate", "82", "ID"},
+	{"Jayapura", "91", "ID"},
+	{"Sorong", "92", "ID"},
+	{"Manokwari", "92", "ID"},
 
 	// Luar Negeri - Asia Tenggara
-	{"Singapura", "abroad"},
-	{"Kuala Lumpur, Malaysia", "abroad"},
-	{"Bangkok, Thailand", "abroad"},
-	{"Manila, Filipina", "abroad"},
-	{"Hanoi, Vietnam", "abroad"},
-	{"Ho Chi Minh, Vietnam", "abroad"},
-	{"Yangon, Myanmar", "abroad"},
-	{"Phnom Penh, Kamboja", "abroad"},
-	{"Vientiane, Laos", "abroad"},
-	{"Bandar Seri Begawan, Brunei", "abroad"},
+	{"Singapura", "", "SG"},
+	{"Kuala Lumpur, Malaysia", "", "MY"},
+	{"Bangkok, Thailand", "", "TH"},
+	{"Manila, Filipina", "", "PH"},
+	{"Hanoi, Vietnam", "", "VN"},
+	{"Ho Chi Minh, Vietnam", "", "VN"},
+	{"Yangon, Myanmar", "", "MM"},
+	{"Phnom Penh, Kamboja", "", "KH"},
+	{"Vientiane, Laos", "", "LA"},
+	{"Bandar Seri Begawan, Brunei", "", "BN"},
 
 	// Luar Negeri - Asia Timur
-	{"Tokyo, Jepang", "abroad"},
-	{"Seoul, Korea Selatan", "abroad"},
-	{"Beijing, China", "abroad"},
-	{"Shanghai, China", "abroad"},
-	{"Hong Kong", "abroad"},
-	{"Taipei, Taiwan", "abroad"},
+	{"Tokyo, Jepang", "", "JP"},
+	{"Seoul, Korea Selatan", "", "KR"},
+	{"Beijing, China", "", "CN"},
+	{"Shanghai, China", "", "CN"},
+	{"Hong Kong", "", "HK"},
+	{"Taipei, Taiwan", "", "TW"},
 
 	// Luar Negeri - Asia Selatan & Tengah
-	{"New Delhi, India", "abroad"},
-	{"Mumbai, India", "abroad"},
-	{"Dhaka, Bangladesh", "abroad"},
-	{"Karachi, Pakistan", "abroad"},
-	{"Colombo, Sri Lanka", "abroad"},
+	{"New Delhi, India", "", "IN"},
+	{"Mumbai, India", "", "IN"},
+	{"Dhaka, Bangladesh", "", "BD"},
+	{"Karachi, Pakistan", "", "PK"},
+	{"Colombo, Sri Lanka", "", "LK"},
 
 	// Luar Negeri - Timur Tengah
-	{"Dubai, UAE", "abroad"},
-	{"Abu Dhabi, UAE", "abroad"},
-	{"Riyadh, Arab Saudi", "abroad"},
-	{"Jeddah, Arab Saudi", "abroad"},
-	{"Doha, Qatar", "abroad"},
-	{"Kuwait City, Kuwait", "abroad"},
-	{"Muscat, Oman", "abroad"},
+	{"Dubai, UAE", "", "AE"},
+	{"Abu Dhabi, UAE", "", "AE"},
+	{"Riyadh, Arab Saudi", "", "SA"},
+	{"Jeddah, Arab Saudi", "", "SA"},
+	{"Doha, Qatar", "", "QA"},
+	{"Kuwait City, Kuwait", "", "KW"},
+	{"Muscat, Oman", "", "OM"},
 
 	// Luar Negeri - Eropa
-	{"London, Inggris", "abroad"},
-	{"Paris, Prancis", "abroad"},
-	{"Berlin, Jerman", "abroad"},
-	{"Amsterdam, Belanda", "abroad"},
-	{"Brussels, Belgia", "abroad"},
-	{"Roma, Italia", "abroad"},
-	{"Madrid, Spanyol", "abroad"},
-	{"Moskow, Rusia", "abroad"},
-	{"Wina, Austria", "abroad"},
-	{"Zurich, Swiss", "abroad"},
+	{"London, Inggris", "", "GB"},
+	{"Paris, Prancis", "", "FR"},
+	{"Berlin, Jerman", "", "DE"},
+	{"Amsterdam, Belanda", "", "NL"},
+	{"Brussels, Belgia", "", "BE"},
+	{"Roma, Italia", "", "IT"},
+	{"Madrid, Spanyol", "", "ES"},
+	{"Moskow, Rusia", "", "RU"},
+	{"Wina, Austria", "", "AT"},
+	{"Zurich, Swiss", "", "CH"},
 
 	// Luar Negeri - Amerika
-	{"New York, Amerika Serikat", "abroad"},
-	{"Washington DC, Amerika Serikat", "abroad"},
-	{"Los Angeles, Amerika Serikat", "abroad"},
-	{"San Francisco, Amerika Serikat", "abroad"},
-	{"Toronto, Kanada", "abroad"},
-	{"Vancouver, Kanada", "abroad"},
-	{"Mexico City, Meksiko", "abroad"},
-	{"Sao Paulo, Brazil", "abroad"},
-	{"Buenos Aires, Argentina", "abroad"},
+	{"New York, Amerika Serikat", "", "US"},
+	{"Washington DC, Amerika Serikat", "", "US"},
+	{"Los Angeles, Amerika Serikat", "", "US"},
+	{"San Francisco, Amerika Serikat", "", "US"},
+	{"Toronto, Kanada", "", "CA"},
+	{"Vancouver, Kanada", "", "CA"},
+	{"Mexico City, Meksiko", "", "MX"},
+	{"Sao Paulo, Brazil", "", "BR"},
+	{"Buenos Aires, Argentina", "", "AR"},
 
 	// Luar Negeri - Australia & Oseania
-	{"Sydney, Australia", "abroad"},
-	{"Melbourne, Australia", "abroad"},
-	{"Perth, Australia", "abroad"},
-	{"Auckland, Selandia Baru", "abroad"},
-	{"Wellington, Selandia Baru", "abroad"},
+	{"Sydney, Australia", "", "AU"},
+	{"Melbourne, Australia", "", "AU"},
+	{"Perth, Australia", "", "AU"},
+	{"Auckland, Selandia Baru", "", "NZ"},
+	{"Wellington, Selandia Baru", "", "NZ"},
 
 	// Luar Negeri - Afrika
-	{"Kairo, Mesir", "abroad"},
-	{"Johannesburg, Afrika Selatan", "abroad"},
-	{"Nairobi, Kenya", "abroad"},
-	{"Lagos, Nigeria", "abroad"},
+	{"Kairo, Mesir", "", "EG"},
+	{"Johannesburg, Afrika Selatan", "", "ZA"},
+	{"Nairobi, Kenya", "", "KE"},
+	{"Lagos, Nigeria", "", "NG"},
 }
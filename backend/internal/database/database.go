@@ -5,6 +5,8 @@ import (
 	"log"
 	"perjalanan-dinas/backend/config"
 	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/utils"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
@@ -35,16 +37,43 @@ func ConnectDatabase(cfg *config.Config) error {
 	err = DB.AutoMigrate(
 		&models.Admin{},
 		&models.Position{},
+		&models.PositionRate{},
+		&models.PositionRateChange{},
 		&models.Employee{},
 		&models.TravelRequest{},
 		&models.TravelRequestEmployee{},
+		&models.TravelParticipant{},
+		&models.TravelLeg{},
+		&models.TravelLegAllowance{},
+		&models.TravelRequestStatusHistory{},
+		&models.TravelRequestRevision{},
+		&models.TravelAmendment{},
+		&models.TravelAmendmentEmployee{},
+		&models.TravelAmendmentParticipant{},
 		&models.TravelReport{},
 		&models.VisitProof{},
 		&models.NumberingConfig{},
+		&models.NumberSequence{},
+		&models.VoidedNumber{},
 		&models.RepresentativeConfig{},
 		&models.AtCostClaim{},
 		&models.AtCostClaimItem{},
 		&models.AtCostReceipt{},
+		&models.ApprovalRoute{},
+		&models.ApprovalRouteStep{},
+		&models.ApprovalStep{},
+		&models.TravelQuotaRule{},
+		&models.TravelPolicyRule{},
+		&models.DestinationTier{},
+		&models.DestinationTierCity{},
+		&models.City{},
+		&models.PartialDayRule{},
+		&models.Holiday{},
+		&models.ExchangeRate{},
+		&models.TravelAdvance{},
+		&models.TravelAdvanceEmployee{},
+		&models.SuratTugas{},
+		&models.SuratTugasSignerConfig{},
 	)
 
 	if err != nil {
@@ -53,11 +82,31 @@ func ConnectDatabase(cfg *config.Config) error {
 
 	log.Println("Database migration completed")
 
+	// Map legacy statuses to the travel request workflow
+	if err := migrateLegacyTravelRequestStatus(); err != nil {
+		log.Printf("Warning: failed to migrate travel request status: %v", err)
+	}
+
+	// Move document numbers to yearly sequences per document type and unit
+	if err := migrateYearlyNumbering(); err != nil {
+		log.Printf("Warning: failed to migrate yearly numbering: %v", err)
+	}
+
 	// Seed positions if not exists
 	if err := seedPositions(); err != nil {
 		log.Printf("Warning: failed to seed positions: %v", err)
 	}
 
+	// Give every position a rate record so trips can look up the rate valid on departure
+	if err := migratePositionRates(); err != nil {
+		log.Printf("Warning: failed to migrate position rates: %v", err)
+	}
+
+	// Seed the city master from the static city list if not exists
+	if err := seedCities(); err != nil {
+		log.Printf("Warning: failed to seed cities: %v", err)
+	}
+
 	// Create default admin if not exists
 	if err := createDefaultAdmin(cfg); err != nil {
 		log.Printf("Warning: failed to create default admin: %v", err)
@@ -73,6 +122,11 @@ func ConnectDatabase(cfg *config.Config) error {
 		log.Printf("Warning: failed to initialize representative config: %v", err)
 	}
 
+	// Initialize surat tugas signer config if not exists
+	if err := initializeSuratTugasSignerConfig(); err != nil {
+		log.Printf("Warning: failed to initialize surat tugas signer config: %v", err)
+	}
+
 	return nil
 }
 
@@ -125,6 +179,101 @@ func seedPositions() error {
 	return nil
 }
 
+func seedCities() error {
+	var count int64
+	DB.Model(&models.City{}).Count(&count)
+
+	if count == 0 {
+		cities := make([]models.City, 0, len(AllCities))
+		for _, cityData := range AllCities {
+			cities = append(cities, models.City{
+				Name:         cityData.Name,
+				Kind:         models.CityKindCity,
+				ProvinceCode: cityData.ProvinceCode,
+				ProvinceName: ProvinceNames[cityData.ProvinceCode],
+				CountryCode:  cityData.CountryCode,
+				CountryName:  CountryNames[cityData.CountryCode],
+				TimeZone:     cityTimeZone(cityData),
+			})
+		}
+		if err := DB.Create(&cities).Error; err != nil {
+			return err
+		}
+		log.Printf("Cities seeded successfully: %d cities", len(cities))
+		return nil
+	}
+
+	return backfillCityTimeZones()
+}
+
+// cityTimeZone returns the time zone of a seeded city
+func cityTimeZone(cityData CityData) string {
+	if zone, ok := CityTimeZones[cityData.Name]; ok {
+		return zone
+	}
+	return utils.DefaultPlaceTimeZone(cityData.ProvinceCode, cityData.CountryCode)
+}
+
+// backfillCityTimeZones gives the cities created before cities had time zones the zone of their
+// province or country. They were all created in WIB.
+func backfillCityTimeZones() error {
+	var cities []models.City
+	if err := DB.Where("time_zone = ?", utils.DefaultTimeZone).Find(&cities).Error; err != nil {
+		return err
+	}
+
+	updated := 0
+	for _, city := range cities {
+		zone, ok := CityTimeZones[city.Name]
+		if !ok {
+			zone = utils.DefaultPlaceTimeZone(city.ProvinceCode, city.CountryCode)
+		}
+		if zone == city.TimeZone {
+			continue
+		}
+		if err := DB.Model(&models.City{}).Where("id = ?", city.ID).Update("time_zone", zone).Error; err != nil {
+			return err
+		}
+		updated++
+	}
+	if updated > 0 {
+		log.Printf("City time zones backfilled: %d cities", updated)
+	}
+	return nil
+}
+
+// positionRateBaseDate is the start of the rates created from the original position seed
+var positionRateBaseDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// migratePositionRates creates an open-ended rate from the position's own allowance for
+// every position that has no rate records yet
+func migratePositionRates() error {
+	var positions []models.Position
+	if err := DB.Where("NOT EXISTS (SELECT 1 FROM position_rates pr WHERE pr.position_id = positions.id)").
+		Find(&positions).Error; err != nil {
+		return err
+	}
+
+	for _, position := range positions {
+		rate := models.PositionRate{
+			PositionID:               position.ID,
+			ValidFrom:                positionRateBaseDate,
+			AllowanceInProvince:      position.AllowanceInProvince,
+			AllowanceOutsideProvince: position.AllowanceOutsideProvince,
+			AllowanceAbroad:          position.AllowanceAbroad,
+			AbroadCurrency:           position.AbroadCurrency,
+		}
+		if err := DB.Create(&rate).Error; err != nil {
+			return err
+		}
+	}
+	if len(positions) > 0 {
+		log.Printf("Position rates created for %d positions", len(positions))
+	}
+
+	return nil
+}
+
 func initializeNumberingConfig() error {
 	var count int64
 	DB.Model(&models.NumberingConfig{}).Count(&count)
@@ -166,6 +315,105 @@ func initializeRepresentativeConfig() error {
 	return nil
 }
 
+// initializeSuratTugasSignerConfig creates the signer of surat tugas. The name is left empty,
+// to be written by hand, until an admin configures the official.
+func initializeSuratTugasSignerConfig() error {
+	var count int64
+	DB.Model(&models.SuratTugasSignerConfig{}).Count(&count)
+
+	if count == 0 {
+		config := models.SuratTugasSignerConfig{
+			Position: "Pemimpin Divisi",
+			Unit:     "DIVISI HUMAN CAPITAL",
+			City:     "Surabaya",
+		}
+
+		if err := DB.Create(&config).Error; err != nil {
+			return err
+		}
+
+		log.Println("Surat tugas signer config initialized with default values")
+	}
+
+	return nil
+}
+
+// migrateLegacyTravelRequestStatus converts the old "pending" status to "submitted"
+func migrateLegacyTravelRequestStatus() error {
+	result := DB.Model(&models.TravelRequest{}).
+		Where("status = ? OR status = ''", "pending").
+		Update("status", models.TravelStatusSubmitted)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Travel request status migrated: %d requests set to submitted", result.RowsAffected)
+	}
+
+	return nil
+}
+
+// migrateYearlyNumbering prepares existing data for numbers that restart every year.
+// Document numbers are only unique within their year now, so the old single column
+// unique constraints are dropped, the year and sequence of existing documents are
+// backfilled and the yearly counters are seeded from the highest number already used.
+func migrateYearlyNumbering() error {
+	legacyConstraints := map[string][]string{
+		"travel_requests": {"request_number", "report_number"},
+		"travel_reports":  {"report_number"},
+		"at_cost_claims":  {"claim_number"},
+	}
+	for table, columns := range legacyConstraints {
+		for _, column := range columns {
+			for _, name := range []string{table + "_" + column + "_key", "uni_" + table + "_" + column} {
+				if err := DB.Exec(fmt.Sprintf("ALTER TABLE IF EXISTS %s DROP CONSTRAINT IF EXISTS %s", table, name)).Error; err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	documents := []struct {
+		table        string
+		numberColumn string
+		documentType string
+	}{
+		{"travel_requests", "request_number", models.DocTypeNotaPermintaan},
+		{"at_cost_claims", "claim_number", models.DocTypeAtCostClaim},
+	}
+	for _, doc := range documents {
+		// Nomor format: 064/{seq}/DIB/{code}/NOTA, tahun diambil dari tanggal pembuatan
+		backfill := fmt.Sprintf(`UPDATE %[1]s SET
+			number_year = EXTRACT(YEAR FROM created_at AT TIME ZONE 'Asia/Jakarta')::int,
+			number_sequence = CASE WHEN split_part(%[2]s, '/', 2) ~ '^[0-9]+$'
+				THEN split_part(%[2]s, '/', 2)::int ELSE 0 END
+			WHERE number_year = 0`, doc.table, doc.numberColumn)
+		result := DB.Exec(backfill)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Yearly numbering backfilled: %d rows in %s", result.RowsAffected, doc.table)
+		}
+
+		// Deleted documents count as well, their numbers must never be handed out again
+		seed := fmt.Sprintf(`INSERT INTO number_sequences (year, document_type, position_code, last_number, created_at, updated_at)
+			SELECT number_year, ?, split_part(%[2]s, '/', 4), MAX(number_sequence), NOW(), NOW()
+			FROM %[1]s
+			WHERE number_year > 0 AND split_part(%[2]s, '/', 4) <> ''
+			GROUP BY number_year, split_part(%[2]s, '/', 4)
+			ON CONFLICT (year, document_type, position_code)
+			DO UPDATE SET last_number = GREATEST(number_sequences.last_number, EXCLUDED.last_number)`,
+			doc.table, doc.numberColumn)
+		if err := DB.Exec(seed, doc.documentType).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func GetDB() *gorm.DB {
 	return DB
 }
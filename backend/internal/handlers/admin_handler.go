@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminHandler manages admin accounts, which also act as approvers
+type AdminHandler struct {
+	repo *repository.Repository
+}
+
+func NewAdminHandler(repo *repository.Repository) *AdminHandler {
+	return &AdminHandler{repo: repo}
+}
+
+type CreateAdminRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+	Name     string `json:"name" binding:"required"`
+	Position string `json:"position" binding:"required"`
+}
+
+type UpdateAdminRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Position string `json:"position" binding:"required"`
+	Password string `json:"password"` // Kosong = tidak diubah
+}
+
+func (h *AdminHandler) GetAllAdmins(c *gin.Context) {
+	admins, err := h.repo.GetAllAdmins()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admins"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"admins": admins})
+}
+
+func (h *AdminHandler) CreateAdmin(c *gin.Context) {
+	var req CreateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	admin := &models.Admin{
+		Username: req.Username,
+		Password: string(hashedPassword),
+		Name:     req.Name,
+		Position: req.Position,
+	}
+
+	if err := h.repo.CreateAdmin(admin); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Admin created successfully",
+		"admin":   admin,
+	})
+}
+
+func (h *AdminHandler) UpdateAdmin(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	admin, err := h.repo.GetAdminByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return
+	}
+
+	var req UpdateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin.Name = req.Name
+	admin.Position = req.Position
+	if req.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		admin.Password = string(hashedPassword)
+	}
+
+	if err := h.repo.UpdateAdmin(admin); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Admin updated successfully",
+		"admin":   admin,
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"perjalanan-dinas/backend/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdvanceHandler pays advances (uang muka) to travellers and settles them after the trip
+type AdvanceHandler struct {
+	repo         *repository.Repository
+	service      *services.TravelAdvanceService
+	pdfGenerator *services.PDFGenerator
+}
+
+func NewAdvanceHandler(repo *repository.Repository) *AdvanceHandler {
+	return &AdvanceHandler{
+		repo:         repo,
+		service:      services.NewTravelAdvanceService(repo),
+		pdfGenerator: services.NewPDFGenerator(),
+	}
+}
+
+type CreateAdvanceRequest struct {
+	DisbursementDate string                 `json:"disbursement_date" binding:"required"` // Format: 2006-01-02
+	Employees        []AdvanceEmployeeInput `json:"employees" binding:"required,min=1,dive"`
+	Notes            string                 `json:"notes"`
+}
+
+// AdvanceEmployeeInput is the advance paid to one employee of the trip
+type AdvanceEmployeeInput struct {
+	EmployeeID uint `json:"employee_id" binding:"required"`
+	Amount     int  `json:"amount" binding:"required,gt=0"`
+}
+
+// CreateAdvance records the advance paid to the employees of a travel request
+func (h *AdvanceHandler) CreateAdvance(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	var req CreateAdvanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	disbursementDate, err := time.Parse("2006-01-02", req.DisbursementDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid disbursement date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	lines := make([]services.AdvanceLine, 0, len(req.Employees))
+	for _, input := range req.Employees {
+		lines = append(lines, services.AdvanceLine{EmployeeID: input.EmployeeID, Amount: input.Amount})
+	}
+
+	advance, err := h.service.Create(uint(id), lines, disbursementDate, strings.TrimSpace(req.Notes), c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		case errors.Is(err, services.ErrAdvanceNotAllowed), errors.Is(err, services.ErrAdvanceExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidAdvance):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create advance"})
+		}
+		return
+	}
+
+	advance, _ = h.repo.GetTravelAdvanceByTravelRequestID(advance.TravelRequestID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Advance created successfully",
+		"advance": advance,
+	})
+}
+
+// GetAdvance returns the advance of a travel request
+func (h *AdvanceHandler) GetAdvance(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	advance, err := h.repo.GetTravelAdvanceByTravelRequestID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Advance not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"advance": advance})
+}
+
+// SettleAdvance nets the advance of a finished or cancelled trip against the final allowance
+// and the approved at-cost claims
+func (h *AdvanceHandler) SettleAdvance(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	advance, err := h.service.Settle(uint(id), c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Advance not found"})
+		case errors.Is(err, services.ErrAdvanceSettled), errors.Is(err, services.ErrAdvanceNotSettleable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to settle advance"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Advance settled successfully",
+		"advance": advance,
+	})
+}
+
+// DownloadAdvanceSettlement downloads the "Pertanggungjawaban Uang Muka" PDF of a travel request
+func (h *AdvanceHandler) DownloadAdvanceSettlement(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	advance, err := h.repo.GetTravelAdvanceByTravelRequestID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Advance not found"})
+		return
+	}
+	if advance.Status != models.AdvanceStatusSettled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Advance is not settled yet"})
+		return
+	}
+
+	request, err := h.repo.GetTravelRequestByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateAdvanceSettlement(request, advance)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=pertanggungjawaban_"+utils.GenerateAdvanceSettlementNumber(request.RequestNumber)+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
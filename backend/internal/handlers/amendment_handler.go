@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"perjalanan-dinas/backend/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AmendmentHandler records extensions and early returns of approved travel requests
+type AmendmentHandler struct {
+	repo         *repository.Repository
+	service      *services.TravelAmendmentService
+	pdfGenerator *services.PDFGenerator
+}
+
+func NewAmendmentHandler(repo *repository.Repository) *AmendmentHandler {
+	return &AmendmentHandler{
+		repo:         repo,
+		service:      services.NewTravelAmendmentService(repo),
+		pdfGenerator: services.NewPDFGenerator(),
+	}
+}
+
+type CreateAmendmentRequest struct {
+	NewReturnDate string `json:"new_return_date" binding:"required"` // Format: 2006-01-02
+	NewReturnTime string `json:"new_return_time"`                    // Jam tiba kembali (HH:MM), kosong = tidak diisi
+	Reason        string `json:"reason" binding:"required"`
+	ApproverID    uint   `json:"approver_id" binding:"required"` // Admin yang menyetujui perubahan
+}
+
+func (h *AmendmentHandler) CreateAmendment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	var req CreateAmendmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	newReturnDate, err := time.Parse("2006-01-02", req.NewReturnDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid return date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if _, err := h.repo.GetAdminByID(req.ApproverID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Approver not found"})
+		return
+	}
+
+	amendment, err := h.service.Amend(uint(id), newReturnDate, req.NewReturnTime, req.Reason, req.ApproverID, c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		case errors.Is(err, services.ErrAmendmentNotAllowed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAmendmentNoChange), errors.Is(err, services.ErrAmendmentInvalidDate),
+			errors.Is(err, services.ErrAmendmentReasonMissing), errors.Is(err, services.ErrInvalidTravelTime):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create amendment"})
+		}
+		return
+	}
+
+	amendment, _ = h.repo.GetTravelAmendmentByID(amendment.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Amendment created successfully",
+		"amendment": amendment,
+	})
+}
+
+func (h *AmendmentHandler) GetAmendments(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	amendments, err := h.repo.GetTravelAmendments(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch amendments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"amendments": amendments})
+}
+
+// DownloadAmendmentLetter downloads the amendment letter PDF of one amendment
+func (h *AmendmentHandler) DownloadAmendmentLetter(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amendment ID"})
+		return
+	}
+
+	amendment, err := h.repo.GetTravelAmendmentByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Amendment not found"})
+		return
+	}
+
+	request, err := h.repo.GetTravelRequestByID(amendment.TravelRequestID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateAmendmentLetter(request, amendment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=perubahan_"+utils.GenerateAmendmentNumber(request.RequestNumber, amendment.AmendmentNumber)+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ApprovalHandler struct {
+	repo    *repository.Repository
+	service *services.ApprovalService
+}
+
+func NewApprovalHandler(repo *repository.Repository) *ApprovalHandler {
+	return &ApprovalHandler{
+		repo:    repo,
+		service: services.NewApprovalService(repo),
+	}
+}
+
+type ApprovalRouteRequest struct {
+	Name          string                   `json:"name" binding:"required"`
+	DocumentType  string                   `json:"document_type" binding:"required,oneof=travel_request at_cost_claim"`
+	PositionCode  string                   `json:"position_code"`  // Kosong = semua unit
+	PositionLevel string                   `json:"position_level"` // Kosong = semua level
+	MinAmount     int                      `json:"min_amount" binding:"min=0"`
+	IsActive      *bool                    `json:"is_active"`
+	Steps         []ApprovalRouteStepInput `json:"steps" binding:"required,min=1,dive"`
+}
+
+type ApprovalRouteStepInput struct {
+	ApproverID uint `json:"approver_id" binding:"required"`
+	MinAmount  int  `json:"min_amount" binding:"min=0"` // Step hanya wajib jika nominal >= MinAmount
+}
+
+type ApprovalDecisionRequest struct {
+	Note string `json:"note"` // Wajib saat menolak
+}
+
+func (h *ApprovalHandler) GetAllApprovalRoutes(c *gin.Context) {
+	routes, err := h.repo.GetAllApprovalRoutes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approval routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"approval_routes": routes})
+}
+
+func (h *ApprovalHandler) CreateApprovalRoute(c *gin.Context) {
+	var req ApprovalRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route := &models.ApprovalRoute{IsActive: true}
+	if err := h.applyRouteRequest(route, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.CreateApprovalRoute(route); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create approval route"})
+		return
+	}
+
+	route, _ = h.repo.GetApprovalRouteByID(route.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Approval route created successfully",
+		"approval_route": route,
+	})
+}
+
+func (h *ApprovalHandler) UpdateApprovalRoute(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval route ID"})
+		return
+	}
+
+	route, err := h.repo.GetApprovalRouteByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Approval route not found"})
+		return
+	}
+
+	var req ApprovalRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.applyRouteRequest(route, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.ReplaceApprovalRoute(route); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval route"})
+		return
+	}
+
+	route, _ = h.repo.GetApprovalRouteByID(route.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Approval route updated successfully",
+		"approval_route": route,
+	})
+}
+
+func (h *ApprovalHandler) DeleteApprovalRoute(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval route ID"})
+		return
+	}
+
+	if err := h.repo.DeleteApprovalRoute(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete approval route"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Approval route deleted successfully"})
+}
+
+// GetInbox returns the approval steps waiting for the logged in admin,
+// or for another approver when approver_id is given
+func (h *ApprovalHandler) GetInbox(c *gin.Context) {
+	approverID := c.GetUint("userID")
+	if approverIDStr := c.Query("approver_id"); approverIDStr != "" {
+		id, err := strconv.ParseUint(approverIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approver ID"})
+			return
+		}
+		approverID = uint(id)
+	}
+
+	items, err := h.service.Inbox(approverID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approval inbox"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"approver_id": approverID,
+		"items":       items,
+	})
+}
+
+func (h *ApprovalHandler) ApproveStep(c *gin.Context) {
+	h.decide(c, true)
+}
+
+func (h *ApprovalHandler) RejectStep(c *gin.Context) {
+	h.decide(c, false)
+}
+
+func (h *ApprovalHandler) decide(c *gin.Context, approve bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval step ID"})
+		return
+	}
+
+	var req ApprovalDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	step, err := h.service.Decide(uint(id), c.GetUint("userID"), c.GetString("username"), approve, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Approval step not found"})
+		case errors.Is(err, services.ErrNotApprover):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrApprovalStepDecided), errors.Is(err, services.ErrApprovalStepNotCurrent),
+			errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrClaimNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrApprovalNoteRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record approval decision"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Approval decision recorded successfully",
+		"step":    step,
+	})
+}
+
+func (h *ApprovalHandler) applyRouteRequest(route *models.ApprovalRoute, req *ApprovalRouteRequest) error {
+	route.Name = req.Name
+	route.DocumentType = req.DocumentType
+	route.PositionCode = req.PositionCode
+	route.PositionLevel = req.PositionLevel
+	route.MinAmount = req.MinAmount
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	route.Steps = make([]models.ApprovalRouteStep, 0, len(req.Steps))
+	for i, stepInput := range req.Steps {
+		if _, err := h.repo.GetAdminByID(stepInput.ApproverID); err != nil {
+			return fmt.Errorf("approver with ID %d not found", stepInput.ApproverID)
+		}
+		route.Steps = append(route.Steps, models.ApprovalRouteStep{
+			StepOrder:  i + 1,
+			ApproverID: stepInput.ApproverID,
+			MinAmount:  stepInput.MinAmount,
+		})
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"perjalanan-dinas/backend/internal/repository"
 	"perjalanan-dinas/backend/internal/services"
@@ -60,6 +61,23 @@ func (h *AtCostHandler) CreateAtCostClaim(c *gin.Context) {
 
 	claim, err := h.service.CreateAtCostClaim(&req)
 	if err != nil {
+		if errors.Is(err, services.ErrTravelRequestCancelled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidCurrency) || errors.Is(err, services.ErrInvalidForeignAmount) ||
+			errors.Is(err, services.ErrExchangeRateMissing) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var policyErr *services.PolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":  policyErr.Error(),
+				"policy": policyErr.Evaluation,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -127,7 +145,7 @@ func (h *AtCostHandler) UpdateClaimStatus(c *gin.Context) {
 	}
 
 	var req struct {
-		Status string `json:"status" binding:"required,oneof=pending approved rejected"`
+		Status string `json:"status" binding:"required,oneof=pending approved rejected refunded"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -136,6 +154,11 @@ func (h *AtCostHandler) UpdateClaimStatus(c *gin.Context) {
 	}
 
 	if err := h.service.UpdateClaimStatus(uint(id), req.Status); err != nil {
+		if errors.Is(err, services.ErrApprovalPending) || errors.Is(err, services.ErrClaimNotRefundable) ||
+			errors.Is(err, services.ErrClaimStatusLocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
 		return
 	}
@@ -1,34 +1,204 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"perjalanan-dinas/backend/internal/database"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-type CityHandler struct{}
+type CityHandler struct {
+	repo   *repository.Repository
+	cities *services.CityService
+}
 
-func NewCityHandler() *CityHandler {
-	return &CityHandler{}
+func NewCityHandler(repo *repository.Repository) *CityHandler {
+	return &CityHandler{
+		repo:   repo,
+		cities: services.NewCityService(repo),
+	}
 }
 
 type CityResponse struct {
-	Name            string `json:"name"`
-	DestinationType string `json:"destination_type"`
+	models.City
+	DestinationType string `json:"destination_type"` // Jenis tujuan dilihat dari tempat berangkat (?from=), default Surabaya
+}
+
+type CityRequest struct {
+	Name         string `json:"name" binding:"required"`
+	Kind         string `json:"kind" binding:"omitempty,oneof=city regency country"`
+	ProvinceCode string `json:"province_code"`
+	ProvinceName string `json:"province_name"`
+	CountryCode  string `json:"country_code" binding:"required,len=2"`
+	CountryName  string `json:"country_name"`
+	TimeZone     string `json:"time_zone"` // Zona waktu IANA, kosong = diturunkan dari provinsi/negara
 }
 
+// GetAllCities lists the city master with the destination type of each city seen from the
+// departure place in ?from=
 func (h *CityHandler) GetAllCities(c *gin.Context) {
-	cities := make([]CityResponse, len(database.AllCities))
+	cities, err := h.repo.GetAllCities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cities"})
+		return
+	}
 
-	for i, city := range database.AllCities {
-		cities[i] = CityResponse{
-			Name:            city.Name,
-			DestinationType: city.DestinationType,
+	origin := services.FindCity(cities, c.DefaultQuery("from", "Surabaya"))
+	response := make([]CityResponse, len(cities))
+	for i := range cities {
+		response[i] = CityResponse{City: cities[i]}
+		if origin != nil {
+			response[i].DestinationType = services.ClassifyDestination(origin, &cities[i])
 		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"cities": cities,
+		"cities": response,
+	})
+}
+
+func (h *CityHandler) CreateCity(c *gin.Context) {
+	city, ok := bindCity(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.GetCityByName(city.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "City with this name already exists"})
+		return
+	}
+
+	if err := h.repo.CreateCity(city); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create city"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "City created successfully",
+		"city":    city,
+	})
+}
+
+func (h *CityHandler) UpdateCity(c *gin.Context) {
+	id, ok := parseCityID(c)
+	if !ok {
+		return
+	}
+
+	city, err := h.repo.GetCityByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "City not found"})
+		return
+	}
+
+	updated, ok := bindCity(c)
+	if !ok {
+		return
+	}
+
+	if other, err := h.repo.GetCityByName(updated.Name); err == nil && other.ID != city.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "City with this name already exists"})
+		return
+	}
+
+	updated.ID = city.ID
+	updated.CreatedAt = city.CreatedAt
+	if err := h.repo.UpdateCity(updated); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update city"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "City updated successfully",
+		"city":    updated,
 	})
 }
+
+func (h *CityHandler) DeleteCity(c *gin.Context) {
+	id, ok := parseCityID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteCity(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete city"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "City deleted successfully"})
+}
+
+// ImportCities adds or updates cities from an uploaded CSV file (form field "file").
+// A single invalid row rejects the whole file.
+func (h *CityHandler) ImportCities(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV file"})
+		return
+	}
+	defer file.Close()
+
+	cities, err := services.ParseCityCSV(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.cities.Import(cities)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import cities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cities imported successfully",
+		"result":  result,
+	})
+}
+
+func parseCityID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid city ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// bindCity reads and validates a city from the request body. Returns false when it wrote a response.
+func bindCity(c *gin.Context) (*models.City, bool) {
+	var req CityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	city := &models.City{
+		Name:         req.Name,
+		Kind:         req.Kind,
+		ProvinceCode: req.ProvinceCode,
+		ProvinceName: req.ProvinceName,
+		CountryCode:  req.CountryCode,
+		CountryName:  req.CountryName,
+		TimeZone:     req.TimeZone,
+	}
+	if err := services.NormalizeCity(city); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidCity) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return city, true
+}
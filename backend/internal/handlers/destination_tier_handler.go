@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DestinationTierHandler struct {
+	repo *repository.Repository
+}
+
+func NewDestinationTierHandler(repo *repository.Repository) *DestinationTierHandler {
+	return &DestinationTierHandler{repo: repo}
+}
+
+type DestinationTierRequest struct {
+	Code              string   `json:"code" binding:"required"`
+	Name              string   `json:"name" binding:"required"`
+	DestinationType   string   `json:"destination_type" binding:"required,oneof=in_province outside_province abroad"`
+	Country           string   `json:"country"`
+	MultiplierPercent int      `json:"multiplier_percent" binding:"required,min=1"` // 150 = 1,5 x tarif zona
+	Cities            []string `json:"cities"`
+}
+
+func (h *DestinationTierHandler) GetAllDestinationTiers(c *gin.Context) {
+	tiers, err := h.repo.GetAllDestinationTiers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch destination tiers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"destination_tiers": tiers})
+}
+
+func (h *DestinationTierHandler) CreateDestinationTier(c *gin.Context) {
+	var req DestinationTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tier := &models.DestinationTier{}
+	applyDestinationTierRequest(tier, &req)
+
+	if err := h.repo.CreateDestinationTier(tier); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create destination tier"})
+		return
+	}
+
+	tier, _ = h.repo.GetDestinationTierByID(tier.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":          "Destination tier created successfully",
+		"destination_tier": tier,
+	})
+}
+
+func (h *DestinationTierHandler) UpdateDestinationTier(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination tier ID"})
+		return
+	}
+
+	tier, err := h.repo.GetDestinationTierByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Destination tier not found"})
+		return
+	}
+
+	var req DestinationTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyDestinationTierRequest(tier, &req)
+
+	if err := h.repo.ReplaceDestinationTier(tier); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update destination tier"})
+		return
+	}
+
+	tier, _ = h.repo.GetDestinationTierByID(tier.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Destination tier updated successfully",
+		"destination_tier": tier,
+	})
+}
+
+func (h *DestinationTierHandler) DeleteDestinationTier(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination tier ID"})
+		return
+	}
+
+	if err := h.repo.DeleteDestinationTier(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete destination tier"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Destination tier deleted successfully"})
+}
+
+func applyDestinationTierRequest(tier *models.DestinationTier, req *DestinationTierRequest) {
+	tier.Code = strings.TrimSpace(req.Code)
+	tier.Name = req.Name
+	tier.DestinationType = req.DestinationType
+	tier.Country = strings.TrimSpace(req.Country)
+	tier.MultiplierPercent = req.MultiplierPercent
+
+	tier.Cities = make([]models.DestinationTierCity, 0, len(req.Cities))
+	for _, city := range req.Cities {
+		if city = strings.TrimSpace(city); city != "" {
+			tier.Cities = append(tier.Cities, models.DestinationTierCity{CityName: city})
+		}
+	}
+}
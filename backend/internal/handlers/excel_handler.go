@@ -15,12 +15,14 @@ import (
 type ExcelHandler struct {
 	repo      *repository.Repository
 	excelGen  *services.ExcelGenerator
+	holidays  *services.HolidayService
 }
 
 func NewExcelHandler(repo *repository.Repository) *ExcelHandler {
 	return &ExcelHandler{
 		repo:     repo,
 		excelGen: services.NewExcelGenerator(),
+		holidays: services.NewHolidayService(repo),
 	}
 }
 
@@ -59,8 +61,22 @@ func (h *ExcelHandler) ExportMonthlyAllowance(c *gin.Context) {
 		return
 	}
 
+	// Holidays from the start of the month until the last trip returns
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	for _, request := range requests {
+		if request.ReturnDate.After(to) {
+			to = request.ReturnDate
+		}
+	}
+	calendar, err := h.holidays.Calendar(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load holiday calendar"})
+		return
+	}
+
 	// Generate Excel file
-	excelData, err := h.excelGen.GenerateMonthlyAllowanceReport(requests, year, month)
+	excelData, err := h.excelGen.GenerateMonthlyAllowanceReport(requests, calendar, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate Excel file"})
 		return
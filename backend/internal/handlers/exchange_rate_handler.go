@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateHandler struct {
+	repo          *repository.Repository
+	exchangeRates *services.ExchangeRateService
+}
+
+func NewExchangeRateHandler(repo *repository.Repository) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		repo:          repo,
+		exchangeRates: services.NewExchangeRateService(repo),
+	}
+}
+
+type ExchangeRateRequest struct {
+	Currency   string  `json:"currency" binding:"required"`         // Kode ISO 4217, mis. USD
+	Date       string  `json:"date" binding:"required"`             // Format: 2006-01-02
+	MiddleRate float64 `json:"middle_rate" binding:"required,gt=0"` // Rupiah per 1 unit
+	Source     string  `json:"source" binding:"omitempty,oneof=bi manual"`
+}
+
+// GetExchangeRates lists the exchange rates, newest first, optionally of one currency (?currency=USD)
+func (h *ExchangeRateHandler) GetExchangeRates(c *gin.Context) {
+	currency := ""
+	if c.Query("currency") != "" {
+		var err error
+		if currency, err = services.NormalizeCurrency(c.Query("currency")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	rates, err := h.repo.GetExchangeRates(currency)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exchange_rates": rates})
+}
+
+func (h *ExchangeRateHandler) CreateExchangeRate(c *gin.Context) {
+	rate := &models.ExchangeRate{}
+	if !bindExchangeRate(c, rate) {
+		return
+	}
+
+	if err := h.repo.CreateExchangeRate(rate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Exchange rate created successfully",
+		"exchange_rate": rate,
+	})
+}
+
+func (h *ExchangeRateHandler) UpdateExchangeRate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exchange rate ID"})
+		return
+	}
+
+	rate, err := h.repo.GetExchangeRateByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exchange rate not found"})
+		return
+	}
+
+	if !bindExchangeRate(c, rate) {
+		return
+	}
+
+	if err := h.repo.UpdateExchangeRate(rate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Exchange rate updated successfully",
+		"exchange_rate": rate,
+	})
+}
+
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exchange rate ID"})
+		return
+	}
+
+	if err := h.repo.DeleteExchangeRate(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete exchange rate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exchange rate deleted successfully"})
+}
+
+// ImportExchangeRates loads middle rates from an uploaded CSV (form field "file"), e.g. the
+// Bank Indonesia "Kurs Transaksi BI" export. Form field "date" (YYYY-MM-DD) dates files without
+// a date column. A single invalid row rejects the file.
+func (h *ExchangeRateHandler) ImportExchangeRates(c *gin.Context) {
+	var defaultDate time.Time
+	if value := strings.TrimSpace(c.PostForm("date")); value != "" {
+		var err error
+		if defaultDate, err = time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Exchange rate file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read exchange rate file"})
+		return
+	}
+	defer file.Close()
+
+	rates, err := services.ParseExchangeRateCSV(file, defaultDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.exchangeRates.Import(rates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rates imported successfully",
+		"result":  result,
+	})
+}
+
+// bindExchangeRate copies the request body onto rate. Returns false when it wrote a response.
+func bindExchangeRate(c *gin.Context, rate *models.ExchangeRate) bool {
+	var req ExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	currency, err := services.NormalizeCurrency(req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if !services.IsForeignCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rupiah needs no exchange rate"})
+		return false
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return false
+	}
+
+	rate.Currency = currency
+	rate.Date = date
+	rate.MiddleRate = req.MiddleRate
+	rate.Source = models.ExchangeRateSourceManual
+	if req.Source != "" {
+		rate.Source = req.Source
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HolidayHandler struct {
+	repo     *repository.Repository
+	holidays *services.HolidayService
+}
+
+func NewHolidayHandler(repo *repository.Repository) *HolidayHandler {
+	return &HolidayHandler{
+		repo:     repo,
+		holidays: services.NewHolidayService(repo),
+	}
+}
+
+type HolidayRequest struct {
+	Date            string `json:"date" binding:"required"` // Format: 2006-01-02
+	Name            string `json:"name" binding:"required"`
+	CollectiveLeave bool   `json:"collective_leave"` // Cuti bersama
+}
+
+// GetHolidays lists the holidays of a year (default current year)
+func (h *HolidayHandler) GetHolidays(c *gin.Context) {
+	yearStr := c.DefaultQuery("year", fmt.Sprintf("%d", time.Now().Year()))
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year parameter"})
+		return
+	}
+
+	holidays, err := h.repo.GetHolidaysByYear(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch holidays"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holidays": holidays})
+}
+
+func (h *HolidayHandler) CreateHoliday(c *gin.Context) {
+	holiday := &models.Holiday{}
+	if !bindHoliday(c, holiday) {
+		return
+	}
+
+	if err := h.repo.CreateHoliday(holiday); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create holiday"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Holiday created successfully",
+		"holiday": holiday,
+	})
+}
+
+func (h *HolidayHandler) UpdateHoliday(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid holiday ID"})
+		return
+	}
+
+	holiday, err := h.repo.GetHolidayByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+
+	if !bindHoliday(c, holiday) {
+		return
+	}
+
+	if err := h.repo.UpdateHoliday(holiday); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update holiday"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Holiday updated successfully",
+		"holiday": holiday,
+	})
+}
+
+func (h *HolidayHandler) DeleteHoliday(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid holiday ID"})
+		return
+	}
+
+	if err := h.repo.DeleteHoliday(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete holiday"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday deleted successfully"})
+}
+
+// ImportHolidays adds or renames holidays from an uploaded calendar (form field "file"). Files
+// ending in .ics are read as iCalendar, others as CSV. A single invalid entry rejects the file.
+func (h *HolidayHandler) ImportHolidays(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Calendar file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read calendar file"})
+		return
+	}
+	defer file.Close()
+
+	var holidays []models.Holiday
+	if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".ics") {
+		holidays, err = services.ParseHolidayICal(file)
+	} else {
+		holidays, err = services.ParseHolidayCSV(file)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.holidays.Import(holidays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import holidays"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Holidays imported successfully",
+		"result":  result,
+	})
+}
+
+// bindHoliday copies the request body onto holiday. Returns false when it wrote a response.
+func bindHoliday(c *gin.Context, holiday *models.Holiday) bool {
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return false
+	}
+
+	holiday.Date = date
+	holiday.Name = strings.TrimSpace(req.Name)
+	holiday.CollectiveLeave = req.CollectiveLeave
+	return true
+}
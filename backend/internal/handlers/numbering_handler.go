@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NumberingHandler lets admins view and seed the yearly numbering agendas
+type NumberingHandler struct {
+	repo      *repository.Repository
+	numbering *services.NumberingService
+	register  *services.NumberRegisterService
+	pdfGen    *services.PDFGenerator
+	excelGen  *services.ExcelGenerator
+}
+
+func NewNumberingHandler(repo *repository.Repository) *NumberingHandler {
+	return &NumberingHandler{
+		repo:      repo,
+		numbering: services.NewNumberingService(repo),
+		register:  services.NewNumberRegisterService(repo),
+		pdfGen:    services.NewPDFGenerator(),
+		excelGen:  services.NewExcelGenerator(),
+	}
+}
+
+type SeedNumberSequenceRequest struct {
+	Year         int    `json:"year" binding:"required,min=2000"`
+	DocumentType string `json:"document_type" binding:"required,oneof=nota_permintaan at_cost_claim surat_tugas"`
+	PositionCode string `json:"position_code" binding:"required"` // DPEB, DPDB, DDBE
+	LastNumber   int    `json:"last_number" binding:"min=0"`      // Nomor terakhir yang sudah terpakai
+}
+
+// GetNumberSequences returns the counters of a year (default: current year)
+func (h *NumberingHandler) GetNumberSequences(c *gin.Context) {
+	year := services.NumberingYear(time.Now())
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		year = y
+	}
+
+	sequences, err := h.numbering.GetSequences(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch number sequences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"year":      year,
+		"sequences": sequences,
+	})
+}
+
+// SeedNumberSequence sets the last used number of an agenda, e.g. to continue a paper register
+func (h *NumberingHandler) SeedNumberSequence(c *gin.Context) {
+	var req SeedNumberSequenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	positionCode := strings.ToUpper(strings.TrimSpace(req.PositionCode))
+	sequence, err := h.numbering.SeedSequence(req.Year, req.DocumentType, positionCode, req.LastNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSequenceBelowUsed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidDocumentType):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update number sequence"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Number sequence updated successfully",
+		"sequence": sequence,
+	})
+}
+
+// GetNumberJournal lists every number of a period as used, void or gap.
+// Query: year (default current), month (optional), document_type (default nota_permintaan), position_code (optional)
+func (h *NumberingHandler) GetNumberJournal(c *gin.Context) {
+	journals, ok := h.loadJournal(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"journals": journals})
+}
+
+// ExportNumberJournalPDF downloads the buku agenda as PDF
+func (h *NumberingHandler) ExportNumberJournalPDF(c *gin.Context) {
+	journals, ok := h.loadJournal(c)
+	if !ok {
+		return
+	}
+
+	pdfBytes, err := h.pdfGen.GenerateBukuAgenda(journals)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename="+journalFilename(c, "pdf"))
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// ExportNumberJournalExcel downloads the buku agenda as Excel
+func (h *NumberingHandler) ExportNumberJournalExcel(c *gin.Context) {
+	journals, ok := h.loadJournal(c)
+	if !ok {
+		return
+	}
+
+	excelData, err := h.excelGen.GenerateBukuAgenda(journals)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate Excel file"})
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", "attachment; filename="+journalFilename(c, "xlsx"))
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Transfer-Encoding", "binary")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", excelData)
+}
+
+func (h *NumberingHandler) loadJournal(c *gin.Context) ([]services.NumberJournal, bool) {
+	year := services.NumberingYear(time.Now())
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2000 || y > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year parameter"})
+			return nil, false
+		}
+		year = y
+	}
+
+	month := 0
+	if monthStr := c.Query("month"); monthStr != "" {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month parameter"})
+			return nil, false
+		}
+		month = m
+	}
+
+	documentType := c.DefaultQuery("document_type", models.DocTypeNotaPermintaan)
+	journals, err := h.register.Journal(year, month, documentType, c.Query("position_code"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDocumentType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build number journal"})
+		}
+		return nil, false
+	}
+
+	return journals, true
+}
+
+func journalFilename(c *gin.Context, ext string) string {
+	name := fmt.Sprintf("Buku_Agenda_%s", c.DefaultQuery("year", strconv.Itoa(services.NumberingYear(time.Now()))))
+	if month := c.Query("month"); month != "" {
+		name += "_" + month
+	}
+	if code := c.Query("position_code"); code != "" {
+		name += "_" + strings.ToUpper(code)
+	}
+	return name + "." + ext
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartialDayHandler struct {
+	repo *repository.Repository
+}
+
+func NewPartialDayHandler(repo *repository.Repository) *PartialDayHandler {
+	return &PartialDayHandler{repo: repo}
+}
+
+type PartialDayRuleRequest struct {
+	DestinationType     string `json:"destination_type" binding:"required,oneof=in_province outside_province abroad"`
+	ShortTripMaxHours   int    `json:"short_trip_max_hours" binding:"min=0,max=24"` // 0 = tanpa aturan perjalanan singkat
+	ShortTripPercent    int    `json:"short_trip_percent" binding:"min=0,max=100"`
+	DepartureDayPercent int    `json:"departure_day_percent" binding:"min=0,max=100"`
+	ReturnDayPercent    int    `json:"return_day_percent" binding:"min=0,max=100"`
+	IsActive            *bool  `json:"is_active"`
+}
+
+func (h *PartialDayHandler) GetAllPartialDayRules(c *gin.Context) {
+	rules, err := h.repo.GetAllPartialDayRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch partial day rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"partial_day_rules": rules})
+}
+
+func (h *PartialDayHandler) CreatePartialDayRule(c *gin.Context) {
+	var req PartialDayRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.PartialDayRule{IsActive: true}
+	applyPartialDayRuleRequest(rule, &req)
+	if !h.ensureSingleActiveRule(c, rule) {
+		return
+	}
+
+	if err := h.repo.CreatePartialDayRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create partial day rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":          "Partial day rule created successfully",
+		"partial_day_rule": rule,
+	})
+}
+
+func (h *PartialDayHandler) UpdatePartialDayRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partial day rule ID"})
+		return
+	}
+
+	rule, err := h.repo.GetPartialDayRuleByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Partial day rule not found"})
+		return
+	}
+
+	var req PartialDayRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyPartialDayRuleRequest(rule, &req)
+	if !h.ensureSingleActiveRule(c, rule) {
+		return
+	}
+
+	if err := h.repo.UpdatePartialDayRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update partial day rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Partial day rule updated successfully",
+		"partial_day_rule": rule,
+	})
+}
+
+func (h *PartialDayHandler) DeletePartialDayRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partial day rule ID"})
+		return
+	}
+
+	if err := h.repo.DeletePartialDayRule(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete partial day rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Partial day rule deleted successfully"})
+}
+
+func applyPartialDayRuleRequest(rule *models.PartialDayRule, req *PartialDayRuleRequest) {
+	rule.DestinationType = req.DestinationType
+	rule.ShortTripMaxHours = req.ShortTripMaxHours
+	rule.ShortTripPercent = req.ShortTripPercent
+	rule.DepartureDayPercent = req.DepartureDayPercent
+	rule.ReturnDayPercent = req.ReturnDayPercent
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
+
+// ensureSingleActiveRule refuses a second active rule for the same destination type.
+// Returns false when it wrote a response.
+func (h *PartialDayHandler) ensureSingleActiveRule(c *gin.Context, rule *models.PartialDayRule) bool {
+	if !rule.IsActive {
+		return true
+	}
+	active, err := h.repo.GetActivePartialDayRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch partial day rules"})
+		return false
+	}
+	if existing, ok := active[rule.DestinationType]; ok && existing.ID != rule.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Destination type %s already has an active partial day rule", rule.DestinationType)})
+		return false
+	}
+	return true
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"perjalanan-dinas/backend/internal/models"
 	"perjalanan-dinas/backend/internal/repository"
 	"perjalanan-dinas/backend/internal/services"
+	"perjalanan-dinas/backend/internal/utils"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -43,7 +47,7 @@ func (h *PDFHandler) DownloadNotaPermintaan(c *gin.Context) {
 	}
 
 	c.Header("Content-Type", "application/pdf")
-	c.Header("Content-Disposition", "attachment; filename=nota_permintaan_"+request.RequestNumber+".pdf")
+	c.Header("Content-Disposition", "attachment; filename=nota_permintaan_"+utils.WithRevision(request.RequestNumber, request.Revision)+".pdf")
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
 
@@ -75,7 +79,7 @@ func (h *PDFHandler) DownloadBeritaAcara(c *gin.Context) {
 	}
 
 	c.Header("Content-Type", "application/pdf")
-	c.Header("Content-Disposition", "attachment; filename=berita_acara_"+report.ReportNumber+".pdf")
+	c.Header("Content-Disposition", "attachment; filename=berita_acara_"+utils.WithRevision(report.ReportNumber, request.Revision)+".pdf")
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
 
@@ -100,13 +104,168 @@ func (h *PDFHandler) DownloadCombinedPDF(c *gin.Context) {
 		return
 	}
 
-	pdfBytes, err := h.pdfGenerator.GenerateCombinedPDF(request, report)
+	// The surat tugas is included once the request is approved, with the amendments made since
+	var letter *models.SuratTugas
+	if issued, err := h.repo.GetSuratTugasByTravelRequestID(uint(id)); err == nil {
+		letter = issued
+		if request.Amendments, err = h.repo.GetTravelAmendments(uint(id)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch amendments"})
+			return
+		}
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateCombinedPDF(request, report, letter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=perjalanan_dinas_"+utils.WithRevision(request.RequestNumber, request.Revision)+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// DownloadSuratTugas downloads the surat tugas of an approved travel request, watermarked once
+// the request is cancelled
+func (h *PDFHandler) DownloadSuratTugas(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	request, err := h.repo.GetTravelRequestByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+
+	letter, err := h.repo.GetSuratTugasByTravelRequestID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Surat tugas not issued for this request"})
+		return
+	}
+
+	request.Amendments, err = h.repo.GetTravelAmendments(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch amendments"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateSuratTugas(request, letter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=surat_tugas_"+letter.LetterNumber+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// DownloadKuitansi downloads the kuitansi of the employees of a travel request, one per page.
+// With ?employee_id= only the kuitansi of that employee is rendered.
+func (h *PDFHandler) DownloadKuitansi(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	request, err := h.repo.GetTravelRequestByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+	if !services.IsAllowancePaidStatus(request.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kuitansi is only available for approved travel requests"})
+		return
+	}
+
+	receipts := services.BuildKuitansi(request)
+	filename := "kuitansi_" + request.RequestNumber
+	if employeeIDStr := c.Query("employee_id"); employeeIDStr != "" {
+		employeeID, err := strconv.ParseUint(employeeIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+			return
+		}
+		var selected []services.Kuitansi
+		for _, receipt := range receipts {
+			if receipt.Employee.ID == uint(employeeID) {
+				selected = append(selected, receipt)
+				filename = "kuitansi_" + receipt.Number
+			}
+		}
+		receipts = selected
+	}
+	if len(receipts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No allowance is paid to this employee"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateKuitansi(receipts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename="+filename+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// DownloadMonthlyKuitansi downloads the kuitansi of every trip departing in a month in one PDF
+func (h *PDFHandler) DownloadMonthlyKuitansi(c *gin.Context) {
+	yearStr := c.Query("year")
+	monthStr := c.Query("month")
+
+	// Default to current month if not provided
+	now := time.Now()
+	year := now.Year()
+	month := int(now.Month())
+
+	if yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2000 || y > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year parameter"})
+			return
+		}
+		year = y
+	}
+
+	if monthStr != "" {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month parameter"})
+			return
+		}
+		month = m
+	}
+
+	requests, err := h.repo.GetTravelRequestsByStatus([]string{
+		models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch travel requests"})
+		return
+	}
+
+	receipts := services.MonthlyKuitansi(requests, year, month)
+	if len(receipts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No allowance is paid for trips in this month"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateKuitansi(receipts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
 		return
 	}
 
 	c.Header("Content-Type", "application/pdf")
-	c.Header("Content-Disposition", "attachment; filename=perjalanan_dinas_"+request.RequestNumber+".pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=kuitansi_%d-%02d.pdf", year, month))
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyHandler struct {
+	repo *repository.Repository
+}
+
+func NewPolicyHandler(repo *repository.Repository) *PolicyHandler {
+	return &PolicyHandler{repo: repo}
+}
+
+type TravelPolicyRuleRequest struct {
+	Name            string `json:"name" binding:"required"`
+	Message         string `json:"message"`
+	DocumentType    string `json:"document_type" binding:"omitempty,oneof=travel_request at_cost_claim"` // Kosong = keduanya
+	PositionLevel   string `json:"position_level"`
+	DestinationType string `json:"destination_type" binding:"omitempty,oneof=in_province outside_province abroad"`
+	TransportMode   string `json:"transport_mode"`
+	MinDurationDays int    `json:"min_duration_days" binding:"min=0"`
+	MaxDurationDays int    `json:"max_duration_days" binding:"min=0"`
+	NonWorkingDays  bool   `json:"non_working_days"` // Hanya untuk perjalanan yang mencakup akhir pekan/hari libur
+	Effect          string `json:"effect" binding:"required,oneof=deny justify"`
+	IsActive        *bool  `json:"is_active"`
+}
+
+func (h *PolicyHandler) GetAllPolicyRules(c *gin.Context) {
+	rules, err := h.repo.GetAllTravelPolicyRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch policy rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policy_rules": rules})
+}
+
+func (h *PolicyHandler) CreatePolicyRule(c *gin.Context) {
+	var req TravelPolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.TravelPolicyRule{IsActive: true}
+	if !applyPolicyRuleRequest(c, rule, &req) {
+		return
+	}
+
+	if err := h.repo.CreateTravelPolicyRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create policy rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Policy rule created successfully",
+		"policy_rule": rule,
+	})
+}
+
+func (h *PolicyHandler) UpdatePolicyRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy rule ID"})
+		return
+	}
+
+	rule, err := h.repo.GetTravelPolicyRuleByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy rule not found"})
+		return
+	}
+
+	var req TravelPolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !applyPolicyRuleRequest(c, rule, &req) {
+		return
+	}
+
+	if err := h.repo.UpdateTravelPolicyRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Policy rule updated successfully",
+		"policy_rule": rule,
+	})
+}
+
+func (h *PolicyHandler) DeletePolicyRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy rule ID"})
+		return
+	}
+
+	if err := h.repo.DeleteTravelPolicyRule(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete policy rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy rule deleted successfully"})
+}
+
+// applyPolicyRuleRequest copies the request onto rule. Returns false when it wrote a response.
+func applyPolicyRuleRequest(c *gin.Context, rule *models.TravelPolicyRule, req *TravelPolicyRuleRequest) bool {
+	if req.MaxDurationDays > 0 && req.MaxDurationDays < req.MinDurationDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_duration_days cannot be less than min_duration_days"})
+		return false
+	}
+
+	rule.Name = req.Name
+	rule.Message = req.Message
+	rule.DocumentType = req.DocumentType
+	rule.PositionLevel = req.PositionLevel
+	rule.DestinationType = req.DestinationType
+	rule.TransportMode = req.TransportMode
+	rule.MinDurationDays = req.MinDurationDays
+	rule.MaxDurationDays = req.MaxDurationDays
+	rule.NonWorkingDays = req.NonWorkingDays
+	rule.Effect = req.Effect
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return true
+}
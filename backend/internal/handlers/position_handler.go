@@ -1,18 +1,55 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"perjalanan-dinas/backend/internal/models"
 	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type PositionHandler struct {
-	repo *repository.Repository
+	repo  *repository.Repository
+	rates *services.PositionRateService
 }
 
 func NewPositionHandler(repo *repository.Repository) *PositionHandler {
-	return &PositionHandler{repo: repo}
+	return &PositionHandler{
+		repo:  repo,
+		rates: services.NewPositionRateService(repo),
+	}
+}
+
+type CreatePositionRequest struct {
+	Title                    string `json:"title" binding:"required"`
+	Code                     string `json:"code" binding:"required"`
+	Level                    string `json:"level" binding:"required"`
+	AllowanceInProvince      int    `json:"allowance_in_province" binding:"min=0"`
+	AllowanceOutsideProvince int    `json:"allowance_outside_province" binding:"min=0"`
+	AllowanceAbroad          int    `json:"allowance_abroad" binding:"min=0"`
+	AbroadCurrency           string `json:"abroad_currency"` // ISO 4217, kosong = IDR
+	ValidFrom                string `json:"valid_from"`      // Format: 2006-01-02, default hari ini
+}
+
+// UpdatePositionRequest changes the identity of a position. Rates change through the rate endpoints.
+type UpdatePositionRequest struct {
+	Title string `json:"title" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+	Level string `json:"level" binding:"required"`
+}
+
+type PositionRateRequest struct {
+	ValidFrom                string `json:"valid_from" binding:"required"` // Format: 2006-01-02
+	ValidTo                  string `json:"valid_to"`                      // Format: 2006-01-02, kosong = sampai ada tarif baru
+	AllowanceInProvince      int    `json:"allowance_in_province" binding:"min=0"`
+	AllowanceOutsideProvince int    `json:"allowance_outside_province" binding:"min=0"`
+	AllowanceAbroad          int    `json:"allowance_abroad" binding:"min=0"`
+	AbroadCurrency           string `json:"abroad_currency"` // ISO 4217, kosong = IDR
 }
 
 func (h *PositionHandler) GetAllPositions(c *gin.Context) {
@@ -24,3 +61,267 @@ func (h *PositionHandler) GetAllPositions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"positions": positions})
 }
+
+func (h *PositionHandler) CreatePosition(c *gin.Context) {
+	var req CreatePositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	validFrom := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if req.ValidFrom != "" {
+		var err error
+		if validFrom, err = time.Parse("2006-01-02", req.ValidFrom); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid valid_from format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	abroadCurrency, err := services.NormalizeCurrency(req.AbroadCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	position := &models.Position{
+		Title:                    req.Title,
+		Code:                     req.Code,
+		Level:                    req.Level,
+		AllowanceInProvince:      req.AllowanceInProvince,
+		AllowanceOutsideProvince: req.AllowanceOutsideProvince,
+		AllowanceAbroad:          req.AllowanceAbroad,
+		AbroadCurrency:           abroadCurrency,
+	}
+	if err := h.rates.CreatePosition(position, validFrom, c.GetString("username")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create position"})
+		return
+	}
+
+	position, _ = h.repo.GetPositionWithRates(position.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Position created successfully",
+		"position": position,
+	})
+}
+
+func (h *PositionHandler) GetPositionByID(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+
+	position, err := h.repo.GetPositionWithRates(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"position": position})
+}
+
+func (h *PositionHandler) UpdatePosition(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+
+	position, err := h.repo.GetPositionByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return
+	}
+
+	var req UpdatePositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	position.Title = req.Title
+	position.Code = req.Code
+	position.Level = req.Level
+	if err := h.repo.UpdatePosition(position); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update position"})
+		return
+	}
+
+	position, _ = h.repo.GetPositionWithRates(id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Position updated successfully",
+		"position": position,
+	})
+}
+
+func (h *PositionHandler) DeletePosition(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.repo.CountEmployeesByPosition(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check position usage"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrPositionInUse.Error()})
+		return
+	}
+
+	if err := h.repo.DeletePosition(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete position"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Position deleted successfully"})
+}
+
+func (h *PositionHandler) CreatePositionRate(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+
+	rate, ok := bindPositionRate(c)
+	if !ok {
+		return
+	}
+
+	if err := h.rates.CreateRate(id, rate, c.GetString("username")); err != nil {
+		respondPositionRateError(c, err, "Failed to create position rate")
+		return
+	}
+
+	position, _ := h.repo.GetPositionWithRates(id)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Position rate created successfully",
+		"rate":     rate,
+		"position": position,
+	})
+}
+
+func (h *PositionHandler) UpdatePositionRate(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+	rateID, err := strconv.ParseUint(c.Param("rate_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rate ID"})
+		return
+	}
+
+	updated, ok := bindPositionRate(c)
+	if !ok {
+		return
+	}
+
+	rate, err := h.rates.UpdateRate(id, uint(rateID), *updated, c.GetString("username"))
+	if err != nil {
+		respondPositionRateError(c, err, "Failed to update position rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Position rate updated successfully",
+		"rate":    rate,
+	})
+}
+
+func (h *PositionHandler) DeletePositionRate(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+	rateID, err := strconv.ParseUint(c.Param("rate_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rate ID"})
+		return
+	}
+
+	if err := h.rates.DeleteRate(id, uint(rateID), c.GetString("username")); err != nil {
+		respondPositionRateError(c, err, "Failed to delete position rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Position rate deleted successfully"})
+}
+
+// GetPositionRateChanges returns the audit trail of the rates of a position, newest first
+func (h *PositionHandler) GetPositionRateChanges(c *gin.Context) {
+	id, ok := parsePositionID(c)
+	if !ok {
+		return
+	}
+
+	changes, err := h.repo.GetPositionRateChanges(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rate changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
+func parsePositionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid position ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// bindPositionRate reads a rate from the request body. Returns false when it wrote a response.
+func bindPositionRate(c *gin.Context) (*models.PositionRate, bool) {
+	var req PositionRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	validFrom, err := time.Parse("2006-01-02", req.ValidFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid valid_from format. Use YYYY-MM-DD"})
+		return nil, false
+	}
+	abroadCurrency, err := services.NormalizeCurrency(req.AbroadCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	rate := &models.PositionRate{
+		ValidFrom:                validFrom,
+		AllowanceInProvince:      req.AllowanceInProvince,
+		AllowanceOutsideProvince: req.AllowanceOutsideProvince,
+		AllowanceAbroad:          req.AllowanceAbroad,
+		AbroadCurrency:           abroadCurrency,
+	}
+	if req.ValidTo != "" {
+		validTo, err := time.Parse("2006-01-02", req.ValidTo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid valid_to format. Use YYYY-MM-DD"})
+			return nil, false
+		}
+		rate.ValidTo = &validTo
+	}
+	return rate, true
+}
+
+func respondPositionRateError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position or rate not found"})
+	case errors.Is(err, services.ErrRateOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidRatePeriod):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaHandler struct {
+	repo    *repository.Repository
+	service *services.TravelQuotaService
+}
+
+func NewQuotaHandler(repo *repository.Repository) *QuotaHandler {
+	return &QuotaHandler{
+		repo:    repo,
+		service: services.NewTravelQuotaService(repo),
+	}
+}
+
+type TravelQuotaRuleRequest struct {
+	PositionLevel      string `json:"position_level" binding:"required"`
+	MaxTripsPerYear    int    `json:"max_trips_per_year" binding:"min=0"`   // 0 = tidak dibatasi
+	MaxConsecutiveDays int    `json:"max_consecutive_days" binding:"min=0"` // 0 = tidak dibatasi
+	Enforcement        string `json:"enforcement" binding:"required,oneof=block warn"`
+	IsActive           *bool  `json:"is_active"`
+}
+
+func (h *QuotaHandler) GetAllQuotaRules(c *gin.Context) {
+	rules, err := h.repo.GetAllTravelQuotaRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quota rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quota_rules": rules})
+}
+
+func (h *QuotaHandler) CreateQuotaRule(c *gin.Context) {
+	var req TravelQuotaRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.TravelQuotaRule{IsActive: true}
+	applyQuotaRuleRequest(rule, &req)
+	if !h.ensureSingleActiveRule(c, rule) {
+		return
+	}
+
+	if err := h.repo.CreateTravelQuotaRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quota rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Quota rule created successfully",
+		"quota_rule": rule,
+	})
+}
+
+func (h *QuotaHandler) UpdateQuotaRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quota rule ID"})
+		return
+	}
+
+	rule, err := h.repo.GetTravelQuotaRuleByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quota rule not found"})
+		return
+	}
+
+	var req TravelQuotaRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyQuotaRuleRequest(rule, &req)
+	if !h.ensureSingleActiveRule(c, rule) {
+		return
+	}
+
+	if err := h.repo.UpdateTravelQuotaRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quota rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Quota rule updated successfully",
+		"quota_rule": rule,
+	})
+}
+
+func (h *QuotaHandler) DeleteQuotaRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quota rule ID"})
+		return
+	}
+
+	if err := h.repo.DeleteTravelQuotaRule(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quota rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quota rule deleted successfully"})
+}
+
+// GetEmployeeQuota returns the remaining travel quota of an employee in a year (default current year)
+func (h *QuotaHandler) GetEmployeeQuota(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+
+	yearStr := c.DefaultQuery("year", fmt.Sprintf("%d", time.Now().Year()))
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year parameter"})
+		return
+	}
+
+	employee, err := h.repo.GetEmployeeByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	quota, err := h.service.EmployeeQuota(employee, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate travel quota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quota": quota})
+}
+
+func applyQuotaRuleRequest(rule *models.TravelQuotaRule, req *TravelQuotaRuleRequest) {
+	rule.PositionLevel = req.PositionLevel
+	rule.MaxTripsPerYear = req.MaxTripsPerYear
+	rule.MaxConsecutiveDays = req.MaxConsecutiveDays
+	rule.Enforcement = req.Enforcement
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
+
+// ensureSingleActiveRule refuses a second active rule for the same position level.
+// Returns false when it wrote a response.
+func (h *QuotaHandler) ensureSingleActiveRule(c *gin.Context, rule *models.TravelQuotaRule) bool {
+	if !rule.IsActive {
+		return true
+	}
+	active, err := h.repo.GetActiveTravelQuotaRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quota rules"})
+		return false
+	}
+	if existing, ok := active[rule.PositionLevel]; ok && existing.ID != rule.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Position level %s already has an active quota rule", rule.PositionLevel)})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SuratTugasHandler issues surat tugas and manages who signs them
+type SuratTugasHandler struct {
+	repo    *repository.Repository
+	service *services.SuratTugasService
+}
+
+func NewSuratTugasHandler(repo *repository.Repository) *SuratTugasHandler {
+	return &SuratTugasHandler{
+		repo:    repo,
+		service: services.NewSuratTugasService(repo),
+	}
+}
+
+type UpdateSuratTugasSignerRequest struct {
+	Name     string `json:"name"` // Kosong = nama ditulis tangan
+	NIP      string `json:"nip"`
+	Position string `json:"position" binding:"required"`
+	Unit     string `json:"unit" binding:"required"`
+	City     string `json:"city" binding:"required"`
+}
+
+// IssueSuratTugas issues the surat tugas of an approved travel request. Requests approved from
+// now on get theirs on approval; this covers requests approved before.
+func (h *SuratTugasHandler) IssueSuratTugas(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	letter, err := h.service.Issue(uint(id), c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		case errors.Is(err, services.ErrSuratTugasNotAllowed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue surat tugas"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Surat tugas issued successfully",
+		"surat_tugas": letter,
+	})
+}
+
+// GetSuratTugas returns the surat tugas of a travel request
+func (h *SuratTugasHandler) GetSuratTugas(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	letter, err := h.repo.GetSuratTugasByTravelRequestID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Surat tugas not issued for this request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"surat_tugas": letter})
+}
+
+func (h *SuratTugasHandler) GetSignerConfig(c *gin.Context) {
+	config, err := h.repo.GetSuratTugasSignerConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get surat tugas signer config"})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// UpdateSignerConfig changes who signs new surat tugas; letters already issued keep their signer
+func (h *SuratTugasHandler) UpdateSignerConfig(c *gin.Context) {
+	var req UpdateSuratTugasSignerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := h.repo.GetSuratTugasSignerConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get surat tugas signer config"})
+		return
+	}
+
+	config.Name = strings.TrimSpace(req.Name)
+	config.NIP = strings.TrimSpace(req.NIP)
+	config.Position = strings.TrimSpace(req.Position)
+	config.Unit = strings.TrimSpace(req.Unit)
+	config.City = strings.TrimSpace(req.City)
+
+	if err := h.repo.UpdateSuratTugasSignerConfig(config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update surat tugas signer config"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Surat tugas signer config updated successfully",
+		"data":    config,
+	})
+}
@@ -1,21 +1,29 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"perjalanan-dinas/backend/internal/models"
 	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TravelReportHandler struct {
-	repo *repository.Repository
+	repo     *repository.Repository
+	workflow *services.TravelRequestWorkflow
 }
 
 func NewTravelReportHandler(repo *repository.Repository) *TravelReportHandler {
-	return &TravelReportHandler{repo: repo}
+	return &TravelReportHandler{
+		repo:     repo,
+		workflow: services.NewTravelRequestWorkflow(repo),
+	}
 }
 
 type CreateTravelReportRequest struct {
@@ -46,28 +54,17 @@ func (h *TravelReportHandler) CreateTravelReport(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
 		return
 	}
-
-	// Check if report already exists
-	existingReport, err := h.repo.GetTravelReportByRequestID(req.TravelRequestID)
-	if err == nil && existingReport.ID > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Travel report already exists for this request"})
+	if travelRequest.Status == models.TravelStatusCompleted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrTravelReportFiled.Error()})
 		return
 	}
-
-	// Create travel report
-	travelReport := &models.TravelReport{
-		TravelRequestID:        req.TravelRequestID,
-		ReportNumber:           travelRequest.ReportNumber,
-		RepresentativeName:     req.RepresentativeName,
-		RepresentativePosition: req.RepresentativePosition,
-	}
-
-	if err := h.repo.CreateTravelReport(travelReport); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create travel report"})
+	if !services.IsReportableTravelStatus(travelRequest.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Travel report can only be created for approved or in progress travel requests"})
 		return
 	}
 
-	// Create visit proofs
+	// Parse visit proofs before anything is saved
+	proofs := make([]models.VisitProof, 0, len(req.VisitProofs))
 	for _, proofInput := range req.VisitProofs {
 		date, err := time.Parse("2006-01-02", proofInput.Date)
 		if err != nil {
@@ -75,27 +72,81 @@ func (h *TravelReportHandler) CreateTravelReport(c *gin.Context) {
 			return
 		}
 
-		proof := &models.VisitProof{
-			TravelReportID: travelReport.ID,
+		proofs = append(proofs, models.VisitProof{
 			Date:           date,
 			DepartFrom:     proofInput.DepartFrom,
 			StayOrStopAt:   proofInput.StayOrStopAt,
 			ArriveAt:       proofInput.ArriveAt,
 			SignatureProof: proofInput.SignatureProof,
+		})
+	}
+
+	// Fill in the report and complete the trip together: filing the berita acara ends the trip
+	err = h.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, req.TravelRequestID).Error; err != nil {
+			return err
+		}
+		if request.Status == models.TravelStatusCompleted {
+			return services.ErrTravelReportFiled
 		}
 
-		if err := h.repo.CreateVisitProof(proof); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create visit proof"})
-			return
+		// The report is created with the request and pre-filled from its legs;
+		// requests from before that get theirs now
+		var travelReport models.TravelReport
+		err := tx.Where("travel_request_id = ?", request.ID).First(&travelReport).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			travelReport = models.TravelReport{
+				TravelRequestID:        request.ID,
+				ReportNumber:           request.ReportNumber,
+				RepresentativeName:     req.RepresentativeName,
+				RepresentativePosition: req.RepresentativePosition,
+			}
+			if err := tx.Create(&travelReport).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			err := tx.Model(&travelReport).Updates(map[string]interface{}{
+				"representative_name":     req.RepresentativeName,
+				"representative_position": req.RepresentativePosition,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// The visit proofs filed replace the pre-filled ones
+		if len(proofs) > 0 {
+			if err := tx.Where("travel_report_id = ?", travelReport.ID).Delete(&models.VisitProof{}).Error; err != nil {
+				return err
+			}
+			for i := range proofs {
+				proofs[i].TravelReportID = travelReport.ID
+			}
+			if err := tx.Create(&proofs).Error; err != nil {
+				return err
+			}
 		}
-	}
 
-	// Update travel request status
-	travelRequest.Status = "completed"
-	h.repo.UpdateTravelRequest(travelRequest)
+		return h.workflow.CompleteTx(tx, &request, c.GetString("username"), "Berita acara perjalanan dinas dibuat")
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTravelReportFiled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create travel report"})
+		}
+		return
+	}
 
 	// Reload with visit proofs
-	travelReport, _ = h.repo.GetTravelReportByRequestID(req.TravelRequestID)
+	travelReport, _ := h.repo.GetTravelReportByRequestID(req.TravelRequestID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":       "Travel report created successfully",
@@ -1,41 +1,130 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"perjalanan-dinas/backend/internal/models"
 	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/services"
 	"perjalanan-dinas/backend/internal/utils"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type TravelRequestHandler struct {
-	repo *repository.Repository
+	repo          *repository.Repository
+	allowanceCalc *services.AllowanceCalculator
+	workflow      *services.TravelRequestWorkflow
+	numbering     *services.NumberingService
+	register      *services.NumberRegisterService
+	pdfGenerator  *services.PDFGenerator
+	editor        *services.TravelRequestEditor
+	conflicts     *services.TravelConflictService
+	quota         *services.TravelQuotaService
+	policy        *services.TravelPolicyService
+	rates         *services.PositionRateService
+	tiers         *services.DestinationTierService
+	cities        *services.CityService
+	partialDays   *services.PartialDayService
+	holidays      *services.HolidayService
+	exchangeRates *services.ExchangeRateService
 }
 
 func NewTravelRequestHandler(repo *repository.Repository) *TravelRequestHandler {
-	return &TravelRequestHandler{repo: repo}
+	return &TravelRequestHandler{
+		repo:          repo,
+		allowanceCalc: services.NewAllowanceCalculator(),
+		workflow:      services.NewTravelRequestWorkflow(repo),
+		numbering:     services.NewNumberingService(repo),
+		register:      services.NewNumberRegisterService(repo),
+		pdfGenerator:  services.NewPDFGenerator(),
+		editor:        services.NewTravelRequestEditor(repo),
+		conflicts:     services.NewTravelConflictService(repo),
+		quota:         services.NewTravelQuotaService(repo),
+		policy:        services.NewTravelPolicyService(repo),
+		rates:         services.NewPositionRateService(repo),
+		tiers:         services.NewDestinationTierService(repo),
+		cities:        services.NewCityService(repo),
+		partialDays:   services.NewPartialDayService(repo),
+		holidays:      services.NewHolidayService(repo),
+		exchangeRates: services.NewExchangeRateService(repo),
+	}
 }
 
 type CreateTravelRequestRequest struct {
-	EmployeeIDs     []uint `json:"employee_ids" binding:"required,min=1"`
-	Purpose         string `json:"purpose" binding:"required"`
-	DeparturePlace  string `json:"departure_place"`
-	Destination     string `json:"destination" binding:"required"`
-	DestinationType string `json:"destination_type" binding:"required,oneof=in_province outside_province abroad"`
+	EmployeeIDs     []uint             `json:"employee_ids" binding:"required,min=1"`
+	Purpose         string             `json:"purpose" binding:"required"`
+	DeparturePlace  string             `json:"departure_place"`
+	Destination     string             `json:"destination" binding:"required_without=Legs"`
+	DestinationType string             `json:"destination_type" binding:"omitempty,oneof=in_province outside_province abroad"` // Diturunkan dari master kota, hanya dipakai jika tempat tidak ada di master
+	DepartureDate   string             `json:"departure_date" binding:"required_without=Legs"`                                 // Format: 2006-01-02
+	ReturnDate      string             `json:"return_date" binding:"required_without=Legs"`                                    // Format: 2006-01-02
+	DepartureTime   string             `json:"departure_time"`                                                                 // Format: 15:04, untuk aturan hari parsial
+	ReturnTime      string             `json:"return_time"`                                                                    // Format: 15:04
+	Transportation  string             `json:"transportation" binding:"required_without=Legs"`                                 // angkutan umum, pesawat, kereta api
+	Legs            []TravelLegInput   `json:"legs" binding:"omitempty,dive"`                                                  // Itinerary multi-leg, menggantikan tujuan tunggal
+	Participants    []ParticipantInput `json:"participants" binding:"omitempty,dive"`                                          // Peserta non-pegawai
+	Draft           bool               `json:"draft"`                                                                          // Simpan sebagai draft, belum diajukan
+
+	// Required when a travel policy rule asks for a justification
+	PolicyJustification string `json:"policy_justification"`
+
+	// Admin only: save even though employees are already on a trip at the same dates
+	OverrideConflicts     bool   `json:"override_conflicts"`
+	OverrideJustification string `json:"override_justification"`
+}
+
+// ParticipantInput is a traveller who is not an employee
+type ParticipantInput struct {
+	Name              string `json:"name" binding:"required"`
+	Institution       string `json:"institution" binding:"required"`
+	IDNumber          string `json:"id_number"`
+	Role              string `json:"role"`
+	ReceivesAllowance bool   `json:"receives_allowance"`
+	RatePositionID    uint   `json:"rate_position_id" binding:"required_if=ReceivesAllowance true"` // Jabatan yang disetarakan
+}
+
+// TravelLegInput is one leg of a multi-leg itinerary, in travel order
+type TravelLegInput struct {
+	FromPlace       string `json:"from_place" binding:"required"`
+	ToPlace         string `json:"to_place" binding:"required"`
 	DepartureDate   string `json:"departure_date" binding:"required"` // Format: 2006-01-02
-	ReturnDate      string `json:"return_date" binding:"required"`    // Format: 2006-01-02
-	Transportation  string `json:"transportation" binding:"required"` // angkutan umum, pesawat, kereta api
+	ArrivalDate     string `json:"arrival_date" binding:"required"`   // Format: 2006-01-02
+	Transportation  string `json:"transportation" binding:"required"`
+	DestinationType string `json:"destination_type" binding:"omitempty,oneof=in_province outside_province abroad"` // Diturunkan dari master kota
 }
 
-func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
+// preparedTravelRequest is a validated travel request with its allowance calculated,
+// shared by create and preview. Nothing in it has been persisted yet.
+type preparedTravelRequest struct {
+	request    *models.TravelRequest
+	employees  []models.Employee
+	allowances []services.EmployeeAllowance
+	position   models.Position // Kode unit untuk penomoran, mengikuti pegawai pertama
+}
+
+// bindTravelRequest binds the request body and prepares it, see prepareTravelRequest
+func (h *TravelRequestHandler) bindTravelRequest(c *gin.Context) (*preparedTravelRequest, bool) {
 	var req CreateTravelRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return nil, false
+	}
+
+	return h.prepareTravelRequest(c, &req)
+}
+
+// prepareTravelRequest validates and calculates a travel request. On failure it
+// writes the error response and returns false.
+func (h *TravelRequestHandler) prepareTravelRequest(c *gin.Context, req *CreateTravelRequestRequest) (*preparedTravelRequest, bool) {
+	if len(req.Legs) > 0 {
+		return h.prepareItinerary(c, req)
 	}
 
 	// Set default departure place if not provided
@@ -43,60 +132,402 @@ func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
 		req.DeparturePlace = "Surabaya"
 	}
 
-	// Parse dates
+	// The destination type follows from the provinces of both places, not from the client
+	destinationType, err := h.cities.DestinationType(req.DeparturePlace, req.Destination, req.DestinationType)
+	if err != nil {
+		respondDestinationTypeError(c, err)
+		return nil, false
+	}
+	req.DestinationType = destinationType
+
+	// Parse dates. Both are days at the departure place: the trip leaves from it and returns to it.
+	timeZone, err := h.cities.TimeZone(req.DeparturePlace)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cities"})
+		return nil, false
+	}
+
 	departureDate, err := time.Parse("2006-01-02", req.DepartureDate)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid departure date format. Use YYYY-MM-DD"})
-		return
+		return nil, false
 	}
+	departureDate = services.LocalDate(departureDate, timeZone)
 
 	returnDate, err := time.Parse("2006-01-02", req.ReturnDate)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid return date format. Use YYYY-MM-DD"})
-		return
+		return nil, false
 	}
+	returnDate = services.LocalDate(returnDate, timeZone)
 
 	// Validate dates
 	if returnDate.Before(departureDate) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Return date cannot be before departure date"})
-		return
+		return nil, false
 	}
 
-	// Calculate duration
+	if err := services.ValidateTravelTimes(departureDate, returnDate, req.DepartureTime, req.ReturnTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	// Calculate duration and the days paid under the partial day rule of the destination type
 	durationDays := repository.CalculateDurationDays(departureDate, returnDate)
+	allowanceDays, err := h.partialDays.AllowanceDays(req.DestinationType, departureDate, returnDate, req.DepartureTime, req.ReturnTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load partial day rules"})
+		return nil, false
+	}
 
 	// Validate all employees exist and get first employee for position code
-	var firstEmployee *models.Employee
-	for i, empID := range req.EmployeeIDs {
+	employees := make([]models.Employee, 0, len(req.EmployeeIDs))
+	for _, empID := range req.EmployeeIDs {
 		employee, err := h.repo.GetEmployeeByID(empID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Employee with ID %d not found", empID)})
-			return
+			return nil, false
+		}
+		employees = append(employees, *employee)
+	}
+
+	participants, ok := h.prepareParticipants(c, req.Participants)
+	if !ok {
+		return nil, false
+	}
+	conversion, ok := h.applyEffectiveRates(c, departureDate, employees, participants, req.DestinationType == "abroad")
+	if !ok {
+		return nil, false
+	}
+
+	// Destinations in a tier are paid a percentage of the zone rate
+	zone, err := h.tiers.Zone(req.Destination, req.DestinationType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load destination tiers"})
+		return nil, false
+	}
+
+	// Calculate allowance per employee from each employee's own position rate
+	allowances, totalAllowance, err := h.allowanceCalc.CalculateZoneDays(employees, zone, allowanceDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination_type"})
+		return nil, false
+	}
+
+	participantAllowance, err := h.allowanceCalc.CalculateParticipantsZoneDays(participants, zone, allowanceDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	totalAllowance += participantAllowance
+
+	initialStatus := models.TravelStatusSubmitted
+	if req.Draft {
+		initialStatus = models.TravelStatusDraft
+	}
+
+	travelRequest := &models.TravelRequest{
+		Purpose:           req.Purpose,
+		DeparturePlace:    req.DeparturePlace,
+		Destination:       req.Destination,
+		DestinationType:   req.DestinationType,
+		DestinationTier:   zone.Tier,
+		TierMultiplier:    zone.MultiplierPercent,
+		DepartureDate:     departureDate,
+		ReturnDate:        returnDate,
+		DepartureTime:     strings.TrimSpace(req.DepartureTime),
+		ReturnTime:        strings.TrimSpace(req.ReturnTime),
+		DepartureTimeZone: timeZone,
+		ReturnTimeZone:    timeZone,
+		DurationDays:      durationDays,
+		Transportation:    req.Transportation,
+		TotalAllowance:    totalAllowance,
+		Status:            initialStatus,
+		Participants:      participants,
+	}
+	services.ApplyAllowanceDays(travelRequest, allowanceDays)
+	services.ApplyAllowanceConversion(travelRequest, conversion)
+	if !h.applyDayCounts(c, travelRequest) {
+		return nil, false
+	}
+
+	return &preparedTravelRequest{
+		request:    travelRequest,
+		employees:  employees,
+		allowances: allowances,
+		position:   employees[0].Position,
+	}, true
+}
+
+// applyDayCounts counts the weekend days and holidays of a request from the holiday calendar.
+// Returns false when it wrote a response.
+func (h *TravelRequestHandler) applyDayCounts(c *gin.Context, request *models.TravelRequest) bool {
+	if err := h.holidays.ApplyDayCounts(request); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load holiday calendar"})
+		return false
+	}
+	return true
+}
+
+// applyEffectiveRates prices employees and paid participants at the position rates valid on
+// the departure date, so a trip keeps the tariff of its own time. For a trip abroad, abroad
+// rates in a foreign currency are converted to rupiah at the exchange rate of the departure
+// date. Returns false when it wrote a response.
+func (h *TravelRequestHandler) applyEffectiveRates(c *gin.Context, departureDate time.Time, employees []models.Employee, participants []models.TravelParticipant, abroad bool) (*services.AllowanceConversion, bool) {
+	positions := make([]*models.Position, 0, len(employees)+len(participants))
+	for i := range employees {
+		positions = append(positions, &employees[i].Position)
+	}
+	for i := range participants {
+		if participants[i].RatePosition != nil {
+			positions = append(positions, participants[i].RatePosition)
 		}
-		if i == 0 {
-			firstEmployee = employee
+	}
+
+	if err := h.rates.ApplyEffectiveRates(positions, departureDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load allowance rates"})
+		return nil, false
+	}
+	if !abroad {
+		return nil, true
+	}
+
+	conversion, err := h.exchangeRates.ConvertAbroadAllowances(positions, departureDate)
+	if err != nil {
+		if errors.Is(err, services.ErrExchangeRateMissing) || errors.Is(err, services.ErrMixedAllowanceCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert abroad allowance rates"})
+		return nil, false
 	}
+	return conversion, true
+}
 
-	// Get position from first employee
-	position := firstEmployee.Position
+// respondDestinationTypeError writes the response for a destination type that could not be derived
+func respondDestinationTypeError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrDestinationTypeRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cities"})
+}
 
-	// Calculate allowance rate based on destination type
-	var allowanceRate int
-	switch req.DestinationType {
-	case "in_province":
-		allowanceRate = position.AllowanceInProvince
-	case "outside_province":
-		allowanceRate = position.AllowanceOutsideProvince
-	case "abroad":
-		allowanceRate = position.AllowanceAbroad
-	default:
+// prepareItinerary validates and calculates a multi-leg request. The allowance is paid per
+// leg day at the zone of each leg; destination, dates and duration follow from the legs.
+// Partial day rules only apply to single-destination requests.
+func (h *TravelRequestHandler) prepareItinerary(c *gin.Context, req *CreateTravelRequestRequest) (*preparedTravelRequest, bool) {
+	legs := make([]models.TravelLeg, 0, len(req.Legs))
+	for i, input := range req.Legs {
+		departureDate, err := time.Parse("2006-01-02", input.DepartureDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid departure date format in leg %d. Use YYYY-MM-DD", i+1)})
+			return nil, false
+		}
+		arrivalDate, err := time.Parse("2006-01-02", input.ArrivalDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid arrival date format in leg %d. Use YYYY-MM-DD", i+1)})
+			return nil, false
+		}
+
+		legs = append(legs, models.TravelLeg{
+			FromPlace:       input.FromPlace,
+			ToPlace:         input.ToPlace,
+			DepartureDate:   departureDate,
+			ArrivalDate:     arrivalDate,
+			Transportation:  input.Transportation,
+			DestinationType: input.DestinationType,
+		})
+	}
+
+	if err := h.cities.ApplyLegDestinationTypes(legs); err != nil {
+		respondDestinationTypeError(c, err)
+		return nil, false
+	}
+	if err := h.cities.ApplyLegTimeZones(legs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cities"})
+		return nil, false
+	}
+	if err := services.ValidateItinerary(legs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err := h.tiers.ApplyLegTiers(legs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load destination tiers"})
+		return nil, false
+	}
+
+	employees := make([]models.Employee, 0, len(req.EmployeeIDs))
+	for _, empID := range req.EmployeeIDs {
+		employee, err := h.repo.GetEmployeeByID(empID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Employee with ID %d not found", empID)})
+			return nil, false
+		}
+		employees = append(employees, *employee)
+	}
+
+	participants, ok := h.prepareParticipants(c, req.Participants)
+	if !ok {
+		return nil, false
+	}
+	conversion, ok := h.applyEffectiveRates(c, legs[0].DepartureDate, employees, participants, services.ItineraryGoesAbroad(legs))
+	if !ok {
+		return nil, false
+	}
+
+	allowances, totalAllowance, err := h.allowanceCalc.CalculateItinerary(employees, legs)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination_type"})
+		return nil, false
+	}
+
+	participantAllowance, err := h.allowanceCalc.CalculateItineraryParticipants(participants, legs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	totalAllowance += participantAllowance
+
+	initialStatus := models.TravelStatusSubmitted
+	if req.Draft {
+		initialStatus = models.TravelStatusDraft
+	}
+
+	travelRequest := &models.TravelRequest{
+		Purpose:        req.Purpose,
+		TotalAllowance: totalAllowance,
+		Status:         initialStatus,
+		Participants:   participants,
+	}
+	services.ApplyItinerary(travelRequest, legs)
+	services.ApplyAllowanceConversion(travelRequest, conversion)
+	if !h.applyDayCounts(c, travelRequest) {
+		return nil, false
+	}
+
+	return &preparedTravelRequest{
+		request:    travelRequest,
+		employees:  employees,
+		allowances: allowances,
+		position:   employees[0].Position,
+	}, true
+}
+
+// prepareParticipants builds the external participants of a request. Participants receiving
+// allowance get the position they are ranked with, for its rates.
+func (h *TravelRequestHandler) prepareParticipants(c *gin.Context, inputs []ParticipantInput) ([]models.TravelParticipant, bool) {
+	participants := make([]models.TravelParticipant, 0, len(inputs))
+	for _, input := range inputs {
+		participant := models.TravelParticipant{
+			Name:              input.Name,
+			Institution:       input.Institution,
+			IDNumber:          input.IDNumber,
+			Role:              input.Role,
+			ReceivesAllowance: input.ReceivesAllowance,
+		}
+		if input.ReceivesAllowance {
+			position, err := h.repo.GetPositionByID(input.RatePositionID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Position with ID %d not found", input.RatePositionID)})
+				return nil, false
+			}
+			participant.RatePositionID = &position.ID
+			participant.RatePosition = position
+		}
+		participants = append(participants, participant)
+	}
+	return participants, true
+}
+
+// conflictOverride reads the override of schedule conflicts asked for with the request.
+// Only authenticated admins have a username to override with.
+func conflictOverride(c *gin.Context, req *CreateTravelRequestRequest) services.ConflictOverride {
+	return services.ConflictOverride{
+		Requested:     req.OverrideConflicts,
+		Admin:         c.GetString("username"),
+		Justification: req.OverrideJustification,
+	}
+}
+
+// respondConflictError answers a request refused for its schedule conflicts, 409 with every
+// conflict when they were not overridden. Returns false when err is not a ConflictError.
+func respondConflictError(c *gin.Context, err error) bool {
+	var conflictErr *services.ConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+	switch {
+	case errors.Is(err, services.ErrOverrideNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can override schedule conflicts"})
+	case errors.Is(err, services.ErrOverrideJustificationMissing):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Employees are already assigned to overlapping trips",
+			"conflicts": conflictErr.Conflicts,
+		})
+	}
+	return true
+}
+
+// respondQuotaError answers a request stopped by a quota rule in block mode, 422 with every
+// violation. Warn rules are returned with the saved request instead.
+// Returns false when err is not a QuotaError.
+func respondQuotaError(c *gin.Context, err error) bool {
+	var quotaErr *services.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":            "Travel quota exceeded",
+		"quota_violations": quotaErr.Violations,
+	})
+	return true
+}
+
+// checkPolicy evaluates the travel policy rules against the request. Violations, or rules asking
+// for a justification that was not given, stop the request with 422 and the evaluation.
+// Returns false when it wrote a response.
+func (h *TravelRequestHandler) checkPolicy(c *gin.Context, req *CreateTravelRequestRequest, prepared *preparedTravelRequest) bool {
+	subject := services.TravelRequestPolicySubject(prepared.request, prepared.employees)
+	evaluation, err := h.policy.Evaluate(models.ApprovalDocTravelRequest, subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate travel policy"})
+		return false
+	}
+	if err := evaluation.Check(req.PolicyJustification); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  err.Error(),
+			"policy": evaluation,
+		})
+		return false
+	}
+
+	if len(evaluation.RequiredJustifications) > 0 {
+		prepared.request.PolicyJustification = req.PolicyJustification
+	}
+	return true
+}
+
+func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
+	var req CreateTravelRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Calculate total allowance: duration * rate per day * number of employees
-	totalAllowance := durationDays * allowanceRate * len(req.EmployeeIDs)
+	prepared, ok := h.prepareTravelRequest(c, &req)
+	if !ok {
+		return
+	}
+	if !h.checkPolicy(c, &req, prepared) {
+		return
+	}
+	employees := prepared.employees
+	allowances := prepared.allowances
+	position := prepared.position
+	initialStatus := prepared.request.Status
 
 	// Start transaction
 	tx := h.repo.GetDB().Begin()
@@ -112,55 +543,79 @@ func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
 		}
 	}()
 
-	// Get next sequence within transaction
-	var config models.NumberingConfig
-	if err := tx.First(&config).Error; err != nil {
+	// Overlapping trips are checked under lock so two requests cannot book the same employee
+	if err := h.conflicts.CheckConflictsTx(tx, prepared.request, employees, 0, conflictOverride(c, &req)); err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get numbering config"})
+		if !respondConflictError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check schedule conflicts"})
+		}
 		return
 	}
 
-	// Increment sequence untuk Nota Permintaan (misal: 0001)
-	config.LastRequestSequence++
-	requestNumber := utils.GenerateRequestNumber(config.LastRequestSequence, position.Code)
-
-	// Berita Acara menggunakan nomor yang SAMA dengan Nota Permintaan
-	reportNumber := requestNumber
+	// The quota is counted under the same lock so two requests cannot both take the last trip
+	quotaWarnings, err := h.quota.CheckRequestTx(tx, prepared.request, employees, 0)
+	if err != nil {
+		tx.Rollback()
+		if !respondQuotaError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check travel quota"})
+		}
+		return
+	}
 
-	// Save sequence
-	if err := tx.Save(&config).Error; err != nil {
+	// Allocate request number inside the transaction (locked, gapless, per year)
+	allocation, err := h.numbering.AllocateTx(tx, models.DocTypeNotaPermintaan, position.Code, time.Now())
+	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sequence"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to allocate request number"})
 		return
 	}
+	requestNumber := allocation.Number
+
+	// Berita Acara menggunakan nomor yang SAMA dengan Nota Permintaan
+	reportNumber := requestNumber
 
 	// Create travel request
 	// Note: ReportNumber is left empty and will be filled when travel report is created
-	travelRequest := &models.TravelRequest{
-		Purpose:         req.Purpose,
-		DeparturePlace:  req.DeparturePlace,
-		Destination:     req.Destination,
-		DestinationType: req.DestinationType,
-		DepartureDate:   departureDate,
-		ReturnDate:      returnDate,
-		DurationDays:    durationDays,
-		Transportation:  req.Transportation,
-		TotalAllowance:  totalAllowance,
-		RequestNumber:   requestNumber,
-		Status:          "pending",
-	}
-
-	if err := tx.Create(travelRequest).Error; err != nil {
+	travelRequest := prepared.request
+	travelRequest.RequestNumber = requestNumber
+	travelRequest.NumberYear = allocation.Year
+	travelRequest.NumberSequence = allocation.Sequence
+
+	if err := tx.Omit("Legs", "Participants").Create(travelRequest).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create travel request"})
 		return
 	}
 
-	// Create travel request employee relations
-	for _, empID := range req.EmployeeIDs {
+	// Save external participants before the legs that refer to them
+	if err := services.CreateParticipantsTx(tx, travelRequest.ID, travelRequest.Participants); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save participants"})
+		return
+	}
+
+	// Save the itinerary legs with their allowance per employee
+	if err := services.CreateItineraryTx(tx, travelRequest.ID, travelRequest.Legs); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save itinerary"})
+		return
+	}
+
+	// Record initial status, submitted by the first employee
+	if err := services.RecordTravelStatusHistory(tx, travelRequest.ID, "", initialStatus, employees[0].Name, ""); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record status history"})
+		return
+	}
+
+	// Create travel request employee relations with their allowance
+	for _, allowance := range allowances {
 		empRel := &models.TravelRequestEmployee{
 			TravelRequestID: travelRequest.ID,
-			EmployeeID:      empID,
+			EmployeeID:      allowance.EmployeeID,
+			DailyRate:       allowance.DailyRate,
+			DurationDays:    allowance.DurationDays,
+			Subtotal:        allowance.Subtotal,
 		}
 		if err := tx.Create(empRel).Error; err != nil {
 			tx.Rollback()
@@ -169,6 +624,15 @@ func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
 		}
 	}
 
+	// Submitted requests enter their approval chain right away
+	if initialStatus == models.TravelStatusSubmitted {
+		if err := h.workflow.StartApprovalTx(tx, travelRequest); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start approval chain"})
+			return
+		}
+	}
+
 	// Get representative config
 	repConfig, err := h.repo.GetActiveRepresentativeConfig()
 	if err != nil {
@@ -193,9 +657,16 @@ func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
 		return
 	}
 
+	// Pre-fill the visit proofs of the Berita Acara from the legs
+	if err := services.CreateVisitProofsTx(tx, travelReport.ID, travelRequest.Legs); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create visit proofs"})
+		return
+	}
+
 	// Update travel request with report number
 	travelRequest.ReportNumber = reportNumber
-	if err := tx.Save(travelRequest).Error; err != nil {
+	if err := tx.Omit("Legs", "Participants").Save(travelRequest).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update travel request"})
 		return
@@ -214,11 +685,257 @@ func (h *TravelRequestHandler) CreateTravelRequest(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{
 		"message":        "Travel request created successfully",
 		"travel_request": travelRequest,
+		"quota_warnings": quotaWarnings,
 	})
 }
 
+// PreviewTravelRequest runs the same validation and calculation as CreateTravelRequest
+// without saving anything or consuming a number
+func (h *TravelRequestHandler) PreviewTravelRequest(c *gin.Context) {
+	preview, next, ok := h.buildPreview(c)
+	if !ok {
+		return
+	}
+
+	// Overlapping trips are reported as a warning, saving would be refused
+	employees := make([]models.Employee, 0, len(preview.TravelRequestEmployees))
+	for _, empRel := range preview.TravelRequestEmployees {
+		employees = append(employees, empRel.Employee)
+	}
+	conflicts, err := h.conflicts.FindConflicts(preview, employees, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check schedule conflicts"})
+		return
+	}
+	quotaViolations, err := h.quota.CheckRequest(preview, employees, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check travel quota"})
+		return
+	}
+	policy, err := h.policy.Evaluate(models.ApprovalDocTravelRequest, services.TravelRequestPolicySubject(preview, employees))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate travel policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Preview only, travel request has not been saved",
+		"travel_request":   preview,
+		"request_number":   next.Number,
+		"duration_days":    preview.DurationDays,
+		"total_allowance":  preview.TotalAllowance,
+		"conflicts":        conflicts,
+		"quota_violations": quotaViolations,
+		"policy":           policy,
+	})
+}
+
+// PreviewTravelRequestPDF renders the draft Nota Permintaan of a request that has not been saved
+func (h *TravelRequestHandler) PreviewTravelRequestPDF(c *gin.Context) {
+	preview, _, ok := h.buildPreview(c)
+	if !ok {
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateDraftNotaPermintaan(preview)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=draft_nota_permintaan.pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// buildPreview fills a prepared request with everything the Nota shows: employees with their
+// allowance, the number it would get right now and the signing representative
+func (h *TravelRequestHandler) buildPreview(c *gin.Context) (*models.TravelRequest, *services.NumberAllocation, bool) {
+	prepared, ok := h.bindTravelRequest(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	next, err := h.numbering.PeekNext(models.DocTypeNotaPermintaan, prepared.position.Code, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read request number"})
+		return nil, nil, false
+	}
+
+	preview := prepared.request
+	preview.RequestNumber = next.Number
+	preview.ReportNumber = next.Number
+	preview.NumberYear = next.Year
+	preview.NumberSequence = next.Sequence
+
+	for i, allowance := range prepared.allowances {
+		preview.TravelRequestEmployees = append(preview.TravelRequestEmployees, models.TravelRequestEmployee{
+			EmployeeID:   allowance.EmployeeID,
+			Employee:     prepared.employees[i],
+			DailyRate:    allowance.DailyRate,
+			DurationDays: allowance.DurationDays,
+			Subtotal:     allowance.Subtotal,
+		})
+	}
+
+	if repConfig, err := h.repo.GetActiveRepresentativeConfig(); err == nil {
+		preview.TravelReport = &models.TravelReport{
+			ReportNumber:           next.Number,
+			RepresentativeName:     repConfig.Name,
+			RepresentativePosition: repConfig.Position,
+		}
+	}
+
+	return preview, next, true
+}
+
+type UpdateTravelRequestRequest struct {
+	CreateTravelRequestRequest
+	Reason string `json:"reason"` // Alasan perubahan, dicatat di riwayat revisi
+}
+
+// UpdateTravelRequest edits a draft or submitted request. Duration and allowance are
+// recalculated, the number is kept and every changed field is recorded as a revision.
+func (h *TravelRequestHandler) UpdateTravelRequest(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	var req UpdateTravelRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prepared, ok := h.prepareTravelRequest(c, &req.CreateTravelRequestRequest)
+	if !ok {
+		return
+	}
+	if !h.checkPolicy(c, &req.CreateTravelRequestRequest, prepared) {
+		return
+	}
+
+	updated := prepared.request
+	for i, allowance := range prepared.allowances {
+		updated.TravelRequestEmployees = append(updated.TravelRequestEmployees, models.TravelRequestEmployee{
+			EmployeeID:   allowance.EmployeeID,
+			Employee:     prepared.employees[i],
+			DailyRate:    allowance.DailyRate,
+			DurationDays: allowance.DurationDays,
+			Subtotal:     allowance.Subtotal,
+		})
+	}
+
+	revisions, quotaWarnings, err := h.editor.Edit(uint(id), updated, conflictOverride(c, &req.CreateTravelRequestRequest), c.GetString("username"), req.Reason)
+	if err != nil {
+		if respondConflictError(c, err) || respondQuotaError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		case errors.Is(err, services.ErrTravelRequestLocked), errors.Is(err, services.ErrRequesterUnitChanged):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update travel request"})
+		}
+		return
+	}
+
+	travelRequest, _ := h.repo.GetTravelRequestByID(uint(id))
+
+	message := "Travel request updated successfully"
+	if len(revisions) == 0 {
+		message = "No changes to travel request"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        message,
+		"travel_request": travelRequest,
+		"changes":        revisions,
+		"quota_warnings": quotaWarnings,
+	})
+}
+
+// GetTravelConflicts reports every employee booked on overlapping trips within a date range.
+// Defaults to the current month.
+func (h *TravelRequestHandler) GetTravelConflicts(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+
+	var err error
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to date cannot be before from date"})
+		return
+	}
+
+	conflicts, err := h.conflicts.ConflictReport(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build conflict report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":      from.Format("2006-01-02"),
+		"to":        to.Format("2006-01-02"),
+		"total":     len(conflicts),
+		"conflicts": conflicts,
+	})
+}
+
+// GetTravelRequestRevisions returns every recorded field change of a travel request
+func (h *TravelRequestHandler) GetTravelRequestRevisions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	if _, err := h.repo.GetTravelRequestByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+
+	revisions, err := h.repo.GetTravelRequestRevisions(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
 func (h *TravelRequestHandler) GetAllTravelRequests(c *gin.Context) {
-	requests, err := h.repo.GetAllTravelRequests()
+	// Optional filter: ?status=approved,completed
+	statuses, err := services.ParseTravelStatusFilter(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var requests []models.TravelRequest
+	if len(statuses) > 0 {
+		requests, err = h.repo.GetTravelRequestsByStatus(statuses)
+	} else {
+		requests, err = h.repo.GetAllTravelRequests()
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch travel requests"})
 		return
@@ -252,12 +969,193 @@ func (h *TravelRequestHandler) DeleteTravelRequest(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.DeleteTravelRequest(uint(id)); err != nil {
+	// Reason may come as JSON body or query parameter
+	var req DeleteTravelRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = strings.TrimSpace(c.Query("reason"))
+	}
+	if reason == "" {
+		reason = defaultVoidReason
+	}
+
+	// The request number is recorded as void (nomor batal) so it stays accounted for
+	voided, err := h.register.VoidTravelRequest(uint(id), c.GetString("username"), reason)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+			return
+		}
+		if errors.Is(err, services.ErrTravelRequestNotDeletable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete travel request"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Travel request deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Travel request deleted successfully",
+		"voided_number": voided,
+	})
+}
+
+// defaultVoidReason is recorded when an admin deletes a request without giving a reason
+const defaultVoidReason = "Dihapus oleh admin"
+
+type DeleteTravelRequestRequest struct {
+	Reason string `json:"reason"` // Alasan pembatalan nomor
+}
+
+type UpdateTravelRequestStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason"` // Wajib untuk rejected dan cancelled
+}
+
+// UpdateTravelRequestStatus moves a travel request through the status workflow
+func (h *TravelRequestHandler) UpdateTravelRequestStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	var req UpdateTravelRequestStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.workflow.Transition(uint(id), req.Status, c.GetString("username"), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		case errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrApprovalPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidTravelStatus), errors.Is(err, services.ErrStatusReasonRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update travel request status"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Travel request status updated successfully",
+		"travel_request": request,
+	})
+}
+
+type CancelTravelRequestRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CancelTravelRequest cancels a travel request. The request is kept with its reason and date,
+// approved at-cost claims are marked for refund and claims under review become void.
+func (h *TravelRequestHandler) CancelTravelRequest(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	var req CancelTravelRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.workflow.Transition(uint(id), models.TravelStatusCancelled, c.GetString("username"), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrStatusReasonRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel travel request"})
+		}
+		return
+	}
+
+	claims, err := h.repo.GetAtCostClaimsByTravelRequestID(request.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get at-cost claims"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Travel request cancelled successfully",
+		"travel_request": request,
+		"claims":         claims,
+	})
+}
+
+// DownloadCancellationLetter downloads the "Pembatalan Perjalanan Dinas" PDF of a cancelled request
+func (h *TravelRequestHandler) DownloadCancellationLetter(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	request, err := h.repo.GetTravelRequestByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+	if request.Status != models.TravelStatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Travel request is not cancelled"})
+		return
+	}
+
+	claims, err := h.repo.GetAtCostClaimsByTravelRequestID(request.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get at-cost claims"})
+		return
+	}
+
+	pdfBytes, err := h.pdfGenerator.GenerateCancellationLetter(request, claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=pembatalan_"+utils.GenerateCancellationNumber(request.RequestNumber)+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// GetTravelRequestStatusHistory returns the status transitions of a travel request
+func (h *TravelRequestHandler) GetTravelRequestStatusHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid travel request ID"})
+		return
+	}
+
+	request, err := h.repo.GetTravelRequestByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Travel request not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":              request.Status,
+		"allowed_transitions": services.AllowedTravelStatusTransitions(request.Status),
+		"history":             request.StatusHistory,
+	})
 }
 
 // EmployeeSPDStats represents SPD statistics for an employee
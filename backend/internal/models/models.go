@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"perjalanan-dinas/backend/internal/utils"
+
 	"gorm.io/gorm"
 )
 
@@ -11,6 +13,8 @@ type Admin struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
 	Username  string         `gorm:"unique;not null" json:"username"`
 	Password  string         `gorm:"not null" json:"-"`
+	Name      string         `json:"name"`     // Nama lengkap untuk blok tanda tangan
+	Position  string         `json:"position"` // Jabatan untuk blok tanda tangan
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -25,11 +29,51 @@ type Position struct {
 	AllowanceInProvince      int            `gorm:"not null" json:"allowance_in_province"`              // Tarif dalam provinsi
 	AllowanceOutsideProvince int            `gorm:"not null" json:"allowance_outside_province"`         // Tarif luar provinsi
 	AllowanceAbroad          int            `gorm:"not null" json:"allowance_abroad"`                   // Tarif luar negeri
+	AbroadCurrency           string         `gorm:"not null;default:'IDR'" json:"abroad_currency"` // Mata uang tarif luar negeri (ISO 4217)
+	Rates                    []PositionRate `gorm:"foreignKey:PositionID" json:"rates,omitempty"`       // Riwayat tarif berlaku per periode
 	CreatedAt                time.Time      `json:"created_at"`
 	UpdatedAt                time.Time      `json:"updated_at"`
 	DeletedAt                gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// PositionRate is the allowance rate of a position for a validity period. The allowance
+// fields on Position mirror the rate in force today; trips use the rate valid on departure.
+type PositionRate struct {
+	ID                       uint           `gorm:"primarykey" json:"id"`
+	PositionID               uint           `gorm:"not null;index" json:"position_id"`
+	ValidFrom                time.Time      `gorm:"not null" json:"valid_from"`
+	ValidTo                  *time.Time     `json:"valid_to"`                                   // nil = berlaku sampai ada tarif baru
+	AllowanceInProvince      int            `gorm:"not null" json:"allowance_in_province"`      // Tarif dalam provinsi
+	AllowanceOutsideProvince int            `gorm:"not null" json:"allowance_outside_province"` // Tarif luar provinsi
+	AllowanceAbroad          int            `gorm:"not null" json:"allowance_abroad"`           // Tarif luar negeri
+	AbroadCurrency           string         `gorm:"not null;default:'IDR'" json:"abroad_currency"` // Mata uang tarif luar negeri (ISO 4217)
+	CreatedAt                time.Time      `json:"created_at"`
+	UpdatedAt                time.Time      `json:"updated_at"`
+	DeletedAt                gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Position rate change actions
+const (
+	RateChangeCreate = "create"
+	RateChangeUpdate = "update"
+	RateChangeDelete = "delete"
+)
+
+// PositionRateChange is the audit trail of the allowance rates of a position
+type PositionRateChange struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	PositionID     uint           `gorm:"not null;index" json:"position_id"`
+	PositionRateID uint           `gorm:"not null" json:"position_rate_id"`
+	Action         string         `gorm:"not null" json:"action"` // create, update, delete
+	OldValue       string         `gorm:"type:text" json:"old_value"`
+	NewValue       string         `gorm:"type:text" json:"new_value"`
+	ChangedBy      string         `gorm:"not null" json:"changed_by"` // Username admin
+	ChangedAt      time.Time      `gorm:"not null" json:"changed_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
 // Employee represents karyawan
 type Employee struct {
 	ID         uint           `gorm:"primarykey" json:"id"`
@@ -49,27 +93,251 @@ type TravelRequest struct {
 	DeparturePlace         string                  `gorm:"not null;default:'Surabaya'" json:"departure_place"`    // Tempat berangkat asal
 	Destination            string                  `gorm:"not null" json:"destination"`                           // Tempat tujuan (nama kota)
 	DestinationType        string                  `gorm:"not null" json:"destination_type"`                      // "in_province", "outside_province", "abroad"
+	DestinationTier        string                  `json:"destination_tier,omitempty"`                            // Kode tier tujuan (A, B, C, negara), kosong = tarif zona biasa
+	TierMultiplier         int                     `gorm:"not null;default:100" json:"tier_multiplier"`           // Persentase tarif zona yang dibayar, 100 = tarif dasar
 	DepartureDate          time.Time               `gorm:"not null" json:"departure_date"`
 	ReturnDate             time.Time               `gorm:"not null" json:"return_date"`
+	DepartureTime          string                  `json:"departure_time"`                                        // Jam berangkat (HH:MM), kosong = tidak diisi
+	ReturnTime             string                  `json:"return_time"`                                           // Jam tiba kembali (HH:MM), kosong = tidak diisi
+	DepartureTimeZone      string                  `gorm:"not null;default:'Asia/Jakarta'" json:"departure_time_zone"` // Zona waktu tempat berangkat (IANA)
+	ReturnTimeZone         string                  `gorm:"not null;default:'Asia/Jakarta'" json:"return_time_zone"`    // Zona waktu tempat tiba kembali (IANA)
 	DurationDays           int                     `gorm:"not null" json:"duration_days"`                         // Lama perjalanan dinas (auto calculated)
+	PartialDays            bool                    `gorm:"not null;default:false" json:"partial_days"`            // Uang harian dihitung dengan aturan hari parsial
+	DepartureDayPercent    int                     `gorm:"not null;default:0" json:"departure_day_percent"`       // Persentase tarif hari berangkat (atau satu-satunya hari)
+	ReturnDayPercent       int                     `gorm:"not null;default:0" json:"return_day_percent"`          // Persentase tarif hari kembali, 0 untuk perjalanan satu hari
+	PaidDayPercent         int                     `gorm:"not null;default:0" json:"paid_day_percent"`            // Hari yang dibayar x 100, mis. 275 = 2,75 hari
+	WeekendDays            int                     `gorm:"not null;default:0" json:"weekend_days"`                // Hari Sabtu/Minggu selama perjalanan
+	HolidayDays            int                     `gorm:"not null;default:0" json:"holiday_days"`                // Hari libur nasional pada hari kerja selama perjalanan
 	Transportation         string                  `gorm:"not null" json:"transportation"`                        // angkutan umum, pesawat, kereta api
-	TotalAllowance         int                     `gorm:"not null;default:0" json:"total_allowance"`             // Total iuran (auto calculated)
-	RequestNumber          string                  `gorm:"unique;not null" json:"request_number"`                 // 064/{seq}/DIB/{code}/NOTA
-	ReportNumber           string                  `gorm:"unique" json:"report_number"`                           // 064/ /DIB/{code}/NOTA
-	Status                 string                  `gorm:"default:'pending'" json:"status"`                       // pending, approved, completed
+	TotalAllowance         int                     `gorm:"not null;default:0" json:"total_allowance"`             // Total iuran (jumlah subtotal per pegawai)
+	AllowanceCurrency      string                  `json:"allowance_currency,omitempty"`                          // Mata uang tarif luar negeri yang dikonversi, kosong = rupiah
+	AllowanceExchangeRate  float64                 `gorm:"not null;default:0" json:"allowance_exchange_rate"`     // Kurs rupiah per 1 unit AllowanceCurrency
+	AllowanceRateDate      *time.Time              `json:"allowance_rate_date,omitempty"`                         // Tanggal kurs yang dipakai
+	RequestNumber          string                  `gorm:"not null;uniqueIndex:idx_travel_requests_number_year" json:"request_number"` // 064/{seq}/DIB/{code}/NOTA/{year}
+	NumberYear             int                     `gorm:"not null;default:0;uniqueIndex:idx_travel_requests_number_year" json:"number_year"` // Tahun agenda penomoran
+	NumberSequence         int                     `gorm:"not null;default:0" json:"number_sequence"`             // Nomor urut dalam agenda
+	ReportNumber           string                  `gorm:"index" json:"report_number"`                            // 064/ /DIB/{code}/NOTA
+	Status                 string                  `gorm:"default:'submitted'" json:"status"`                     // draft, submitted, approved, rejected, in_progress, completed, cancelled
+	Revision               int                     `gorm:"not null;default:0" json:"revision"`                    // Naik setiap kali permintaan diubah, 0 = asli
+	CancelledAt            *time.Time              `json:"cancelled_at,omitempty"`                                 // Tanggal pembatalan
+	CancelledBy            string                  `json:"cancelled_by,omitempty"`
+	CancellationReason     string                  `gorm:"type:text" json:"cancellation_reason,omitempty"`
+	ConflictOverrideBy     string                  `json:"conflict_override_by,omitempty"`                        // Admin yang menyetujui jadwal bentrok
+	ConflictOverrideReason string                  `gorm:"type:text" json:"conflict_override_reason,omitempty"` // Justifikasi jadwal bentrok
+	PolicyJustification    string                  `gorm:"type:text" json:"policy_justification,omitempty"`     // Justifikasi atas aturan kebijakan perjalanan
 	TravelRequestEmployees []TravelRequestEmployee `gorm:"foreignKey:TravelRequestID" json:"employees"`
+	Legs                   []TravelLeg             `gorm:"foreignKey:TravelRequestID" json:"legs,omitempty"` // Itinerary multi-leg, kosong untuk satu tujuan
+	Participants           []TravelParticipant     `gorm:"foreignKey:TravelRequestID" json:"participants,omitempty"` // Peserta non-pegawai
 	TravelReport           *TravelReport           `gorm:"foreignKey:TravelRequestID" json:"travel_report,omitempty"`
+	StatusHistory          []TravelRequestStatusHistory `gorm:"foreignKey:TravelRequestID" json:"status_history,omitempty"`
+	Revisions              []TravelRequestRevision `gorm:"foreignKey:TravelRequestID" json:"revisions,omitempty"`
+	Amendments             []TravelAmendment       `gorm:"foreignKey:TravelRequestID" json:"amendments,omitempty"`
+	ApprovalSteps          []ApprovalStep          `gorm:"polymorphic:Document;polymorphicValue:travel_request" json:"approval_steps,omitempty"`
 	CreatedAt              time.Time               `json:"created_at"`
 	UpdatedAt              time.Time               `json:"updated_at"`
 	DeletedAt              gorm.DeletedAt          `gorm:"index" json:"-"`
 }
 
+// AfterFind puts the trip dates back in the time zones of their places; the database returns
+// them in the zone of the connection
+func (r *TravelRequest) AfterFind(tx *gorm.DB) error {
+	r.DepartureDate = utils.InTimeZone(r.DepartureDate, r.DepartureTimeZone)
+	r.ReturnDate = utils.InTimeZone(r.ReturnDate, r.ReturnTimeZone)
+	return nil
+}
+
+// TravelLeg is one leg of a multi-leg itinerary, ordered by Sequence. The allowance days of a
+// leg run from its departure up to the departure of the next leg; the last leg runs until arrival.
+type TravelLeg struct {
+	ID                uint                 `gorm:"primarykey" json:"id"`
+	TravelRequestID   uint                 `gorm:"not null;index" json:"travel_request_id"`
+	Sequence          int                  `gorm:"not null" json:"sequence"`   // Urutan leg, mulai dari 1
+	FromPlace         string               `gorm:"not null" json:"from_place"` // Kota asal leg
+	ToPlace           string               `gorm:"not null" json:"to_place"`   // Kota tujuan leg
+	DepartureDate     time.Time            `gorm:"not null" json:"departure_date"`
+	ArrivalDate       time.Time            `gorm:"not null" json:"arrival_date"`
+	DepartureTimeZone string               `gorm:"not null;default:'Asia/Jakarta'" json:"departure_time_zone"` // Zona waktu FromPlace (IANA)
+	ArrivalTimeZone   string               `gorm:"not null;default:'Asia/Jakarta'" json:"arrival_time_zone"`   // Zona waktu ToPlace (IANA)
+	Transportation    string               `gorm:"not null" json:"transportation"`                             // angkutan umum, pesawat, kereta api
+	DestinationType   string               `gorm:"not null" json:"destination_type"`                           // Zona tarif hari-hari leg ini
+	DestinationTier   string               `json:"destination_tier,omitempty"`                                 // Kode tier tujuan leg, kosong = tarif zona biasa
+	TierMultiplier    int                  `gorm:"not null;default:100" json:"tier_multiplier"`                // Persentase tarif zona yang dibayar, 100 = tarif dasar
+	AllowanceDays     int                  `gorm:"not null;default:0" json:"allowance_days"`                   // Hari uang harian yang dihitung pada leg ini
+	Allowances        []TravelLegAllowance `gorm:"foreignKey:TravelLegID" json:"allowances,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt       `gorm:"index" json:"-"`
+}
+
+// AfterFind puts the leg dates back in the time zones of their places
+func (l *TravelLeg) AfterFind(tx *gorm.DB) error {
+	l.DepartureDate = utils.InTimeZone(l.DepartureDate, l.DepartureTimeZone)
+	l.ArrivalDate = utils.InTimeZone(l.ArrivalDate, l.ArrivalTimeZone)
+	return nil
+}
+
+// TravelLegAllowance is the allowance of one traveller for the days of one leg
+type TravelLegAllowance struct {
+	ID            uint               `gorm:"primarykey" json:"id"`
+	TravelLegID   uint               `gorm:"not null;index" json:"travel_leg_id"`
+	EmployeeID    uint               `gorm:"not null;index" json:"employee_id"` // 0 untuk peserta eksternal
+	ParticipantID *uint              `gorm:"index" json:"participant_id,omitempty"`
+	Participant   *TravelParticipant `gorm:"foreignKey:ParticipantID" json:"-"`
+	DailyRate     int                `gorm:"not null;default:0" json:"daily_rate"` // Tarif harian jabatan untuk zona leg
+	Days          int                `gorm:"not null;default:0" json:"days"`
+	Subtotal      int                `gorm:"not null;default:0" json:"subtotal"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt     `gorm:"index" json:"-"`
+}
+
+// TravelParticipant is a traveller who is not in the Employee table: vendors, consultants or
+// staff of other divisions. Admins decide per participant whether an allowance is paid; a paid
+// participant is ranked with a position whose rates apply.
+type TravelParticipant struct {
+	ID                uint           `gorm:"primarykey" json:"id"`
+	TravelRequestID   uint           `gorm:"not null;index" json:"travel_request_id"`
+	Name              string         `gorm:"not null" json:"name"`
+	Institution       string         `gorm:"not null" json:"institution"`                       // Instansi / perusahaan asal
+	IDNumber          string         `json:"id_number"`                                         // NIK, nomor paspor atau nomor pegawai instansi asal
+	Role              string         `json:"role"`                                              // Peran dalam perjalanan, mis. konsultan, narasumber
+	ReceivesAllowance bool           `gorm:"not null;default:false" json:"receives_allowance"`  // Dipilih admin
+	RatePositionID    *uint          `json:"rate_position_id,omitempty"`                        // Jabatan yang disetarakan untuk tarif uang harian
+	RatePosition      *Position      `gorm:"foreignKey:RatePositionID" json:"rate_position,omitempty"`
+	DailyRate         int            `gorm:"not null;default:0" json:"daily_rate"`              // 0 jika tidak menerima uang harian atau multi-leg
+	DurationDays      int            `gorm:"not null;default:0" json:"duration_days"`
+	Subtotal          int            `gorm:"not null;default:0" json:"subtotal"`                // Uang harian peserta, termasuk dalam TotalAllowance
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TravelRequestRevision records one field changed by an edit of a travel request.
+// All fields changed by the same edit share the revision number.
+type TravelRequestRevision struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	TravelRequestID uint           `gorm:"not null;index" json:"travel_request_id"`
+	Revision        int            `gorm:"not null" json:"revision"`
+	Field           string         `gorm:"not null" json:"field"`              // Nama field JSON, mis. purpose, return_date
+	OldValue        string         `gorm:"type:text" json:"old_value"`
+	NewValue        string         `gorm:"type:text" json:"new_value"`
+	ChangedBy       string         `gorm:"not null" json:"changed_by"`         // Username admin
+	Reason          string         `gorm:"type:text" json:"reason"`            // Alasan perubahan
+	ChangedAt       time.Time      `gorm:"not null" json:"changed_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Amendment types
+const (
+	AmendmentTypeExtension   = "extension"    // Perpanjangan
+	AmendmentTypeEarlyReturn = "early_return" // Kembali lebih awal
+)
+
+// TravelAmendment records a change of return date after a travel request was approved
+// (perpanjangan atau kembali lebih awal), with the recalculated allowance
+type TravelAmendment struct {
+	ID                uint                      `gorm:"primarykey" json:"id"`
+	TravelRequestID   uint                      `gorm:"not null;index" json:"travel_request_id"`
+	AmendmentNumber   int                       `gorm:"not null" json:"amendment_number"` // Urutan adendum dalam satu permintaan
+	Type              string                    `gorm:"not null" json:"type"`             // extension, early_return
+	OldReturnDate     time.Time                 `gorm:"not null" json:"old_return_date"`
+	NewReturnDate     time.Time                 `gorm:"not null" json:"new_return_date"`
+	TimeZone          string                    `gorm:"not null;default:'Asia/Jakarta'" json:"time_zone"` // Zona waktu tanggal kembali (IANA)
+	OldReturnTime     string                    `json:"old_return_time"`                                  // Jam tiba kembali semula (HH:MM)
+	NewReturnTime     string                    `json:"new_return_time"`                                  // Jam tiba kembali baru, kosong = tidak diisi
+	OldDurationDays   int                       `gorm:"not null" json:"old_duration_days"`
+	NewDurationDays   int                       `gorm:"not null" json:"new_duration_days"`
+	OldTotalAllowance int                       `gorm:"not null" json:"old_total_allowance"`
+	NewTotalAllowance int                       `gorm:"not null" json:"new_total_allowance"`
+	Reason            string                    `gorm:"type:text;not null" json:"reason"`
+	ApproverID        uint                      `gorm:"not null" json:"approver_id"`       // Admin yang menyetujui
+	ApproverName      string                    `gorm:"not null" json:"approver_name"`
+	ApproverPosition  string                    `json:"approver_position"`
+	CreatedBy         string                    `gorm:"not null" json:"created_by"`        // Username admin yang mencatat
+	Employees         []TravelAmendmentEmployee `gorm:"foreignKey:AmendmentID" json:"employees"`
+	Participants      []TravelAmendmentParticipant `gorm:"foreignKey:AmendmentID" json:"participants,omitempty"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt            `gorm:"index" json:"-"`
+}
+
+// AfterFind puts the return dates back in the time zone of the place of return
+func (a *TravelAmendment) AfterFind(tx *gorm.DB) error {
+	a.OldReturnDate = utils.InTimeZone(a.OldReturnDate, a.TimeZone)
+	a.NewReturnDate = utils.InTimeZone(a.NewReturnDate, a.TimeZone)
+	return nil
+}
+
+// TravelAmendmentEmployee is the allowance change of one traveller caused by an amendment
+type TravelAmendmentEmployee struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	AmendmentID     uint           `gorm:"not null;index" json:"amendment_id"`
+	EmployeeID      uint           `gorm:"not null" json:"employee_id"`
+	Employee        Employee       `gorm:"foreignKey:EmployeeID" json:"employee"`
+	DailyRate       int            `gorm:"not null" json:"daily_rate"`
+	OldDurationDays int            `gorm:"not null" json:"old_duration_days"`
+	NewDurationDays int            `gorm:"not null" json:"new_duration_days"`
+	OldSubtotal     int            `gorm:"not null" json:"old_subtotal"`
+	NewSubtotal     int            `gorm:"not null" json:"new_subtotal"`
+	Delta           int            `gorm:"not null" json:"delta"` // NewSubtotal - OldSubtotal, negatif jika dikembalikan
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TravelAmendmentParticipant is the allowance change of one paid external participant
+type TravelAmendmentParticipant struct {
+	ID              uint              `gorm:"primarykey" json:"id"`
+	AmendmentID     uint              `gorm:"not null;index" json:"amendment_id"`
+	ParticipantID   uint              `gorm:"not null" json:"participant_id"`
+	Participant     TravelParticipant `gorm:"foreignKey:ParticipantID" json:"participant"`
+	DailyRate       int               `gorm:"not null" json:"daily_rate"`
+	OldDurationDays int               `gorm:"not null" json:"old_duration_days"`
+	NewDurationDays int               `gorm:"not null" json:"new_duration_days"`
+	OldSubtotal     int               `gorm:"not null" json:"old_subtotal"`
+	NewSubtotal     int               `gorm:"not null" json:"new_subtotal"`
+	Delta           int               `gorm:"not null" json:"delta"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// Travel request statuses
+const (
+	TravelStatusDraft      = "draft"
+	TravelStatusSubmitted  = "submitted"
+	TravelStatusApproved   = "approved"
+	TravelStatusRejected   = "rejected"
+	TravelStatusInProgress = "in_progress"
+	TravelStatusCompleted  = "completed"
+	TravelStatusCancelled  = "cancelled"
+)
+
+// TravelRequestStatusHistory records each status transition of a travel request
+type TravelRequestStatusHistory struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	TravelRequestID uint           `gorm:"not null;index" json:"travel_request_id"`
+	FromStatus      string         `json:"from_status"`                 // Kosong untuk status awal
+	ToStatus        string         `gorm:"not null" json:"to_status"`
+	ChangedBy       string         `gorm:"not null" json:"changed_by"`  // Username admin atau nama pemohon
+	Reason          string         `gorm:"type:text" json:"reason"`     // Alasan perubahan status
+	ChangedAt       time.Time      `gorm:"not null" json:"changed_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
 // TravelRequestEmployee represents the many-to-many relationship between TravelRequest and Employee
 type TravelRequestEmployee struct {
 	ID               uint           `gorm:"primarykey" json:"id"`
 	TravelRequestID  uint           `gorm:"not null" json:"travel_request_id"`
 	EmployeeID       uint           `gorm:"not null" json:"employee_id"`
 	Employee         Employee       `gorm:"foreignKey:EmployeeID" json:"employee"`
+	DailyRate        int            `gorm:"not null;default:0" json:"daily_rate"`    // Tarif harian sesuai jabatan pegawai
+	DurationDays     int            `gorm:"not null;default:0" json:"duration_days"` // Jumlah hari yang dibayarkan
+	Subtotal         int            `gorm:"not null;default:0" json:"subtotal"`      // Iuran pegawai (tarif x hari)
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
@@ -80,7 +348,7 @@ type TravelReport struct {
 	ID                uint           `gorm:"primarykey" json:"id"`
 	TravelRequestID   uint           `gorm:"unique;not null" json:"travel_request_id"`
 	TravelRequest     TravelRequest  `gorm:"foreignKey:TravelRequestID;constraint:OnDelete:CASCADE" json:"travel_request"`
-	ReportNumber      string         `gorm:"unique;not null" json:"report_number"` // 064/ /DIB/{code}/NOTA
+	ReportNumber      string         `gorm:"not null;index" json:"report_number"` // 064/ /DIB/{code}/NOTA
 	RepresentativeName string        `gorm:"not null" json:"representative_name"` // Nama perwakilan yang tanda tangan
 	RepresentativePosition string    `gorm:"not null" json:"representative_position"` // Jabatan perwakilan
 	VisitProofs       []VisitProof   `gorm:"foreignKey:TravelReportID" json:"visit_proofs"`
@@ -103,7 +371,7 @@ type VisitProof struct {
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-// NumberingConfig untuk manage penomoran
+// NumberingConfig untuk manage penomoran (legacy, digantikan NumberSequence)
 type NumberingConfig struct {
 	ID                    uint           `gorm:"primarykey" json:"id"`
 	LastRequestSequence   int            `gorm:"not null;default:0" json:"last_request_sequence"`
@@ -113,6 +381,42 @@ type NumberingConfig struct {
 	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Numbering document types, each with its own agenda
+const (
+	DocTypeNotaPermintaan = "nota_permintaan"
+	DocTypeAtCostClaim    = "at_cost_claim"
+	DocTypeSuratTugas     = "surat_tugas"
+)
+
+// NumberSequence is the counter of one numbering agenda: per year, document type and unit code.
+// A new year starts a new row, so numbering restarts every January.
+type NumberSequence struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	Year         int       `gorm:"not null;uniqueIndex:idx_number_sequences_key" json:"year"`
+	DocumentType string    `gorm:"not null;uniqueIndex:idx_number_sequences_key" json:"document_type"` // nota_permintaan, at_cost_claim
+	PositionCode string    `gorm:"not null;uniqueIndex:idx_number_sequences_key" json:"position_code"` // DPEB, DPDB, DDBE
+	LastNumber   int       `gorm:"not null;default:0" json:"last_number"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// VoidedNumber mencatat nomor dokumen yang batal (nomor batal), misalnya karena dokumennya dihapus.
+// Nomor yang batal tidak dipakai ulang dan tetap muncul di buku agenda.
+type VoidedNumber struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	DocumentType string    `gorm:"not null;index:idx_voided_numbers_agenda" json:"document_type"` // nota_permintaan, at_cost_claim
+	DocumentID   uint      `gorm:"not null" json:"document_id"`                                  // ID dokumen yang dihapus
+	Year         int       `gorm:"not null;index:idx_voided_numbers_agenda" json:"year"`          // Tahun agenda penomoran
+	PositionCode string    `gorm:"not null;index:idx_voided_numbers_agenda" json:"position_code"` // DPEB, DPDB, DDBE
+	Sequence     int       `gorm:"not null" json:"sequence"`                                     // Nomor urut dalam agenda
+	Number       string    `gorm:"not null" json:"number"`                                       // 064/{seq}/DIB/{code}/NOTA
+	Reason       string    `gorm:"type:text;not null" json:"reason"`                             // Alasan pembatalan
+	VoidedBy     string    `gorm:"not null" json:"voided_by"`                                    // Username admin
+	VoidedAt     time.Time `gorm:"not null" json:"voided_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
 // RepresentativeConfig untuk manage perwakilan penandatangan
 type RepresentativeConfig struct {
 	ID       uint           `gorm:"primarykey" json:"id"`
@@ -129,17 +433,33 @@ type AtCostClaim struct {
 	ID                     uint                    `gorm:"primarykey" json:"id"`
 	TravelRequestID        uint                    `gorm:"not null" json:"travel_request_id"`
 	TravelRequest          TravelRequest           `gorm:"foreignKey:TravelRequestID" json:"travel_request"`
-	ClaimNumber            string                  `gorm:"unique;not null" json:"claim_number"`                 // 064/{seq}/DIB/{code}/NOTA (same sequence)
+	ClaimNumber            string                  `gorm:"not null;uniqueIndex:idx_at_cost_claims_number_year" json:"claim_number"` // 064/{seq}/DIB/{code}/KLAIM/{year}
+	NumberYear             int                     `gorm:"not null;default:0;uniqueIndex:idx_at_cost_claims_number_year" json:"number_year"` // Tahun agenda penomoran
+	NumberSequence         int                     `gorm:"not null;default:0" json:"number_sequence"`           // Nomor urut dalam agenda
 	RepresentativeName     string                  `gorm:"not null" json:"representative_name"`                 // VP name
 	RepresentativePosition string                  `gorm:"not null" json:"representative_position"`             // VP position
-	Status                 string                  `gorm:"default:'pending'" json:"status"`                     // pending, approved, rejected
+	Status                 string                  `gorm:"default:'pending'" json:"status"`                     // pending, approved, rejected, refund_pending, refunded, void
 	TotalAmount            int                     `gorm:"not null;default:0" json:"total_amount"`              // Total semua klaim
+	RefundAmount           int                     `gorm:"not null;default:0" json:"refund_amount"`             // Jumlah yang harus dikembalikan karena perjalanan batal
+	RefundedAt             *time.Time              `json:"refunded_at,omitempty"`
+	PolicyJustification    string                  `gorm:"type:text" json:"policy_justification,omitempty"` // Justifikasi atas aturan kebijakan perjalanan
 	ClaimItems             []AtCostClaimItem       `gorm:"foreignKey:AtCostClaimID" json:"claim_items"`
+	ApprovalSteps          []ApprovalStep          `gorm:"polymorphic:Document;polymorphicValue:at_cost_claim" json:"approval_steps,omitempty"`
 	CreatedAt              time.Time               `json:"created_at"`
 	UpdatedAt              time.Time               `json:"updated_at"`
 	DeletedAt              gorm.DeletedAt          `gorm:"index" json:"-"`
 }
 
+// At-cost claim statuses
+const (
+	ClaimStatusPending       = "pending"
+	ClaimStatusApproved      = "approved"
+	ClaimStatusRejected      = "rejected"
+	ClaimStatusRefundPending = "refund_pending" // Perjalanan batal, dana yang sudah dibayar harus dikembalikan
+	ClaimStatusRefunded      = "refunded"
+	ClaimStatusVoid          = "void" // Perjalanan batal sebelum klaim disetujui
+)
+
 // AtCostClaimItem represents detail klaim per karyawan
 type AtCostClaimItem struct {
 	ID              uint                      `gorm:"primarykey" json:"id"`
@@ -164,7 +484,10 @@ type AtCostReceipt struct {
 	Vendor          string         `json:"vendor"`                                // Traveloka, tiket.com, etc
 	Type            string         `gorm:"not null" json:"type"`                  // flight, hotel, train
 	Description     string         `gorm:"type:text" json:"description"`          // Deskripsi item
-	Amount          int            `gorm:"not null" json:"amount"`                // Nominal
+	Amount          int            `gorm:"not null" json:"amount"`                // Nominal dalam rupiah
+	Currency        string         `gorm:"not null;default:'IDR'" json:"currency"`    // Mata uang receipt (ISO 4217)
+	OriginalAmount  float64        `gorm:"not null;default:0" json:"original_amount"` // Nominal dalam mata uang receipt
+	ExchangeRate    float64        `gorm:"not null;default:1" json:"exchange_rate"`   // Kurs rupiah pada tanggal receipt
 	FilePath        string         `gorm:"not null" json:"file_path"`             // Path ke file PDF
 	FileName        string         `gorm:"not null" json:"file_name"`             // Original filename
 	PassengerName   string         `json:"passenger_name"`                        // Nama penumpang dari receipt
@@ -174,3 +497,285 @@ type AtCostReceipt struct {
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
+
+// Approval document types
+const (
+	ApprovalDocTravelRequest = "travel_request"
+	ApprovalDocAtCostClaim   = "at_cost_claim"
+)
+
+// Approval step statuses
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+	ApprovalStatusSkipped  = "skipped"
+)
+
+// ApprovalRoute defines the approval chain for a document type, per requester position and amount
+type ApprovalRoute struct {
+	ID            uint                `gorm:"primarykey" json:"id"`
+	Name          string              `gorm:"not null" json:"name"`
+	DocumentType  string              `gorm:"not null;index" json:"document_type"`    // travel_request, at_cost_claim
+	PositionCode  string              `json:"position_code"`                          // Kode unit pemohon (DPEB, DPDB, DDBE), kosong = semua
+	PositionLevel string              `json:"position_level"`                         // Level pemohon, kosong = semua
+	MinAmount     int                 `gorm:"not null;default:0" json:"min_amount"`   // Berlaku jika nominal >= MinAmount
+	IsActive      bool                `gorm:"not null;default:true" json:"is_active"`
+	Steps         []ApprovalRouteStep `gorm:"foreignKey:RouteID" json:"steps"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt      `gorm:"index" json:"-"`
+}
+
+// ApprovalRouteStep is one approver in an approval route
+type ApprovalRouteStep struct {
+	ID         uint           `gorm:"primarykey" json:"id"`
+	RouteID    uint           `gorm:"not null;index" json:"route_id"`
+	StepOrder  int            `gorm:"not null" json:"step_order"`
+	ApproverID uint           `gorm:"not null" json:"approver_id"`          // Admin yang menyetujui
+	Approver   Admin          `gorm:"foreignKey:ApproverID" json:"approver"`
+	MinAmount  int            `gorm:"not null;default:0" json:"min_amount"` // Step wajib jika nominal >= MinAmount (mis. VP untuk > Rp 5 juta)
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ApprovalStep records one approval step against a travel request or at-cost claim
+type ApprovalStep struct {
+	ID               uint           `gorm:"primarykey" json:"id"`
+	DocumentType     string         `gorm:"not null;index:idx_approval_steps_document" json:"document_type"`
+	DocumentID       uint           `gorm:"not null;index:idx_approval_steps_document" json:"document_id"`
+	StepOrder        int            `gorm:"not null" json:"step_order"`
+	ApproverID       uint           `gorm:"not null;index" json:"approver_id"`
+	ApproverName     string         `gorm:"not null" json:"approver_name"`
+	ApproverPosition string         `gorm:"not null" json:"approver_position"`
+	Status           string         `gorm:"not null;default:'pending'" json:"status"` // pending, approved, rejected, skipped
+	Note             string         `gorm:"type:text" json:"note"`
+	DecidedAt        *time.Time     `json:"decided_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Quota enforcement modes
+const (
+	QuotaEnforcementBlock = "block"
+	QuotaEnforcementWarn  = "warn"
+)
+
+// TravelQuotaRule limits how much the employees of a position level may travel
+type TravelQuotaRule struct {
+	ID                 uint           `gorm:"primarykey" json:"id"`
+	PositionLevel      string         `gorm:"not null;index" json:"position_level"`           // Jr. Officer, Officer, Senior Officer, AVP
+	MaxTripsPerYear    int            `gorm:"not null;default:0" json:"max_trips_per_year"`   // Maksimal SPD per tahun, 0 = tidak dibatasi
+	MaxConsecutiveDays int            `gorm:"not null;default:0" json:"max_consecutive_days"` // Maksimal hari dinas berturut-turut dalam sebulan, 0 = tidak dibatasi
+	Enforcement        string         `gorm:"not null;default:'warn'" json:"enforcement"`     // block, warn
+	IsActive           bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Policy rule effects
+const (
+	PolicyEffectDeny    = "deny"
+	PolicyEffectJustify = "justify"
+)
+
+// TravelPolicyRule is one declarative travel policy rule. A rule matches when every condition
+// that is set holds; empty conditions match anything. A matching deny rule is a violation,
+// a matching justify rule requires a written justification.
+type TravelPolicyRule struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	Name            string         `gorm:"not null" json:"name"`
+	Message         string         `gorm:"type:text" json:"message"`                   // Pesan yang ditampilkan ke pemohon
+	DocumentType    string         `gorm:"index" json:"document_type"`                 // travel_request, at_cost_claim, kosong = keduanya
+	PositionLevel   string         `json:"position_level"`                             // Level pelaku perjalanan, kosong = semua
+	DestinationType string         `json:"destination_type"`                           // in_province, outside_province, abroad, kosong = semua
+	TransportMode   string         `json:"transport_mode"`                             // Moda angkutan (pesawat, kereta api), kosong = semua
+	MinDurationDays int            `gorm:"not null;default:0" json:"min_duration_days"` // Berlaku jika lama perjalanan >= nilai ini, 0 = tanpa batas
+	MaxDurationDays int            `gorm:"not null;default:0" json:"max_duration_days"` // Berlaku jika lama perjalanan <= nilai ini, 0 = tanpa batas
+	NonWorkingDays  bool           `gorm:"not null;default:false" json:"non_working_days"` // Berlaku hanya jika perjalanan mencakup akhir pekan atau hari libur
+	Effect          string         `gorm:"not null" json:"effect"`                     // deny, justify
+	IsActive        bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// DestinationTier is a rate tier within an allowance zone, e.g. domestic tier A for Jakarta and
+// Bali or one country abroad. The daily rate of a traveller is their position rate for the zone
+// times MultiplierPercent. Destinations without a tier are paid the plain zone rate.
+type DestinationTier struct {
+	ID                uint                  `gorm:"primarykey" json:"id"`
+	Code              string                `gorm:"not null;index" json:"code"`                     // A, B, C, atau kode negara (JP, SG)
+	Name              string                `gorm:"not null" json:"name"`
+	DestinationType   string                `gorm:"not null" json:"destination_type"`               // Zona tempat tier berlaku: in_province, outside_province, abroad
+	Country           string                `json:"country"`                                        // Negara (nama atau kode ISO) untuk tier luar negeri, cocok untuk setiap kota master di negara itu
+	MultiplierPercent int                   `gorm:"not null;default:100" json:"multiplier_percent"` // 150 = 1,5 x tarif zona
+	Cities            []DestinationTierCity `gorm:"foreignKey:TierID" json:"cities"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt        `gorm:"index" json:"-"`
+}
+
+// DestinationTierCity maps a city to its destination tier
+type DestinationTierCity struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	TierID    uint           `gorm:"not null;index" json:"tier_id"`
+	CityName  string         `gorm:"not null;index" json:"city_name"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// City kinds of the city master
+const (
+	CityKindCity    = "city"
+	CityKindRegency = "regency"
+	CityKindCountry = "country"
+)
+
+// City is a destination of the city master: a city or regency with its province, or a foreign
+// city or country. The destination type of a trip follows from comparing the province and
+// country of its departure place and destination.
+type City struct {
+	ID           uint           `gorm:"primarykey" json:"id"`
+	Name         string         `gorm:"not null;index" json:"name"`
+	Kind         string         `gorm:"not null;default:city" json:"kind"` // city, regency, country
+	ProvinceCode string         `gorm:"index" json:"province_code"`        // Kode provinsi BPS, kosong untuk luar negeri
+	ProvinceName string         `json:"province_name"`
+	CountryCode  string         `gorm:"not null;index" json:"country_code"` // Kode negara ISO 3166-1 alpha-2, ID = Indonesia
+	CountryName  string         `json:"country_name"`
+	TimeZone     string         `gorm:"not null;default:'Asia/Jakarta'" json:"time_zone"` // Zona waktu IANA, mis. Asia/Makassar (WITA)
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// PartialDayRule sets how much of the daily rate is paid for incomplete days of a trip to a
+// destination type: the departure and return day, and same-day trips shorter than a number
+// of hours.
+type PartialDayRule struct {
+	ID                  uint           `gorm:"primarykey" json:"id"`
+	DestinationType     string         `gorm:"not null;index" json:"destination_type"`         // in_province, outside_province, abroad
+	ShortTripMaxHours   int            `gorm:"not null;default:0" json:"short_trip_max_hours"` // Perjalanan satu hari kurang dari jam ini dibayar ShortTripPercent, 0 = tidak berlaku
+	ShortTripPercent    int            `gorm:"not null;default:0" json:"short_trip_percent"`   // Persentase tarif perjalanan singkat
+	DepartureDayPercent int            `gorm:"not null" json:"departure_day_percent"`          // Persentase tarif hari berangkat
+	ReturnDayPercent    int            `gorm:"not null" json:"return_day_percent"`             // Persentase tarif hari kembali
+	IsActive            bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Holiday is a national holiday or collective leave day of the working-day calendar
+type Holiday struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	Date            time.Time      `gorm:"type:date;not null;index" json:"date"`
+	Name            string         `gorm:"not null" json:"name"`
+	CollectiveLeave bool           `gorm:"not null;default:false" json:"collective_leave"` // Cuti bersama
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Currency codes
+const (
+	CurrencyIDR = "IDR"
+)
+
+// Exchange rate sources
+const (
+	ExchangeRateSourceBI     = "bi"     // Kurs tengah Bank Indonesia
+	ExchangeRateSourceManual = "manual" // Diisi admin
+)
+
+// ExchangeRate is the rupiah value of one unit of a foreign currency on a date. Amounts are
+// converted at the latest rate on or before their own date.
+type ExchangeRate struct {
+	ID         uint           `gorm:"primarykey" json:"id"`
+	Currency   string         `gorm:"not null;index:idx_exchange_rates_currency_date" json:"currency"`       // Kode ISO 4217, mis. USD
+	Date       time.Time      `gorm:"type:date;not null;index:idx_exchange_rates_currency_date" json:"date"` // Tanggal berlaku kurs
+	MiddleRate float64        `gorm:"not null" json:"middle_rate"`                                           // Rupiah per 1 unit valuta asing
+	Source     string         `gorm:"not null;default:'manual'" json:"source"`                               // bi, manual
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Travel advance statuses
+const (
+	AdvanceStatusDisbursed = "disbursed" // Uang muka sudah dibayarkan, belum dipertanggungjawabkan
+	AdvanceStatusSettled   = "settled"   // Uang muka sudah dipertanggungjawabkan
+)
+
+// TravelAdvance is the uang muka paid to the employees of a travel request before departure.
+// Settling it nets the advance of every employee against their final allowance and approved
+// at-cost claims.
+type TravelAdvance struct {
+	ID               uint                    `gorm:"primarykey" json:"id"`
+	TravelRequestID  uint                    `gorm:"not null;index" json:"travel_request_id"`
+	Status           string                  `gorm:"not null;default:'disbursed'" json:"status"`  // disbursed, settled
+	DisbursementDate time.Time               `gorm:"type:date;not null" json:"disbursement_date"` // Tanggal uang muka dibayarkan
+	TotalAmount      int                     `gorm:"not null;default:0" json:"total_amount"`      // Jumlah uang muka
+	TotalAllowance   int                     `gorm:"not null;default:0" json:"total_allowance"`   // Uang harian final, diisi saat pertanggungjawaban
+	TotalClaims      int                     `gorm:"not null;default:0" json:"total_claims"`      // Klaim at-cost yang disetujui
+	TotalBalance     int                     `gorm:"not null;default:0" json:"total_balance"`     // Uang harian + klaim - uang muka
+	Notes            string                  `gorm:"type:text" json:"notes"`
+	CreatedBy        string                  `gorm:"not null" json:"created_by"` // Username admin yang mencatat
+	SettledAt        *time.Time              `json:"settled_at,omitempty"`
+	SettledBy        string                  `json:"settled_by,omitempty"`
+	Employees        []TravelAdvanceEmployee `gorm:"foreignKey:AdvanceID" json:"employees"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt          `gorm:"index" json:"-"`
+}
+
+// TravelAdvanceEmployee is the advance of one employee and, once settled, what it nets to
+type TravelAdvanceEmployee struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	AdvanceID   uint           `gorm:"not null;index" json:"advance_id"`
+	EmployeeID  uint           `gorm:"not null" json:"employee_id"`
+	Employee    Employee       `gorm:"foreignKey:EmployeeID" json:"employee"`
+	Amount      int            `gorm:"not null" json:"amount"`                 // Uang muka yang diterima
+	Allowance   int            `gorm:"not null;default:0" json:"allowance"`    // Uang harian final
+	ClaimAmount int            `gorm:"not null;default:0" json:"claim_amount"` // Klaim at-cost yang disetujui
+	Balance     int            `gorm:"not null;default:0" json:"balance"`      // Positif = kurang bayar (dibayar ke pegawai), negatif = lebih bayar (dikembalikan pegawai)
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// SuratTugas is the assignment letter (surat tugas perjalanan dinas) issued when a travel
+// request is approved, numbered in its own agenda. The signer is copied from the signer config
+// when the letter is issued, so changing the config does not alter letters already issued.
+type SuratTugas struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	TravelRequestID uint           `gorm:"not null;uniqueIndex" json:"travel_request_id"`
+	LetterNumber    string         `gorm:"not null;uniqueIndex:idx_surat_tugas_number_year" json:"letter_number"`         // 064/{seq}/DIB/{code}/ST/{year}
+	NumberYear      int            `gorm:"not null;default:0;uniqueIndex:idx_surat_tugas_number_year" json:"number_year"` // Tahun agenda penomoran
+	NumberSequence  int            `gorm:"not null;default:0" json:"number_sequence"`                                     // Nomor urut dalam agenda
+	IssueDate       time.Time      `gorm:"type:date;not null" json:"issue_date"`                                          // Tanggal surat tugas
+	IssuePlace      string         `json:"issue_place"`                                                                   // Tempat surat tugas dibuat
+	SignerName      string         `json:"signer_name"`                                                                   // Kosong = diisi saat ditandatangani
+	SignerNIP       string         `json:"signer_nip"`
+	SignerPosition  string         `json:"signer_position"`
+	SignerUnit      string         `json:"signer_unit"` // Unit penerbit, mis. DIVISI HUMAN CAPITAL
+	IssuedBy        string         `json:"issued_by"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// SuratTugasSignerConfig is the official who signs new surat tugas
+type SuratTugasSignerConfig struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Name      string    `json:"name"` // Kosong = nama ditulis tangan saat ditandatangani
+	NIP       string    `gorm:"column:nip" json:"nip"`
+	Position  string    `gorm:"not null" json:"position"`
+	Unit      string    `gorm:"not null" json:"unit"` // Unit penerbit, mis. DIVISI HUMAN CAPITAL
+	City      string    `gorm:"not null;default:'Surabaya'" json:"city"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"errors"
-	"fmt"
 	"perjalanan-dinas/backend/internal/models"
 	"time"
 
@@ -28,6 +27,26 @@ func (r *Repository) GetAdminByUsername(username string) (*models.Admin, error)
 	return &admin, err
 }
 
+func (r *Repository) GetAdminByID(id uint) (*models.Admin, error) {
+	var admin models.Admin
+	err := r.db.First(&admin, id).Error
+	return &admin, err
+}
+
+func (r *Repository) GetAllAdmins() ([]models.Admin, error) {
+	var admins []models.Admin
+	err := r.db.Order("id ASC").Find(&admins).Error
+	return admins, err
+}
+
+func (r *Repository) CreateAdmin(admin *models.Admin) error {
+	return r.db.Create(admin).Error
+}
+
+func (r *Repository) UpdateAdmin(admin *models.Admin) error {
+	return r.db.Save(admin).Error
+}
+
 // Position operations
 func (r *Repository) GetAllPositions() ([]models.Position, error) {
 	var positions []models.Position
@@ -41,6 +60,51 @@ func (r *Repository) GetPositionByID(id uint) (*models.Position, error) {
 	return &position, err
 }
 
+// GetPositionWithRates returns a position with its rates, oldest first
+func (r *Repository) GetPositionWithRates(id uint) (*models.Position, error) {
+	var position models.Position
+	err := r.db.
+		Preload("Rates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("valid_from ASC")
+		}).
+		First(&position, id).Error
+	return &position, err
+}
+
+func (r *Repository) UpdatePosition(position *models.Position) error {
+	return r.db.Omit("Rates").Save(position).Error
+}
+
+// CountEmployeesByPosition returns how many employees hold a position
+func (r *Repository) CountEmployeesByPosition(positionID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Employee{}).Where("position_id = ?", positionID).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) DeletePosition(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("position_id = ?", id).Delete(&models.PositionRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Position{}, id).Error
+	})
+}
+
+// GetPositionRatesByPositionIDs returns the rates of several positions, oldest first
+func (r *Repository) GetPositionRatesByPositionIDs(positionIDs []uint) ([]models.PositionRate, error) {
+	var rates []models.PositionRate
+	err := r.db.Where("position_id IN ?", positionIDs).Order("position_id ASC, valid_from ASC").Find(&rates).Error
+	return rates, err
+}
+
+// GetPositionRateChanges returns the rate audit trail of a position, newest first
+func (r *Repository) GetPositionRateChanges(positionID uint) ([]models.PositionRateChange, error) {
+	var changes []models.PositionRateChange
+	err := r.db.Where("position_id = ?", positionID).Order("changed_at DESC, id DESC").Find(&changes).Error
+	return changes, err
+}
+
 // Employee operations
 func (r *Repository) CreateEmployee(employee *models.Employee) error {
 	return r.db.Create(employee).Error
@@ -77,34 +141,6 @@ func (r *Repository) UpdateNumberingConfig(config *models.NumberingConfig) error
 	return r.db.Save(config).Error
 }
 
-func (r *Repository) GetNextRequestSequence() (int, error) {
-	config, err := r.GetNumberingConfig()
-	if err != nil {
-		return 0, err
-	}
-
-	config.LastRequestSequence++
-	if err := r.UpdateNumberingConfig(config); err != nil {
-		return 0, err
-	}
-
-	return config.LastRequestSequence, nil
-}
-
-func (r *Repository) GetNextReportSequence() (int, error) {
-	config, err := r.GetNumberingConfig()
-	if err != nil {
-		return 0, err
-	}
-
-	config.LastReportSequence++
-	if err := r.UpdateNumberingConfig(config); err != nil {
-		return 0, err
-	}
-
-	return config.LastReportSequence, nil
-}
-
 // TravelRequest operations
 func (r *Repository) CreateTravelRequest(request *models.TravelRequest) error {
 	return r.db.Create(request).Error
@@ -112,7 +148,35 @@ func (r *Repository) CreateTravelRequest(request *models.TravelRequest) error {
 
 func (r *Repository) GetAllTravelRequests() ([]models.TravelRequest, error) {
 	var requests []models.TravelRequest
-	err := r.db.Preload("TravelRequestEmployees.Employee.Position").Order("id DESC").Find(&requests).Error
+	err := r.db.Preload("TravelRequestEmployees.Employee.Position").
+		Preload("Amendments.Employees").
+		Preload("Legs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Preload("Legs.Allowances").
+		Order("id DESC").Find(&requests).Error
+	return requests, err
+}
+
+// GetTravelRequestsByStatus returns travel requests whose status is one of statuses
+func (r *Repository) GetTravelRequestsByStatus(statuses []string) ([]models.TravelRequest, error) {
+	var requests []models.TravelRequest
+	err := r.db.Preload("TravelRequestEmployees", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).
+		Preload("TravelRequestEmployees.Employee.Position").
+		Preload("TravelReport").
+		Preload("Amendments.Employees").
+		Preload("Legs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Preload("Legs.Allowances").
+		Preload("Participants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("status IN ?", statuses).
+		Order("id DESC").
+		Find(&requests).Error
 	return requests, err
 }
 
@@ -120,10 +184,68 @@ func (r *Repository) GetTravelRequestByID(id uint) (*models.TravelRequest, error
 	var request models.TravelRequest
 	err := r.db.Preload("TravelRequestEmployees.Employee.Position").
 		Preload("TravelReport").
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("amendment_number ASC")
+		}).
+		Preload("Amendments.Employees").
+		Preload("Legs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Preload("Legs.Allowances").
+		Preload("Participants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Participants.RatePosition").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("changed_at ASC, id ASC")
+		}).
+		Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC")
+		}).
 		First(&request, id).Error
 	return &request, err
 }
 
+// GetTravelRequestStatusHistory returns the status transitions of a travel request, oldest first
+func (r *Repository) GetTravelRequestStatusHistory(requestID uint) ([]models.TravelRequestStatusHistory, error) {
+	var history []models.TravelRequestStatusHistory
+	err := r.db.Where("travel_request_id = ?", requestID).
+		Order("changed_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// GetTravelRequestRevisions returns the field changes of a travel request, oldest first
+func (r *Repository) GetTravelRequestRevisions(requestID uint) ([]models.TravelRequestRevision, error) {
+	var revisions []models.TravelRequestRevision
+	err := r.db.Where("travel_request_id = ?", requestID).
+		Order("revision ASC, id ASC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetTravelAmendments returns the amendments of a travel request, oldest first
+func (r *Repository) GetTravelAmendments(requestID uint) ([]models.TravelAmendment, error) {
+	var amendments []models.TravelAmendment
+	err := r.db.Preload("Employees.Employee.Position").
+		Preload("Participants.Participant").
+		Where("travel_request_id = ?", requestID).
+		Order("amendment_number ASC").
+		Find(&amendments).Error
+	return amendments, err
+}
+
+func (r *Repository) GetTravelAmendmentByID(id uint) (*models.TravelAmendment, error) {
+	var amendment models.TravelAmendment
+	err := r.db.Preload("Employees", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Employees.Employee.Position").
+		Preload("Participants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).Preload("Participants.Participant").First(&amendment, id).Error
+	return &amendment, err
+}
+
 func (r *Repository) UpdateTravelRequest(request *models.TravelRequest) error {
 	return r.db.Save(request).Error
 }
@@ -166,38 +288,18 @@ func (r *Repository) DeleteVisitProof(id uint) error {
 	return r.db.Delete(&models.VisitProof{}, id).Error
 }
 
-// Calculate duration days
+// Calculate duration days. The days are counted on the local calendars of both dates, so a
+// trip from Jakarta (WIB) to Jayapura (WIT) is not cut short by the time difference.
 func CalculateDurationDays(departure, returnDate time.Time) int {
-	duration := returnDate.Sub(departure)
-	days := int(duration.Hours() / 24)
+	from := time.Date(departure.Year(), departure.Month(), departure.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(returnDate.Year(), returnDate.Month(), returnDate.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(to.Sub(from).Hours() / 24)
 	if days <= 0 {
 		return 1
 	}
 	return days + 1 // Include both departure and return day
 }
 
-// Generate request number: 064/{seq}/DIB/{code}/NOTA
-func (r *Repository) GenerateRequestNumber(employeeID uint) (string, error) {
-	employee, err := r.GetEmployeeByID(employeeID)
-	if err != nil {
-		return "", err
-	}
-
-	position, err := r.GetPositionByID(employee.PositionID)
-	if err != nil {
-		return "", errors.New("position not found for employee")
-	}
-
-	seq, err := r.GetNextRequestSequence()
-	if err != nil {
-		return "", err
-	}
-
-	// Format: 064/{seq}/DIB/{code}/NOTA
-	requestNumber := formatRequestNumber(seq, position.Code)
-	return requestNumber, nil
-}
-
 // Generate report number: 064/ /DIB/{code}/NOTA
 func (r *Repository) GenerateReportNumber(employeeID uint) (string, error) {
 	employee, err := r.GetEmployeeByID(employeeID)
@@ -215,22 +317,10 @@ func (r *Repository) GenerateReportNumber(employeeID uint) (string, error) {
 	return reportNumber, nil
 }
 
-func formatRequestNumber(seq int, code string) string {
-	return "064/" + padLeft(seq, 4) + "/DIB/" + code + "/NOTA"
-}
-
 func formatReportNumber(code string) string {
 	return "064/    /DIB/" + code + "/NOTA"
 }
 
-func padLeft(num int, length int) string {
-	numStr := fmt.Sprintf("%d", num)
-	if len(numStr) >= length {
-		return numStr
-	}
-	return fmt.Sprintf("%0"+fmt.Sprintf("%d", length)+"d", num)
-}
-
 // CreateTravelRequestEmployee creates a new travel request employee relation
 func (r *Repository) CreateTravelRequestEmployee(empRel *models.TravelRequestEmployee) error {
 	return r.db.Create(empRel).Error
@@ -273,6 +363,9 @@ func (r *Repository) GetAtCostClaimByID(id uint) (*models.AtCostClaim, error) {
 		Preload("ClaimItems.Employee").
 		Preload("ClaimItems.Employee.Position").
 		Preload("ClaimItems.Receipts").
+		Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC")
+		}).
 		First(&claim, id).Error
 	return &claim, err
 }
@@ -290,6 +383,16 @@ func (r *Repository) GetAtCostClaimByTravelRequestID(travelRequestID uint) (*mod
 	return &claim, err
 }
 
+// GetAtCostClaimsByTravelRequestID returns every at-cost claim of a travel request, oldest first
+func (r *Repository) GetAtCostClaimsByTravelRequestID(travelRequestID uint) ([]models.AtCostClaim, error) {
+	var claims []models.AtCostClaim
+	err := r.db.
+		Where("travel_request_id = ?", travelRequestID).
+		Order("id ASC").
+		Find(&claims).Error
+	return claims, err
+}
+
 func (r *Repository) GetAllAtCostClaims() ([]models.AtCostClaim, error) {
 	var claims []models.AtCostClaim
 	err := r.db.
@@ -339,3 +442,376 @@ func (r *Repository) GetAtCostReceiptsByClaimItemID(claimItemID uint) ([]models.
 func (r *Repository) DeleteAtCostReceipt(id uint) error {
 	return r.db.Delete(&models.AtCostReceipt{}, id).Error
 }
+
+// Approval route operations
+func (r *Repository) GetAllApprovalRoutes() ([]models.ApprovalRoute, error) {
+	var routes []models.ApprovalRoute
+	err := r.db.
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC")
+		}).
+		Preload("Steps.Approver").
+		Order("document_type ASC, id ASC").
+		Find(&routes).Error
+	return routes, err
+}
+
+func (r *Repository) GetActiveApprovalRoutes(documentType string) ([]models.ApprovalRoute, error) {
+	var routes []models.ApprovalRoute
+	err := r.db.
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC")
+		}).
+		Preload("Steps.Approver").
+		Where("document_type = ? AND is_active = ?", documentType, true).
+		Find(&routes).Error
+	return routes, err
+}
+
+func (r *Repository) GetApprovalRouteByID(id uint) (*models.ApprovalRoute, error) {
+	var route models.ApprovalRoute
+	err := r.db.
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC")
+		}).
+		Preload("Steps.Approver").
+		First(&route, id).Error
+	return &route, err
+}
+
+func (r *Repository) CreateApprovalRoute(route *models.ApprovalRoute) error {
+	return r.db.Create(route).Error
+}
+
+// ReplaceApprovalRoute updates a route and replaces all of its steps
+func (r *Repository) ReplaceApprovalRoute(route *models.ApprovalRoute) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("route_id = ?", route.ID).Delete(&models.ApprovalRouteStep{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Steps").Save(route).Error; err != nil {
+			return err
+		}
+		for i := range route.Steps {
+			route.Steps[i].ID = 0
+			route.Steps[i].RouteID = route.ID
+		}
+		if len(route.Steps) == 0 {
+			return nil
+		}
+		return tx.Omit("Approver").Create(&route.Steps).Error
+	})
+}
+
+func (r *Repository) DeleteApprovalRoute(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("route_id = ?", id).Delete(&models.ApprovalRouteStep{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ApprovalRoute{}, id).Error
+	})
+}
+
+// Approval step operations
+func (r *Repository) GetApprovalStepsByDocument(documentType string, documentID uint) ([]models.ApprovalStep, error) {
+	var steps []models.ApprovalStep
+	err := r.db.Where("document_type = ? AND document_id = ?", documentType, documentID).
+		Order("step_order ASC").
+		Find(&steps).Error
+	return steps, err
+}
+
+func (r *Repository) GetPendingApprovalStepsByApprover(approverID uint) ([]models.ApprovalStep, error) {
+	var steps []models.ApprovalStep
+	err := r.db.Where("approver_id = ? AND status = ?", approverID, models.ApprovalStatusPending).
+		Order("created_at ASC").
+		Find(&steps).Error
+	return steps, err
+}
+
+// Travel quota rule operations
+func (r *Repository) GetAllTravelQuotaRules() ([]models.TravelQuotaRule, error) {
+	var rules []models.TravelQuotaRule
+	err := r.db.Order("position_level ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *Repository) GetTravelQuotaRuleByID(id uint) (*models.TravelQuotaRule, error) {
+	var rule models.TravelQuotaRule
+	err := r.db.First(&rule, id).Error
+	return &rule, err
+}
+
+// GetActiveTravelQuotaRules returns the active quota rules keyed by position level
+func (r *Repository) GetActiveTravelQuotaRules() (map[string]models.TravelQuotaRule, error) {
+	var rules []models.TravelQuotaRule
+	if err := r.db.Where("is_active = ?", true).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	byLevel := make(map[string]models.TravelQuotaRule, len(rules))
+	for _, rule := range rules {
+		if _, exists := byLevel[rule.PositionLevel]; !exists {
+			byLevel[rule.PositionLevel] = rule
+		}
+	}
+	return byLevel, nil
+}
+
+func (r *Repository) CreateTravelQuotaRule(rule *models.TravelQuotaRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *Repository) UpdateTravelQuotaRule(rule *models.TravelQuotaRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *Repository) DeleteTravelQuotaRule(id uint) error {
+	return r.db.Delete(&models.TravelQuotaRule{}, id).Error
+}
+
+// Travel policy rule operations
+func (r *Repository) GetAllTravelPolicyRules() ([]models.TravelPolicyRule, error) {
+	var rules []models.TravelPolicyRule
+	err := r.db.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// GetActiveTravelPolicyRules returns the active rules that apply to a document type
+func (r *Repository) GetActiveTravelPolicyRules(documentType string) ([]models.TravelPolicyRule, error) {
+	var rules []models.TravelPolicyRule
+	err := r.db.Where("is_active = ? AND (document_type = ? OR document_type = '')", true, documentType).
+		Order("id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *Repository) GetTravelPolicyRuleByID(id uint) (*models.TravelPolicyRule, error) {
+	var rule models.TravelPolicyRule
+	err := r.db.First(&rule, id).Error
+	return &rule, err
+}
+
+func (r *Repository) CreateTravelPolicyRule(rule *models.TravelPolicyRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *Repository) UpdateTravelPolicyRule(rule *models.TravelPolicyRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *Repository) DeleteTravelPolicyRule(id uint) error {
+	return r.db.Delete(&models.TravelPolicyRule{}, id).Error
+}
+
+// Destination tier operations
+func (r *Repository) GetAllDestinationTiers() ([]models.DestinationTier, error) {
+	var tiers []models.DestinationTier
+	err := r.db.
+		Preload("Cities", func(db *gorm.DB) *gorm.DB {
+			return db.Order("city_name ASC")
+		}).
+		Order("destination_type ASC, code ASC").
+		Find(&tiers).Error
+	return tiers, err
+}
+
+func (r *Repository) GetDestinationTierByID(id uint) (*models.DestinationTier, error) {
+	var tier models.DestinationTier
+	err := r.db.
+		Preload("Cities", func(db *gorm.DB) *gorm.DB {
+			return db.Order("city_name ASC")
+		}).
+		First(&tier, id).Error
+	return &tier, err
+}
+
+func (r *Repository) CreateDestinationTier(tier *models.DestinationTier) error {
+	return r.db.Create(tier).Error
+}
+
+// ReplaceDestinationTier updates a tier and replaces all of its cities
+func (r *Repository) ReplaceDestinationTier(tier *models.DestinationTier) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tier_id = ?", tier.ID).Delete(&models.DestinationTierCity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Cities").Save(tier).Error; err != nil {
+			return err
+		}
+		for i := range tier.Cities {
+			tier.Cities[i].ID = 0
+			tier.Cities[i].TierID = tier.ID
+		}
+		if len(tier.Cities) == 0 {
+			return nil
+		}
+		return tx.Create(&tier.Cities).Error
+	})
+}
+
+func (r *Repository) DeleteDestinationTier(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tier_id = ?", id).Delete(&models.DestinationTierCity{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.DestinationTier{}, id).Error
+	})
+}
+
+// City master operations
+func (r *Repository) GetAllCities() ([]models.City, error) {
+	var cities []models.City
+	err := r.db.Order("country_code = 'ID' DESC, country_code ASC, province_code ASC, name ASC").Find(&cities).Error
+	return cities, err
+}
+
+func (r *Repository) GetCityByID(id uint) (*models.City, error) {
+	var city models.City
+	err := r.db.First(&city, id).Error
+	return &city, err
+}
+
+// GetCityByName finds a city by its name, ignoring case
+func (r *Repository) GetCityByName(name string) (*models.City, error) {
+	var city models.City
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&city).Error
+	return &city, err
+}
+
+func (r *Repository) CreateCity(city *models.City) error {
+	return r.db.Create(city).Error
+}
+
+func (r *Repository) UpdateCity(city *models.City) error {
+	return r.db.Save(city).Error
+}
+
+func (r *Repository) DeleteCity(id uint) error {
+	return r.db.Delete(&models.City{}, id).Error
+}
+
+// Partial day rule operations
+func (r *Repository) GetAllPartialDayRules() ([]models.PartialDayRule, error) {
+	var rules []models.PartialDayRule
+	err := r.db.Order("destination_type ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *Repository) GetPartialDayRuleByID(id uint) (*models.PartialDayRule, error) {
+	var rule models.PartialDayRule
+	err := r.db.First(&rule, id).Error
+	return &rule, err
+}
+
+// GetActivePartialDayRules returns the active partial day rules keyed by destination type
+func (r *Repository) GetActivePartialDayRules() (map[string]models.PartialDayRule, error) {
+	var rules []models.PartialDayRule
+	if err := r.db.Where("is_active = ?", true).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	byType := make(map[string]models.PartialDayRule, len(rules))
+	for _, rule := range rules {
+		if _, exists := byType[rule.DestinationType]; !exists {
+			byType[rule.DestinationType] = rule
+		}
+	}
+	return byType, nil
+}
+
+func (r *Repository) CreatePartialDayRule(rule *models.PartialDayRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *Repository) UpdatePartialDayRule(rule *models.PartialDayRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *Repository) DeletePartialDayRule(id uint) error {
+	return r.db.Delete(&models.PartialDayRule{}, id).Error
+}
+
+// Holiday calendar operations
+func (r *Repository) GetHolidaysByYear(year int) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+	err := r.db.Where("date BETWEEN ? AND ?", from, to).Order("date ASC").Find(&holidays).Error
+	return holidays, err
+}
+
+func (r *Repository) GetHolidayByID(id uint) (*models.Holiday, error) {
+	var holiday models.Holiday
+	err := r.db.First(&holiday, id).Error
+	return &holiday, err
+}
+
+func (r *Repository) CreateHoliday(holiday *models.Holiday) error {
+	return r.db.Create(holiday).Error
+}
+
+func (r *Repository) UpdateHoliday(holiday *models.Holiday) error {
+	return r.db.Save(holiday).Error
+}
+
+func (r *Repository) DeleteHoliday(id uint) error {
+	return r.db.Delete(&models.Holiday{}, id).Error
+}
+
+// Exchange rate operations
+func (r *Repository) GetExchangeRates(currency string) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	query := r.db.Order("date DESC, currency ASC")
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	err := query.Find(&rates).Error
+	return rates, err
+}
+
+func (r *Repository) GetExchangeRateByID(id uint) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.First(&rate, id).Error
+	return &rate, err
+}
+
+func (r *Repository) CreateExchangeRate(rate *models.ExchangeRate) error {
+	return r.db.Create(rate).Error
+}
+
+func (r *Repository) UpdateExchangeRate(rate *models.ExchangeRate) error {
+	return r.db.Save(rate).Error
+}
+
+func (r *Repository) DeleteExchangeRate(id uint) error {
+	return r.db.Delete(&models.ExchangeRate{}, id).Error
+}
+
+// Travel advance operations
+func (r *Repository) GetTravelAdvanceByTravelRequestID(requestID uint) (*models.TravelAdvance, error) {
+	var advance models.TravelAdvance
+	err := r.db.Preload("Employees", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Employees.Employee.Position").
+		Where("travel_request_id = ?", requestID).
+		First(&advance).Error
+	return &advance, err
+}
+
+// Surat tugas operations
+func (r *Repository) GetSuratTugasByTravelRequestID(requestID uint) (*models.SuratTugas, error) {
+	var letter models.SuratTugas
+	err := r.db.Where("travel_request_id = ?", requestID).First(&letter).Error
+	return &letter, err
+}
+
+func (r *Repository) GetSuratTugasSignerConfig() (*models.SuratTugasSignerConfig, error) {
+	var config models.SuratTugasSignerConfig
+	if err := r.db.First(&config).Error; err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *Repository) UpdateSuratTugasSignerConfig(config *models.SuratTugasSignerConfig) error {
+	return r.db.Save(config).Error
+}
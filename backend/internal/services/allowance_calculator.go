@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+// EmployeeAllowance holds the computed allowance for a single traveller
+type EmployeeAllowance struct {
+	EmployeeID   uint `json:"employee_id"`
+	DailyRate    int  `json:"daily_rate"`
+	DurationDays int  `json:"duration_days"`
+	Subtotal     int  `json:"subtotal"`
+}
+
+type AllowanceCalculator struct{}
+
+func NewAllowanceCalculator() *AllowanceCalculator {
+	return &AllowanceCalculator{}
+}
+
+// DailyRate returns the allowance rate of a position for the given destination type
+func (ac *AllowanceCalculator) DailyRate(position models.Position, destinationType string) (int, error) {
+	switch destinationType {
+	case "in_province":
+		return position.AllowanceInProvince, nil
+	case "outside_province":
+		return position.AllowanceOutsideProvince, nil
+	case "abroad":
+		return position.AllowanceAbroad, nil
+	default:
+		return 0, fmt.Errorf("invalid destination_type: %s", destinationType)
+	}
+}
+
+// RateZone is where a daily rate is taken from: an allowance zone and, for destinations with
+// a tier, the percentage of the zone rate that tier pays
+type RateZone struct {
+	DestinationType   string `json:"destination_type"`
+	Tier              string `json:"tier,omitempty"`
+	MultiplierPercent int    `json:"multiplier_percent"`
+}
+
+// RequestZone returns the rate zone of a single-destination request, with its destination tier
+func RequestZone(request *models.TravelRequest) RateZone {
+	return RateZone{
+		DestinationType:   request.DestinationType,
+		Tier:              request.DestinationTier,
+		MultiplierPercent: request.TierMultiplier,
+	}
+}
+
+// ZoneRate returns the daily rate of a position in a zone, scaled by the tier multiplier.
+// A zero multiplier is the plain zone rate.
+func (ac *AllowanceCalculator) ZoneRate(position models.Position, zone RateZone) (int, error) {
+	rate, err := ac.DailyRate(position, zone.DestinationType)
+	if err != nil {
+		return 0, err
+	}
+	if zone.MultiplierPercent <= 0 {
+		return rate, nil
+	}
+	return rate * zone.MultiplierPercent / 100, nil
+}
+
+// Calculate computes the allowance of every employee from their own position rate.
+// It returns the per-employee breakdown (in the same order as employees) and the total.
+func (ac *AllowanceCalculator) Calculate(employees []models.Employee, destinationType string, durationDays int) ([]EmployeeAllowance, int, error) {
+	return ac.CalculateZone(employees, RateZone{DestinationType: destinationType}, durationDays)
+}
+
+// CalculateZone is Calculate for a destination that may have a tier
+func (ac *AllowanceCalculator) CalculateZone(employees []models.Employee, zone RateZone, durationDays int) ([]EmployeeAllowance, int, error) {
+	return ac.CalculateZoneDays(employees, zone, FullAllowanceDays(durationDays))
+}
+
+// CalculateZoneDays is CalculateZone for a trip whose departure and return day may be paid
+// at a percentage of the daily rate
+func (ac *AllowanceCalculator) CalculateZoneDays(employees []models.Employee, zone RateZone, days AllowanceDays) ([]EmployeeAllowance, int, error) {
+	allowances := make([]EmployeeAllowance, 0, len(employees))
+	total := 0
+
+	for _, employee := range employees {
+		rate, err := ac.ZoneRate(employee.Position, zone)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		subtotal := days.Amount(rate)
+		allowances = append(allowances, EmployeeAllowance{
+			EmployeeID:   employee.ID,
+			DailyRate:    rate,
+			DurationDays: days.CalendarDays,
+			Subtotal:     subtotal,
+		})
+		total += subtotal
+	}
+
+	return allowances, total, nil
+}
+
+// EmployeeAllowanceAmount returns the allowance paid to one traveller of a request.
+// Requests created before per-employee amounts were stored fall back to an even split.
+func EmployeeAllowanceAmount(request *models.TravelRequest, empRel models.TravelRequestEmployee) int {
+	if hasPerEmployeeAllowance(request) {
+		return empRel.Subtotal
+	}
+	if len(request.TravelRequestEmployees) == 0 {
+		return 0
+	}
+	return request.TotalAllowance / len(request.TravelRequestEmployees)
+}
+
+func hasPerEmployeeAllowance(request *models.TravelRequest) bool {
+	for _, empRel := range request.TravelRequestEmployees {
+		if empRel.DailyRate > 0 || empRel.Subtotal > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestCalculateAllowancePerEmployee(t *testing.T) {
+	jrOfficer := models.Employee{ID: 1, Position: models.Position{Level: "Jr. Officer", AllowanceOutsideProvince: 20000}}
+	avp := models.Employee{ID: 2, Position: models.Position{Level: "AVP", AllowanceOutsideProvince: 50000}}
+
+	allowances, total, err := NewAllowanceCalculator().Calculate([]models.Employee{jrOfficer, avp}, "outside_province", 3)
+	if err != nil {
+		t.Fatalf("Calculate returned error: %v", err)
+	}
+
+	if len(allowances) != 2 {
+		t.Fatalf("Expected 2 allowances, got %d", len(allowances))
+	}
+	if allowances[0].Subtotal != 60000 {
+		t.Errorf("Expected Jr. Officer subtotal 60000, got %d", allowances[0].Subtotal)
+	}
+	if allowances[1].Subtotal != 150000 {
+		t.Errorf("Expected AVP subtotal 150000, got %d", allowances[1].Subtotal)
+	}
+	if total != 210000 {
+		t.Errorf("Expected total 210000, got %d", total)
+	}
+}
+
+func TestCalculateAllowanceInvalidDestinationType(t *testing.T) {
+	_, _, err := NewAllowanceCalculator().Calculate([]models.Employee{{ID: 1}}, "moon", 1)
+	if err == nil {
+		t.Error("Expected error for invalid destination type")
+	}
+}
+
+func TestEmployeeAllowanceAmountLegacyFallback(t *testing.T) {
+	request := &models.TravelRequest{
+		TotalAllowance: 90000,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1},
+			{EmployeeID: 2},
+		},
+	}
+
+	if got := EmployeeAllowanceAmount(request, request.TravelRequestEmployees[0]); got != 45000 {
+		t.Errorf("Expected even split 45000 for legacy request, got %d", got)
+	}
+
+	request.TravelRequestEmployees[0].DailyRate = 20000
+	request.TravelRequestEmployees[0].Subtotal = 40000
+	request.TravelRequestEmployees[1].DailyRate = 25000
+	request.TravelRequestEmployees[1].Subtotal = 50000
+
+	if got := EmployeeAllowanceAmount(request, request.TravelRequestEmployees[1]); got != 50000 {
+		t.Errorf("Expected stored subtotal 50000, got %d", got)
+	}
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrApprovalPending        = errors.New("approval chain is still pending")
+	ErrNotApprover            = errors.New("approval step is assigned to another approver")
+	ErrApprovalStepDecided    = errors.New("approval step has already been decided")
+	ErrApprovalStepNotCurrent = errors.New("previous approval steps are still pending")
+	ErrApprovalNoteRequired   = errors.New("note is required when rejecting")
+	ErrClaimNotPending        = errors.New("claim is no longer pending")
+)
+
+// ApprovalInboxItem is a pending approval step together with a summary of its document
+type ApprovalInboxItem struct {
+	Step           models.ApprovalStep `json:"step"`
+	DocumentNumber string              `json:"document_number"`
+	Purpose        string              `json:"purpose"`
+	Destination    string              `json:"destination"`
+	Amount         int                 `json:"amount"`
+	RequesterName  string              `json:"requester_name"`
+}
+
+type ApprovalService struct {
+	repo *repository.Repository
+}
+
+func NewApprovalService(repo *repository.Repository) *ApprovalService {
+	return &ApprovalService{repo: repo}
+}
+
+// MatchApprovalRoute picks the most specific route for the requester position and amount.
+// A route with a position code beats one with only a level, which beats a generic route;
+// among equally specific routes the one with the highest applicable MinAmount wins.
+func MatchApprovalRoute(routes []models.ApprovalRoute, requester models.Position, amount int) *models.ApprovalRoute {
+	var best *models.ApprovalRoute
+	bestScore := -1
+
+	for i := range routes {
+		route := &routes[i]
+		if route.PositionCode != "" && route.PositionCode != requester.Code {
+			continue
+		}
+		if route.PositionLevel != "" && route.PositionLevel != requester.Level {
+			continue
+		}
+		if amount < route.MinAmount {
+			continue
+		}
+
+		score := 0
+		if route.PositionCode != "" {
+			score += 2
+		}
+		if route.PositionLevel != "" {
+			score++
+		}
+
+		if score > bestScore || (score == bestScore && route.MinAmount > best.MinAmount) {
+			best = route
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// StartTx (re)creates the approval chain of a document inside the caller's transaction.
+// Documents without a matching route get no steps and follow the manual workflow.
+func (s *ApprovalService) StartTx(tx *gorm.DB, documentType string, documentID uint, requester models.Position, amount int) ([]models.ApprovalStep, error) {
+	routes, err := s.repo.GetActiveApprovalRoutes(documentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval routes: %w", err)
+	}
+
+	// Drop any previous chain, e.g. when a request is resubmitted
+	if err := tx.Where("document_type = ? AND document_id = ?", documentType, documentID).
+		Delete(&models.ApprovalStep{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to reset approval steps: %w", err)
+	}
+
+	route := MatchApprovalRoute(routes, requester, amount)
+	if route == nil {
+		return nil, nil
+	}
+
+	var steps []models.ApprovalStep
+	for _, routeStep := range route.Steps {
+		if amount < routeStep.MinAmount {
+			continue
+		}
+
+		approverName := routeStep.Approver.Name
+		if approverName == "" {
+			approverName = routeStep.Approver.Username
+		}
+
+		steps = append(steps, models.ApprovalStep{
+			DocumentType:     documentType,
+			DocumentID:       documentID,
+			StepOrder:        len(steps) + 1,
+			ApproverID:       routeStep.ApproverID,
+			ApproverName:     approverName,
+			ApproverPosition: routeStep.Approver.Position,
+			Status:           models.ApprovalStatusPending,
+		})
+	}
+
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	if err := tx.Create(&steps).Error; err != nil {
+		return nil, fmt.Errorf("failed to create approval steps: %w", err)
+	}
+
+	return steps, nil
+}
+
+// HasPendingTx reports whether a document still has undecided approval steps
+func (s *ApprovalService) HasPendingTx(tx *gorm.DB, documentType string, documentID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.ApprovalStep{}).
+		Where("document_type = ? AND document_id = ? AND status = ?", documentType, documentID, models.ApprovalStatusPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SkipPendingTx closes the remaining steps of a chain that ended early (rejected or cancelled)
+func (s *ApprovalService) SkipPendingTx(tx *gorm.DB, documentType string, documentID uint) error {
+	return tx.Model(&models.ApprovalStep{}).
+		Where("document_type = ? AND document_id = ? AND status = ?", documentType, documentID, models.ApprovalStatusPending).
+		Update("status", models.ApprovalStatusSkipped).Error
+}
+
+// Inbox returns the approval steps currently waiting for the given approver.
+// A step is only listed once all earlier steps of its chain are approved.
+func (s *ApprovalService) Inbox(approverID uint) ([]ApprovalInboxItem, error) {
+	steps, err := s.repo.GetPendingApprovalStepsByApprover(approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ApprovalInboxItem, 0, len(steps))
+	for _, step := range steps {
+		current, err := s.isCurrentStep(s.repo.GetDB(), &step)
+		if err != nil {
+			return nil, err
+		}
+		if !current {
+			continue
+		}
+
+		item := ApprovalInboxItem{Step: step}
+		switch step.DocumentType {
+		case models.ApprovalDocTravelRequest:
+			request, err := s.repo.GetTravelRequestByID(step.DocumentID)
+			if err != nil {
+				continue
+			}
+			item.DocumentNumber = request.RequestNumber
+			item.Purpose = request.Purpose
+			item.Destination = request.Destination
+			item.Amount = request.TotalAllowance
+			if len(request.TravelRequestEmployees) > 0 {
+				item.RequesterName = request.TravelRequestEmployees[0].Employee.Name
+			}
+		case models.ApprovalDocAtCostClaim:
+			claim, err := s.repo.GetAtCostClaimByID(step.DocumentID)
+			if err != nil {
+				continue
+			}
+			item.DocumentNumber = claim.ClaimNumber
+			item.Purpose = claim.TravelRequest.Purpose
+			item.Destination = claim.TravelRequest.Destination
+			item.Amount = claim.TotalAmount
+			if len(claim.ClaimItems) > 0 {
+				item.RequesterName = claim.ClaimItems[0].Employee.Name
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Decide approves or rejects an approval step on behalf of its approver.
+// Rejecting ends the chain and rejects the document; approving the last step approves
+// the document and makes the final approver its signatory.
+func (s *ApprovalService) Decide(stepID, approverID uint, actor string, approve bool, note string) (*models.ApprovalStep, error) {
+	if !approve && strings.TrimSpace(note) == "" {
+		return nil, ErrApprovalNoteRequired
+	}
+
+	var step models.ApprovalStep
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&step, stepID).Error; err != nil {
+			return err
+		}
+		if step.Status != models.ApprovalStatusPending {
+			return ErrApprovalStepDecided
+		}
+		if step.ApproverID != approverID {
+			return ErrNotApprover
+		}
+		current, err := s.isCurrentStep(tx, &step)
+		if err != nil {
+			return err
+		}
+		if !current {
+			return ErrApprovalStepNotCurrent
+		}
+
+		now := time.Now()
+		step.Status = models.ApprovalStatusRejected
+		if approve {
+			step.Status = models.ApprovalStatusApproved
+		}
+		step.Note = note
+		step.DecidedAt = &now
+		if err := tx.Save(&step).Error; err != nil {
+			return fmt.Errorf("failed to update approval step: %w", err)
+		}
+
+		if !approve {
+			if err := s.SkipPendingTx(tx, step.DocumentType, step.DocumentID); err != nil {
+				return err
+			}
+			return s.rejectDocumentTx(tx, &step, actor, note)
+		}
+
+		pending, err := s.HasPendingTx(tx, step.DocumentType, step.DocumentID)
+		if err != nil {
+			return err
+		}
+		if pending {
+			return nil
+		}
+		return s.approveDocumentTx(tx, &step, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &step, nil
+}
+
+func (s *ApprovalService) isCurrentStep(tx *gorm.DB, step *models.ApprovalStep) (bool, error) {
+	var earlier int64
+	err := tx.Model(&models.ApprovalStep{}).
+		Where("document_type = ? AND document_id = ? AND step_order < ? AND status <> ?",
+			step.DocumentType, step.DocumentID, step.StepOrder, models.ApprovalStatusApproved).
+		Count(&earlier).Error
+	return earlier == 0, err
+}
+
+func (s *ApprovalService) rejectDocumentTx(tx *gorm.DB, step *models.ApprovalStep, actor, note string) error {
+	switch step.DocumentType {
+	case models.ApprovalDocTravelRequest:
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, step.DocumentID).Error; err != nil {
+			return err
+		}
+		return NewTravelRequestWorkflow(s.repo).TransitionTx(tx, &request, models.TravelStatusRejected, actor, note)
+	case models.ApprovalDocAtCostClaim:
+		return settlePendingClaimTx(tx, step.DocumentID, map[string]interface{}{
+			"status": models.ClaimStatusRejected,
+		})
+	}
+	return nil
+}
+
+func (s *ApprovalService) approveDocumentTx(tx *gorm.DB, step *models.ApprovalStep, actor string) error {
+	switch step.DocumentType {
+	case models.ApprovalDocTravelRequest:
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, step.DocumentID).Error; err != nil {
+			return err
+		}
+		if err := NewTravelRequestWorkflow(s.repo).TransitionTx(tx, &request, models.TravelStatusApproved, actor, "Disetujui melalui alur persetujuan"); err != nil {
+			return err
+		}
+		return tx.Model(&models.TravelReport{}).Where("travel_request_id = ?", request.ID).
+			Updates(map[string]interface{}{
+				"representative_name":     step.ApproverName,
+				"representative_position": step.ApproverPosition,
+			}).Error
+	case models.ApprovalDocAtCostClaim:
+		return settlePendingClaimTx(tx, step.DocumentID, map[string]interface{}{
+			"status":                  models.ClaimStatusApproved,
+			"representative_name":     step.ApproverName,
+			"representative_position": step.ApproverPosition,
+		})
+	}
+	return nil
+}
+
+// settlePendingClaimTx applies the decision of an approval chain to a claim that is still pending.
+// A claim settled in the meantime, e.g. voided by the cancellation of its trip, is left as it is.
+func settlePendingClaimTx(tx *gorm.DB, claimID uint, updates map[string]interface{}) error {
+	result := tx.Model(&models.AtCostClaim{}).
+		Where("id = ? AND status = ?", claimID, models.ClaimStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: claim %d", ErrClaimNotPending, claimID)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestMatchApprovalRoute(t *testing.T) {
+	routes := []models.ApprovalRoute{
+		{ID: 1, Name: "Default"},
+		{ID: 2, Name: "DPEB", PositionCode: "DPEB"},
+		{ID: 3, Name: "DPEB AVP", PositionCode: "DPEB", PositionLevel: "AVP"},
+		{ID: 4, Name: "DPEB large", PositionCode: "DPEB", MinAmount: 5000000},
+	}
+
+	tests := []struct {
+		name      string
+		requester models.Position
+		amount    int
+		wantID    uint
+	}{
+		{"generic unit falls back to default", models.Position{Code: "DPDB", Level: "Officer"}, 100000, 1},
+		{"unit specific route", models.Position{Code: "DPEB", Level: "Officer"}, 100000, 2},
+		{"unit and level beats unit only", models.Position{Code: "DPEB", Level: "AVP"}, 100000, 3},
+		{"amount threshold picks higher route", models.Position{Code: "DPEB", Level: "Officer"}, 6000000, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := MatchApprovalRoute(routes, tt.requester, tt.amount)
+			if route == nil {
+				t.Fatalf("Expected route %d, got nil", tt.wantID)
+			}
+			if route.ID != tt.wantID {
+				t.Errorf("Expected route %d, got %d (%s)", tt.wantID, route.ID, route.Name)
+			}
+		})
+	}
+
+	if route := MatchApprovalRoute(routes[1:2], models.Position{Code: "DDBE"}, 0); route != nil {
+		t.Errorf("Expected no route for unmatched unit, got %d", route.ID)
+	}
+}
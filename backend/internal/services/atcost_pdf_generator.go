@@ -48,6 +48,9 @@ func (g *AtCostPDFGenerator) GenerateNotaAtCost(claimID uint) (string, error) {
 	pdf.Ln(8)
 	g.drawAccommodationSection(pdf, claim)
 
+	// Receipts in a foreign currency with the rate they were converted at
+	g.drawForeignReceiptsSection(pdf, claim)
+
 	// Draw closing and signatures
 	pdf.Ln(8)
 	g.drawClosingAndSignatures(pdf, claim)
@@ -329,6 +332,51 @@ func (g *AtCostPDFGenerator) drawAccommodationSection(pdf *gofpdf.Fpdf, claim *m
 	pdf.CellFormat(29, 8, formatCurrency(claim.TotalAmount), "1", 1, "R", true, 0, "")
 }
 
+// drawForeignReceiptsSection lists the receipts paid in a foreign currency with their original
+// amount, the middle rate of their receipt date and the rupiah amount in the totals above
+func (g *AtCostPDFGenerator) drawForeignReceiptsSection(pdf *gofpdf.Fpdf, claim *models.AtCostClaim) {
+	var receipts []models.AtCostReceipt
+	for _, item := range claim.ClaimItems {
+		for _, receipt := range item.Receipts {
+			if IsForeignCurrency(receipt.Currency) {
+				receipts = append(receipts, receipt)
+			}
+		}
+	}
+	if len(receipts) == 0 {
+		return
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(0, 5, "Bukti dalam valuta asing dikonversi dengan kurs tengah pada tanggal bukti :")
+	pdf.Ln(6)
+
+	// Column widths: No(8), Bukti(60), Tanggal(22), Nominal(32), Kurs(28), Rupiah(30) = 180mm
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(8, 7, "No.", "1", 0, "CM", true, 0, "")
+	pdf.CellFormat(60, 7, "Bukti", "1", 0, "CM", true, 0, "")
+	pdf.CellFormat(22, 7, "Tanggal", "1", 0, "CM", true, 0, "")
+	pdf.CellFormat(32, 7, "Nominal", "1", 0, "CM", true, 0, "")
+	pdf.CellFormat(28, 7, "Kurs", "1", 0, "CM", true, 0, "")
+	pdf.CellFormat(30, 7, "Rupiah", "1", 1, "CM", true, 0, "")
+
+	pdf.SetFont("Arial", "", 8)
+	for i, receipt := range receipts {
+		label := strings.TrimSpace(fmt.Sprintf("%s %s", receipt.Vendor, receipt.ReceiptNumber))
+		if label == "" {
+			label = receipt.FileName
+		}
+		pdf.CellFormat(8, 6, fmt.Sprintf("%d.", i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(60, 6, fitCellText(pdf, label, 60), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(22, 6, receipt.ReceiptDate.Format("02/01/2006"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(32, 6, FormatForeignAmount(receipt.Currency, receipt.OriginalAmount), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(28, 6, FormatExchangeRate(receipt.ExchangeRate), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 6, formatCurrency(receipt.Amount), "1", 1, "R", false, 0, "")
+	}
+}
+
 func (g *AtCostPDFGenerator) drawClosingAndSignatures(pdf *gofpdf.Fpdf, claim *models.AtCostClaim) {
 	// Closing paragraph with justify
 	pdf.SetFont("Arial", "", 10)
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -13,12 +14,17 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AtCostService struct {
 	repo          *repository.Repository
 	parser        *ReceiptParser
 	pdfExtractor  *PDFExtractor
+	approvals     *ApprovalService
+	numbering     *NumberingService
+	policy        *TravelPolicyService
+	exchangeRates *ExchangeRateService
 	uploadDir     string
 }
 
@@ -31,14 +37,22 @@ func NewAtCostService(repo *repository.Repository) *AtCostService {
 		repo:         repo,
 		parser:       NewReceiptParser(),
 		pdfExtractor: NewPDFExtractor(),
+		approvals:    NewApprovalService(repo),
+		numbering:    NewNumberingService(repo),
+		policy:       NewTravelPolicyService(repo),
+		exchangeRates: NewExchangeRateService(repo),
 		uploadDir:    uploadDir,
 	}
 }
 
-// CreateAtCostClaimRequest represents the request to create a claim
+// CreateAtCostClaimRequest represents the request to create a claim.
+// The transport and accommodation cost of an item are its full rupiah totals, receipts in a
+// foreign currency included; those receipts are converted to rupiah for the record but never
+// added to the costs again.
 type CreateAtCostClaimRequest struct {
-	TravelRequestID uint                       `json:"travel_request_id"`
-	ClaimItems      []CreateAtCostClaimItemReq `json:"claim_items"`
+	TravelRequestID     uint                       `json:"travel_request_id"`
+	ClaimItems          []CreateAtCostClaimItemReq `json:"claim_items"`
+	PolicyJustification string                     `json:"policy_justification"` // Wajib jika aturan kebijakan meminta justifikasi
 }
 
 type CreateAtCostClaimItemReq struct {
@@ -49,17 +63,20 @@ type CreateAtCostClaimItemReq struct {
 }
 
 type CreateAtCostReceiptReq struct {
-	Type            string `json:"type"` // flight, hotel, train
-	ReceiptNumber   string `json:"receipt_number"`
-	ReceiptDate     string `json:"receipt_date"` // YYYY-MM-DD
-	Vendor          string `json:"vendor"`
-	Description     string `json:"description"`
-	Amount          int    `json:"amount"`
-	PassengerName   string `json:"passenger_name"`
-	RouteOrLocation string `json:"route_or_location"`
-	FilePath        string `json:"file_path"` // Set after upload
-	FileName        string `json:"file_name"`
-	ParsedData      string `json:"parsed_data,omitempty"`
+	Type            string  `json:"type"` // flight, hotel, train
+	ReceiptNumber   string  `json:"receipt_number"`
+	ReceiptDate     string  `json:"receipt_date"` // YYYY-MM-DD
+	Vendor          string  `json:"vendor"`
+	Description     string  `json:"description"`
+	Amount          int     `json:"amount"`          // Rupiah, dihitung dari original_amount untuk valuta asing
+	Currency        string  `json:"currency"`        // ISO 4217, kosong = IDR
+	OriginalAmount  float64 `json:"original_amount"` // Nominal dalam valuta asing
+	ExchangeRate    float64 `json:"-"`               // Kurs tengah pada tanggal receipt, diisi saat konversi
+	PassengerName   string  `json:"passenger_name"`
+	RouteOrLocation string  `json:"route_or_location"`
+	FilePath        string  `json:"file_path"` // Set after upload
+	FileName        string  `json:"file_name"`
+	ParsedData      string  `json:"parsed_data,omitempty"`
 }
 
 // ProcessReceiptUpload handles file upload and parsing
@@ -110,6 +127,12 @@ func (s *AtCostService) ProcessReceiptUpload(file *multipart.FileHeader) (*Recei
 	return receiptData, filepath, nil
 }
 
+var (
+	ErrTravelRequestCancelled = errors.New("travel request has been cancelled")
+	ErrClaimNotRefundable     = errors.New("claim is not awaiting a refund")
+	ErrClaimStatusLocked      = errors.New("claim of a cancelled trip can only be refunded")
+)
+
 // CreateAtCostClaim creates a new At-Cost claim with all items and receipts
 func (s *AtCostService) CreateAtCostClaim(req *CreateAtCostClaimRequest) (*models.AtCostClaim, error) {
 	// Get travel request
@@ -117,6 +140,27 @@ func (s *AtCostService) CreateAtCostClaim(req *CreateAtCostClaimRequest) (*model
 	if err != nil {
 		return nil, fmt.Errorf("travel request not found: %w", err)
 	}
+	if travelRequest.Status == models.TravelStatusCancelled {
+		return nil, ErrTravelRequestCancelled
+	}
+
+	// Receipts in a foreign currency are converted to rupiah at their receipt date
+	if err := s.exchangeRates.ConvertClaimReceipts(req); err != nil {
+		return nil, err
+	}
+
+	// Check the claimed travellers and receipts against the travel policy
+	evaluation, err := s.policy.Evaluate(models.ApprovalDocAtCostClaim, AtCostClaimPolicySubject(travelRequest, req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate travel policy: %w", err)
+	}
+	if err := evaluation.Check(req.PolicyJustification); err != nil {
+		return nil, err
+	}
+	policyJustification := ""
+	if len(evaluation.RequiredJustifications) > 0 {
+		policyJustification = req.PolicyJustification
+	}
 
 	// Get representative config
 	repConfig, err := s.repo.GetActiveRepresentativeConfig()
@@ -131,15 +175,6 @@ func (s *AtCostService) CreateAtCostClaim(req *CreateAtCostClaimRequest) (*model
 		return nil, fmt.Errorf("failed to extract position code from request number")
 	}
 
-	// Get next sequence for at-cost (different from travel request sequence)
-	seq, err := s.repo.GetNextRequestSequence()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get next sequence: %w", err)
-	}
-
-	// Generate new claim number with format: 064/{seq}/DIB/{code}/Nota
-	claimNumber := utils.GenerateRequestNumber(seq, positionCode)
-
 	// Calculate total amount
 	var totalAmount int
 	for _, item := range req.ClaimItems {
@@ -149,14 +184,25 @@ func (s *AtCostService) CreateAtCostClaim(req *CreateAtCostClaimRequest) (*model
 	// Begin transaction
 	var claim *models.AtCostClaim
 	err = s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Allocate claim number inside the claim's transaction so a failed insert
+		// gives the number back: 064/{seq}/DIB/{code}/NOTA
+		allocation, err := s.numbering.AllocateTx(tx, models.DocTypeAtCostClaim, positionCode, time.Now())
+		if err != nil {
+			return err
+		}
+		claimNumber := allocation.Number
+
 		// Create claim
 		claim = &models.AtCostClaim{
 			TravelRequestID:        req.TravelRequestID,
 			ClaimNumber:            claimNumber,
+			NumberYear:             allocation.Year,
+			NumberSequence:         allocation.Sequence,
 			RepresentativeName:     repConfig.Name,
 			RepresentativePosition: repConfig.Position,
 			Status:                 "pending",
 			TotalAmount:            totalAmount,
+			PolicyJustification:    policyJustification,
 		}
 
 		if err := tx.Create(claim).Error; err != nil {
@@ -191,6 +237,9 @@ func (s *AtCostService) CreateAtCostClaim(req *CreateAtCostClaimRequest) (*model
 					Type:            receiptReq.Type,
 					Description:     receiptReq.Description,
 					Amount:          receiptReq.Amount,
+					Currency:        receiptReq.Currency,
+					OriginalAmount:  receiptReq.OriginalAmount,
+					ExchangeRate:    receiptReq.ExchangeRate,
 					FilePath:        receiptReq.FilePath,
 					FileName:        receiptReq.FileName,
 					PassengerName:   receiptReq.PassengerName,
@@ -204,6 +253,14 @@ func (s *AtCostService) CreateAtCostClaim(req *CreateAtCostClaimRequest) (*model
 			}
 		}
 
+		// Start approval chain based on the requester's position and claim amount
+		if len(travelRequest.TravelRequestEmployees) > 0 {
+			requester := travelRequest.TravelRequestEmployees[0].Employee.Position
+			if _, err := s.approvals.StartTx(tx, models.ApprovalDocAtCostClaim, claim.ID, requester, totalAmount); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 
@@ -230,15 +287,52 @@ func (s *AtCostService) GetAllAtCostClaims() ([]models.AtCostClaim, error) {
 	return s.repo.GetAllAtCostClaims()
 }
 
-// UpdateClaimStatus updates the status of a claim
-func (s *AtCostService) UpdateClaimStatus(id uint, status string) error {
-	claim, err := s.repo.GetAtCostClaimByID(id)
-	if err != nil {
-		return err
+// CheckClaimStatusChange refuses a status change the cancellation of a trip does not allow.
+// A void claim stays void and a claim awaiting a refund can only be refunded, which is final.
+func CheckClaimStatusChange(from, to string) error {
+	switch {
+	case to == models.ClaimStatusRefunded && from != models.ClaimStatusRefundPending:
+		return ErrClaimNotRefundable
+	case from == models.ClaimStatusVoid, from == models.ClaimStatusRefunded,
+		from == models.ClaimStatusRefundPending && to != models.ClaimStatusRefunded:
+		return fmt.Errorf("%w: claim is %s", ErrClaimStatusLocked, from)
 	}
+	return nil
+}
 
-	claim.Status = status
-	return s.repo.UpdateAtCostClaim(claim)
+// UpdateClaimStatus updates the status of a claim.
+// Claims with an approval chain can only be approved by their last approver.
+// A refund can only be confirmed for claims of a cancelled trip that await it.
+func (s *AtCostService) UpdateClaimStatus(id uint, status string) error {
+	return s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var claim models.AtCostClaim
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&claim, id).Error; err != nil {
+			return err
+		}
+		if err := CheckClaimStatusChange(claim.Status, status); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": status}
+		if status == models.ClaimStatusRefunded {
+			updates["refunded_at"] = time.Now()
+		}
+
+		pending, err := s.approvals.HasPendingTx(tx, models.ApprovalDocAtCostClaim, claim.ID)
+		if err != nil {
+			return err
+		}
+		if pending && status == models.ClaimStatusApproved {
+			return ErrApprovalPending
+		}
+		if status == models.ClaimStatusRejected {
+			if err := s.approvals.SkipPendingTx(tx, models.ApprovalDocAtCostClaim, claim.ID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&claim).Updates(updates).Error
+	})
 }
 
 // DeleteAtCostClaim deletes a claim and all associated data
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCity             = errors.New("invalid city")
+	ErrDestinationTypeRequired = errors.New("destination_type is required when a place is not in the city master")
+)
+
+// domesticCountryCode is the country of the agency; trips to another country are abroad
+const domesticCountryCode = utils.DomesticCountryCode
+
+// FindCity returns the city named name, ignoring case, or nil. A name also matches the part of
+// a city name before a comma or bracket, so "Tokyo" finds "Tokyo, Jepang" and "Solo" finds
+// "Solo (Surakarta)". Exact names win over such short names.
+func FindCity(cities []models.City, name string) *models.City {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		return nil
+	}
+	for i := range cities {
+		if strings.ToLower(strings.TrimSpace(cities[i].Name)) == key {
+			return &cities[i]
+		}
+	}
+	for i := range cities {
+		short := strings.ToLower(cities[i].Name)
+		if cut := strings.IndexAny(short, ",("); cut > 0 {
+			short = strings.TrimSpace(short[:cut])
+		}
+		if short == key {
+			return &cities[i]
+		}
+	}
+	return nil
+}
+
+// ClassifyDestination returns the destination type of a trip from origin to destination:
+// abroad when the countries differ, in_province when both lie in the same province and
+// outside_province otherwise.
+func ClassifyDestination(origin, destination *models.City) string {
+	if !strings.EqualFold(origin.CountryCode, destination.CountryCode) {
+		return "abroad"
+	}
+	if origin.ProvinceCode != "" && origin.ProvinceCode == destination.ProvinceCode {
+		return "in_province"
+	}
+	return "outside_province"
+}
+
+// deriveDestinationType classifies a trip when both places are in the city master and otherwise
+// falls back to the destination type given by the client
+func deriveDestinationType(cities []models.City, departure, destination, fallback string) (string, error) {
+	origin := FindCity(cities, departure)
+	target := FindCity(cities, destination)
+	if origin != nil && target != nil {
+		return ClassifyDestination(origin, target), nil
+	}
+	if fallback == "" {
+		return "", fmt.Errorf("%w: %s - %s", ErrDestinationTypeRequired, departure, destination)
+	}
+	return fallback, nil
+}
+
+// NormalizeCity trims the fields of a city, fills its defaults and checks that a domestic city
+// has a province. A city without a time zone gets the zone of its province or country.
+func NormalizeCity(city *models.City) error {
+	city.Name = strings.TrimSpace(city.Name)
+	city.Kind = strings.ToLower(strings.TrimSpace(city.Kind))
+	city.ProvinceCode = strings.TrimSpace(city.ProvinceCode)
+	city.ProvinceName = strings.TrimSpace(city.ProvinceName)
+	city.CountryCode = strings.ToUpper(strings.TrimSpace(city.CountryCode))
+	city.CountryName = strings.TrimSpace(city.CountryName)
+	city.TimeZone = strings.TrimSpace(city.TimeZone)
+
+	if city.Kind == "" {
+		city.Kind = models.CityKindCity
+	}
+	if city.TimeZone == "" {
+		city.TimeZone = utils.DefaultPlaceTimeZone(city.ProvinceCode, city.CountryCode)
+	}
+	switch {
+	case city.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidCity)
+	case city.Kind != models.CityKindCity && city.Kind != models.CityKindRegency && city.Kind != models.CityKindCountry:
+		return fmt.Errorf("%w: kind must be city, regency or country", ErrInvalidCity)
+	case len(city.CountryCode) != 2:
+		return fmt.Errorf("%w: country_code must be a two-letter ISO code", ErrInvalidCity)
+	case city.CountryCode == domesticCountryCode && city.ProvinceCode == "":
+		return fmt.Errorf("%w: province_code is required for %s", ErrInvalidCity, city.Name)
+	case ValidateTimeZone(city.TimeZone) != nil:
+		return fmt.Errorf("%w: unknown time_zone %q", ErrInvalidCity, city.TimeZone)
+	}
+	return nil
+}
+
+// ParseCityCSV reads cities from a CSV file with a header row. The columns are matched by name:
+// name, kind, province_code, province_name, country_code, country_name and optionally time_zone.
+func ParseCityCSV(r io.Reader) ([]models.City, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidCity, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "country_code"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidCity, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var cities []models.City
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidCity, row, err)
+		}
+		city := models.City{
+			Name:         field(record, "name"),
+			Kind:         field(record, "kind"),
+			ProvinceCode: field(record, "province_code"),
+			ProvinceName: field(record, "province_name"),
+			CountryCode:  field(record, "country_code"),
+			CountryName:  field(record, "country_name"),
+			TimeZone:     field(record, "time_zone"),
+		}
+		if err := NormalizeCity(&city); err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		cities = append(cities, city)
+	}
+	return cities, nil
+}
+
+// CityImportResult counts the cities added and changed by an import
+type CityImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// CityService classifies destinations against the city master
+type CityService struct {
+	repo *repository.Repository
+}
+
+func NewCityService(repo *repository.Repository) *CityService {
+	return &CityService{repo: repo}
+}
+
+// DestinationType derives the destination type of a trip from its departure place and
+// destination. When either place is not in the master the client's fallback is used.
+func (s *CityService) DestinationType(departure, destination, fallback string) (string, error) {
+	cities, err := s.repo.GetAllCities()
+	if err != nil {
+		return "", fmt.Errorf("failed to load cities: %w", err)
+	}
+	return deriveDestinationType(cities, departure, destination, fallback)
+}
+
+// ApplyLegDestinationTypes derives the destination type of every leg by comparing its ToPlace
+// with the place the itinerary starts from
+func (s *CityService) ApplyLegDestinationTypes(legs []models.TravelLeg) error {
+	if len(legs) == 0 {
+		return nil
+	}
+	cities, err := s.repo.GetAllCities()
+	if err != nil {
+		return fmt.Errorf("failed to load cities: %w", err)
+	}
+	for i := range legs {
+		destinationType, err := deriveDestinationType(cities, legs[0].FromPlace, legs[i].ToPlace, legs[i].DestinationType)
+		if err != nil {
+			return fmt.Errorf("leg %d: %w", i+1, err)
+		}
+		legs[i].DestinationType = destinationType
+	}
+	return nil
+}
+
+// Import adds the cities to the master, updating cities that already exist by name
+func (s *CityService) Import(cities []models.City) (*CityImportResult, error) {
+	result := &CityImportResult{}
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, city := range cities {
+			var existing models.City
+			err := tx.Where("LOWER(name) = LOWER(?)", city.Name).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&city).Error; err != nil {
+					return fmt.Errorf("failed to create city %s: %w", city.Name, err)
+				}
+				result.Created++
+			case err != nil:
+				return fmt.Errorf("failed to look up city %s: %w", city.Name, err)
+			default:
+				city.ID = existing.ID
+				city.CreatedAt = existing.CreatedAt
+				if err := tx.Save(&city).Error; err != nil {
+					return fmt.Errorf("failed to update city %s: %w", city.Name, err)
+				}
+				result.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
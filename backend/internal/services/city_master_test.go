@@ -0,0 +1,66 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestClassifyDestination(t *testing.T) {
+	cities := []models.City{
+		{Name: "Surabaya", ProvinceCode: "35", CountryCode: "ID"},
+		{Name: "Malang", ProvinceCode: "35", CountryCode: "ID"},
+		{Name: "Jakarta", ProvinceCode: "31", CountryCode: "ID"},
+		{Name: "Bogor", ProvinceCode: "32", CountryCode: "ID"},
+		{Name: "Tokyo, Jepang", CountryCode: "JP"},
+	}
+
+	tests := []struct {
+		departure, destination, expected string
+	}{
+		{"Surabaya", "Malang", "in_province"},
+		{"Surabaya", "Jakarta", "outside_province"},
+		{"Jakarta", "Malang", "outside_province"},
+		{"jakarta", "Bogor", "outside_province"},
+		{"Jakarta", "Tokyo", "abroad"},
+	}
+	for _, tt := range tests {
+		got, err := deriveDestinationType(cities, tt.departure, tt.destination, "")
+		if err != nil || got != tt.expected {
+			t.Errorf("%s - %s: expected %s, got %q (%v)", tt.departure, tt.destination, tt.expected, got, err)
+		}
+	}
+
+	if got, _ := deriveDestinationType(cities, "Surabaya", "Desa Terpencil", "outside_province"); got != "outside_province" {
+		t.Errorf("Expected the client value for an unknown place, got %q", got)
+	}
+	if _, err := deriveDestinationType(cities, "Surabaya", "Desa Terpencil", ""); !errors.Is(err, ErrDestinationTypeRequired) {
+		t.Errorf("Expected ErrDestinationTypeRequired, got %v", err)
+	}
+}
+
+func TestParseCityCSV(t *testing.T) {
+	input := "name,kind,province_code,province_name,country_code\n" +
+		"Sidoarjo,regency,35,Jawa Timur,id\n" +
+		"Singapura,,,,SG\n"
+	cities, err := ParseCityCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cities) != 2 {
+		t.Fatalf("Expected 2 cities, got %d", len(cities))
+	}
+	if cities[0].Kind != models.CityKindRegency || cities[0].CountryCode != "ID" {
+		t.Errorf("Expected a regency in ID, got %+v", cities[0])
+	}
+	if cities[1].Kind != models.CityKindCity {
+		t.Errorf("Expected kind to default to city, got %q", cities[1].Kind)
+	}
+
+	_, err = ParseCityCSV(strings.NewReader("name,country_code\nBandung,ID\n"))
+	if !errors.Is(err, ErrInvalidCity) || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("Expected a domestic city without province to be rejected at row 2, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+)
+
+// ResolveTier returns the tier of a destination within a zone: the tier the city is mapped to,
+// or for tiers abroad the tier of the destination country. The destination is looked up in the
+// city master, so "Tokyo, Jepang" finds a tier of the city Tokyo or of the country Jepang (JP).
+// Tiers of another zone are ignored, so a destination without a tier in its zone is paid the
+// plain zone rate.
+func ResolveTier(tiers []models.DestinationTier, cities []models.City, destination, destinationType string) *models.DestinationTier {
+	name := strings.ToLower(strings.TrimSpace(destination))
+	if name == "" {
+		return nil
+	}
+	city := FindCity(cities, destination)
+
+	var countryTier *models.DestinationTier
+	for i := range tiers {
+		tier := &tiers[i]
+		if tier.DestinationType != destinationType {
+			continue
+		}
+		for _, tierCity := range tier.Cities {
+			if strings.ToLower(strings.TrimSpace(tierCity.CityName)) == name {
+				return tier
+			}
+			if city != nil && FindCity([]models.City{*city}, tierCity.CityName) != nil {
+				return tier
+			}
+		}
+		if countryTier == nil && tierInCountry(tier, name, city) {
+			countryTier = tier
+		}
+	}
+	return countryTier
+}
+
+// tierInCountry reports whether the country of tier is the destination itself or the country
+// of its city
+func tierInCountry(tier *models.DestinationTier, name string, city *models.City) bool {
+	country := strings.ToLower(strings.TrimSpace(tier.Country))
+	if country == "" {
+		return false
+	}
+	if country == name {
+		return true
+	}
+	return city != nil && (strings.EqualFold(country, city.CountryName) || strings.EqualFold(country, city.CountryCode))
+}
+
+// ZoneLabel returns the Indonesian name of a zone with its tier, e.g. "Luar Provinsi (A)"
+func ZoneLabel(destinationType, tier string) string {
+	if tier == "" {
+		return DestinationTypeLabel(destinationType)
+	}
+	return fmt.Sprintf("%s (%s)", DestinationTypeLabel(destinationType), tier)
+}
+
+// TierZone returns the rate zone of a destination type paid at the multiplier of tier
+func TierZone(tier *models.DestinationTier, destinationType string) RateZone {
+	zone := RateZone{DestinationType: destinationType, MultiplierPercent: 100}
+	if tier != nil {
+		zone.Tier = tier.Code
+		zone.MultiplierPercent = tier.MultiplierPercent
+	}
+	return zone
+}
+
+// DestinationTierService resolves the destination tiers of travel requests
+type DestinationTierService struct {
+	repo *repository.Repository
+}
+
+func NewDestinationTierService(repo *repository.Repository) *DestinationTierService {
+	return &DestinationTierService{repo: repo}
+}
+
+// Zone returns the rate zone of a single destination
+func (s *DestinationTierService) Zone(destination, destinationType string) (RateZone, error) {
+	tiers, cities, err := s.load()
+	if err != nil {
+		return RateZone{}, err
+	}
+	return TierZone(ResolveTier(tiers, cities, destination, destinationType), destinationType), nil
+}
+
+// ApplyLegTiers sets the destination tier and multiplier of every leg from its ToPlace
+func (s *DestinationTierService) ApplyLegTiers(legs []models.TravelLeg) error {
+	tiers, cities, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i := range legs {
+		zone := TierZone(ResolveTier(tiers, cities, legs[i].ToPlace, legs[i].DestinationType), legs[i].DestinationType)
+		legs[i].DestinationTier = zone.Tier
+		legs[i].TierMultiplier = zone.MultiplierPercent
+	}
+	return nil
+}
+
+// load returns the destination tiers with the city master the destinations are resolved in
+func (s *DestinationTierService) load() ([]models.DestinationTier, []models.City, error) {
+	tiers, err := s.repo.GetAllDestinationTiers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load destination tiers: %w", err)
+	}
+	cities, err := s.repo.GetAllCities()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load cities: %w", err)
+	}
+	return tiers, cities, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestResolveTier(t *testing.T) {
+	tiers := []models.DestinationTier{
+		{Code: "A", DestinationType: "outside_province", MultiplierPercent: 150,
+			Cities: []models.DestinationTierCity{{CityName: "Jakarta"}, {CityName: "Denpasar"}}},
+		{Code: "JP", DestinationType: "abroad", Country: "Jepang", MultiplierPercent: 200,
+			Cities: []models.DestinationTierCity{{CityName: "Tokyo"}}},
+	}
+
+	if tier := ResolveTier(tiers, nil, " jakarta ", "outside_province"); tier == nil || tier.Code != "A" {
+		t.Errorf("Expected Jakarta in tier A, got %+v", tier)
+	}
+	if tier := ResolveTier(tiers, nil, "Jepang", "abroad"); tier == nil || tier.Code != "JP" {
+		t.Errorf("Expected the country to resolve its tier, got %+v", tier)
+	}
+	if tier := ResolveTier(tiers, nil, "Jakarta", "in_province"); tier != nil {
+		t.Errorf("Expected tiers of another zone to be ignored, got %+v", tier)
+	}
+
+	cities := []models.City{
+		{Name: "Tokyo, Jepang", CountryCode: "JP", CountryName: "Jepang"},
+		{Name: "Osaka, Jepang", CountryCode: "JP", CountryName: "Jepang"},
+	}
+	if tier := ResolveTier(tiers, cities, "Tokyo, Jepang", "abroad"); tier == nil || tier.Code != "JP" {
+		t.Errorf("Expected Tokyo, Jepang to find the tier of Tokyo, got %+v", tier)
+	}
+	countryOnly := []models.DestinationTier{{Code: "JP", DestinationType: "abroad", Country: "JP", MultiplierPercent: 200}}
+	if tier := ResolveTier(countryOnly, cities, "Osaka, Jepang", "abroad"); tier == nil || tier.Code != "JP" {
+		t.Errorf("Expected Osaka, Jepang to find the tier of its country, got %+v", tier)
+	}
+	if tier := ResolveTier(countryOnly, nil, "Osaka, Jepang", "abroad"); tier != nil {
+		t.Errorf("Expected no tier for a destination outside the city master, got %+v", tier)
+	}
+
+	employee := models.Employee{Position: models.Position{AllowanceOutsideProvince: 200000}}
+	zone := TierZone(ResolveTier(tiers, nil, "Denpasar", "outside_province"), "outside_province")
+	_, total, err := NewAllowanceCalculator().CalculateZone([]models.Employee{employee}, zone, 2)
+	if err != nil {
+		t.Fatalf("CalculateZone failed: %v", err)
+	}
+	if total != 600000 {
+		t.Errorf("Expected 2 x 150%% of 200000 = 600000, got %d", total)
+	}
+
+	// Without a tier the three-zone rate applies unchanged
+	zone = TierZone(ResolveTier(tiers, nil, "Malang", "outside_province"), "outside_province")
+	if _, total, _ = NewAllowanceCalculator().CalculateZone([]models.Employee{employee}, zone, 2); total != 400000 {
+		t.Errorf("Expected the plain zone rate 400000, got %d", total)
+	}
+}
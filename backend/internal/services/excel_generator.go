@@ -2,8 +2,11 @@ package services
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/utils"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -19,13 +22,19 @@ type EmployeeAllowanceRow struct {
 	DaysOutsideProvince  int
 	DaysAbroad           int
 	TotalAllowance       float64
+	WeekendDays          int     // Hari Sabtu/Minggu selama perjalanan
+	HolidayDays          int     // Hari libur nasional pada hari kerja
+	AmendmentDelta       float64 // Selisih iuran karena perpanjangan / kembali lebih awal
+	ForeignAllowance     map[string]float64 // Iuran luar negeri dalam valuta asing per mata uang
 }
 
 func NewExcelGenerator() *ExcelGenerator {
 	return &ExcelGenerator{}
 }
 
-func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.TravelRequest, year int, month int) ([]byte, error) {
+// GenerateMonthlyAllowanceReport summarises the allowance of the trips departing in a month per
+// employee, with the weekend days and holidays of those trips counted from calendar
+func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.TravelRequest, calendar HolidayCalendar, year int, month int) ([]byte, error) {
 	f := excelize.NewFile()
 	defer f.Close()
 
@@ -44,8 +53,8 @@ func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.Trave
 	f.SetColWidth(sheetName, "B", "B", 30)  // NAMA
 	f.SetColWidth(sheetName, "C", "C", 35)  // JABATAN
 	f.SetColWidth(sheetName, "D", "D", 12)  // JUMLAH TRIP
-	f.SetColWidth(sheetName, "E", "G", 18)  // Days columns
-	f.SetColWidth(sheetName, "H", "H", 20)  // TOTAL IURAN
+	f.SetColWidth(sheetName, "E", "I", 18)  // Days columns, akhir pekan dan hari libur
+	f.SetColWidth(sheetName, "J", "L", 20)  // SELISIH ADENDUM, IURAN VALUTA ASING, TOTAL IURAN
 
 	// Create header style
 	headerStyle, _ := f.NewStyle(&excelize.Style{
@@ -83,14 +92,24 @@ func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.Trave
 	hasInProvince := false
 	hasOutsideProvince := false
 	hasAbroad := false
+	hasAmendment := false
+	hasForeign := false
 
 	for _, request := range requests {
+		// Cancelled trips pay no allowance
+		if request.Status == models.TravelStatusCancelled {
+			continue
+		}
+
 		// Filter by month and year
 		departureDate := request.DepartureDate
 		if departureDate.Year() != year || int(departureDate.Month()) != month {
 			continue
 		}
 
+		amendmentDeltas := AmendmentDeltaByEmployee(&request)
+		dayCounts := calendar.CountDays(request.DepartureDate, request.ReturnDate)
+
 		for _, empRel := range request.TravelRequestEmployees {
 			emp := empRel.Employee
 			if _, exists := employeeMap[emp.ID]; !exists {
@@ -103,19 +122,38 @@ func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.Trave
 
 			row := employeeMap[emp.ID]
 			row.TotalTrips++
-			row.TotalAllowance += float64(request.TotalAllowance) / float64(len(request.TravelRequestEmployees))
-
-			// Count days by destination type
-			switch request.DestinationType {
-			case "in_province":
-				row.DaysInProvince += request.DurationDays
-				hasInProvince = true
-			case "outside_province":
-				row.DaysOutsideProvince += request.DurationDays
-				hasOutsideProvince = true
-			case "abroad":
-				row.DaysAbroad += request.DurationDays
-				hasAbroad = true
+			row.WeekendDays += dayCounts.WeekendDays
+			row.HolidayDays += dayCounts.HolidayDays
+			row.TotalAllowance += float64(EmployeeAllowanceAmount(&request, empRel))
+
+			// Abroad allowance priced in a foreign currency, shown next to the rupiah total
+			if foreign := ForeignAllowanceAmount(&request, empRel); foreign > 0 {
+				if row.ForeignAllowance == nil {
+					row.ForeignAllowance = make(map[string]float64)
+				}
+				row.ForeignAllowance[request.AllowanceCurrency] += foreign
+				hasForeign = true
+			}
+
+			// Total iuran already includes amendments, the delta is shown separately
+			if delta := amendmentDeltas[emp.ID]; delta != 0 {
+				row.AmendmentDelta += float64(delta)
+				hasAmendment = true
+			}
+
+			// Count days by destination type, per leg for multi-leg trips
+			for destinationType, days := range TravelDaysByDestinationType(&request) {
+				switch destinationType {
+				case "in_province":
+					row.DaysInProvince += days
+					hasInProvince = true
+				case "outside_province":
+					row.DaysOutsideProvince += days
+					hasOutsideProvince = true
+				case "abroad":
+					row.DaysAbroad += days
+					hasAbroad = true
+				}
 			}
 		}
 	}
@@ -141,6 +179,26 @@ func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.Trave
 		col++
 	}
 
+	// Weekend days and holidays are always shown
+	headers = append(headers, "HARI AKHIR PEKAN", "HARI LIBUR")
+	weekendCol := col
+	holidayCol := col + 1
+	col += 2
+
+	var amendmentCol rune
+	if hasAmendment {
+		headers = append(headers, "SELISIH ADENDUM")
+		amendmentCol = col
+		col++
+	}
+
+	var foreignCol rune
+	if hasForeign {
+		headers = append(headers, "IURAN VALUTA ASING")
+		foreignCol = col
+		col++
+	}
+
 	headers = append(headers, "TOTAL IURAN")
 	totalCol := col
 
@@ -177,6 +235,24 @@ func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.Trave
 			f.SetCellStyle(sheetName, cell, cell, centerStyle)
 		}
 
+		weekendCell := fmt.Sprintf("%c%d", weekendCol, row)
+		f.SetCellValue(sheetName, weekendCell, empRow.WeekendDays)
+		f.SetCellStyle(sheetName, weekendCell, weekendCell, centerStyle)
+		holidayCell := fmt.Sprintf("%c%d", holidayCol, row)
+		f.SetCellValue(sheetName, holidayCell, empRow.HolidayDays)
+		f.SetCellStyle(sheetName, holidayCell, holidayCell, centerStyle)
+
+		if hasAmendment {
+			cell := fmt.Sprintf("%c%d", amendmentCol, row)
+			f.SetCellValue(sheetName, cell, empRow.AmendmentDelta)
+			f.SetCellStyle(sheetName, cell, cell, currencyStyle)
+		}
+
+		if hasForeign {
+			cell := fmt.Sprintf("%c%d", foreignCol, row)
+			f.SetCellValue(sheetName, cell, formatForeignAllowance(empRow.ForeignAllowance))
+		}
+
 		// Write total allowance
 		totalCell := fmt.Sprintf("%c%d", totalCol, row)
 		f.SetCellValue(sheetName, totalCell, empRow.TotalAllowance)
@@ -194,6 +270,21 @@ func (eg *ExcelGenerator) GenerateMonthlyAllowanceReport(requests []models.Trave
 	return buffer.Bytes(), nil
 }
 
+// formatForeignAllowance renders foreign amounts per currency, e.g. "SGD 120,00; USD 300,00"
+func formatForeignAllowance(amounts map[string]float64) string {
+	currencies := make([]string, 0, len(amounts))
+	for currency := range amounts {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	parts := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		parts = append(parts, FormatForeignAmount(currency, amounts[currency]))
+	}
+	return strings.Join(parts, "; ")
+}
+
 func getMonthName(month int) string {
 	months := []string{
 		"Januari", "Februari", "Maret", "April", "Mei", "Juni",
@@ -204,3 +295,106 @@ func getMonthName(month int) string {
 	}
 	return months[month-1]
 }
+
+// GenerateBukuAgenda exports the numbering journal, one sheet per unit code
+func (eg *ExcelGenerator) GenerateBukuAgenda(journals []NumberJournal) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#4472C4"},
+			Pattern: 1,
+		},
+		Font: &excelize.Font{
+			Bold:  true,
+			Size:  11,
+			Color: "#FFFFFF",
+		},
+	})
+
+	titleStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 13},
+	})
+
+	// Void numbers are highlighted so auditors spot them at once
+	voidStyle, _ := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#F8CBAD"},
+			Pattern: 1,
+		},
+	})
+
+	if len(journals) == 0 {
+		f.SetSheetName("Sheet1", "Buku Agenda")
+		f.SetCellValue("Buku Agenda", "A1", "Tidak ada nomor pada periode ini")
+	}
+
+	for i, journal := range journals {
+		sheetName := fmt.Sprintf("Agenda %s", journal.PositionCode)
+		if i == 0 {
+			f.SetSheetName("Sheet1", sheetName)
+		} else if _, err := f.NewSheet(sheetName); err != nil {
+			return nil, fmt.Errorf("failed to create sheet: %w", err)
+		}
+
+		f.SetColWidth(sheetName, "A", "A", 10) // NO. URUT
+		f.SetColWidth(sheetName, "B", "B", 30) // NOMOR
+		f.SetColWidth(sheetName, "C", "C", 14) // TANGGAL
+		f.SetColWidth(sheetName, "D", "D", 12) // STATUS
+		f.SetColWidth(sheetName, "E", "E", 50) // KETERANGAN
+		f.SetColWidth(sheetName, "F", "F", 20) // DICATAT OLEH
+		f.SetColWidth(sheetName, "G", "G", 20) // WAKTU BATAL
+
+		f.SetCellValue(sheetName, "A1", fmt.Sprintf("BUKU AGENDA PENOMORAN %s - UNIT %s - %s",
+			DocumentTypeLabel(journal.DocumentType), journal.PositionCode, journalPeriodLabel(journal)))
+		f.SetCellStyle(sheetName, "A1", "A1", titleStyle)
+		f.SetCellValue(sheetName, "A2", fmt.Sprintf("Terpakai: %d, Batal: %d, Kosong: %d, Nomor terakhir: %d",
+			journal.UsedCount, journal.VoidCount, journal.GapCount, journal.LastNumber))
+
+		headers := []string{"NO. URUT", "NOMOR", "TANGGAL", "STATUS", "KETERANGAN", "DICATAT OLEH", "WAKTU BATAL"}
+		for col, header := range headers {
+			cell := fmt.Sprintf("%c4", 'A'+col)
+			f.SetCellValue(sheetName, cell, header)
+			f.SetCellStyle(sheetName, cell, cell, headerStyle)
+		}
+
+		row := 5
+		for _, entry := range journal.Entries {
+			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), entry.Sequence)
+			f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), entry.Number)
+			if entry.Date != nil {
+				f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), entry.Date.In(utils.JakartaLocation()).Format("02-01-2006"))
+			}
+			f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), JournalStatusLabel(entry.Status))
+
+			note := entry.Description
+			if entry.Status == JournalStatusVoid {
+				note = entry.Reason
+			}
+			f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), note)
+			f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), entry.VoidedBy)
+			if entry.VoidedAt != nil {
+				f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), entry.VoidedAt.In(utils.JakartaLocation()).Format("02-01-2006 15:04"))
+			}
+
+			if entry.Status == JournalStatusVoid {
+				f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), voidStyle)
+			}
+			row++
+		}
+	}
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write excel file: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
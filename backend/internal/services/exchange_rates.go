@@ -0,0 +1,476 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCurrency        = errors.New("invalid currency code")
+	ErrInvalidExchangeRate    = errors.New("invalid exchange rate")
+	ErrExchangeRateMissing    = errors.New("no exchange rate for currency")
+	ErrInvalidForeignAmount   = errors.New("a foreign currency receipt needs a positive original_amount and a receipt_date")
+	ErrMixedAllowanceCurrency = errors.New("travellers have abroad rates in different currencies")
+)
+
+// NormalizeCurrency upper-cases an ISO 4217 code. An empty code is rupiah.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return models.CurrencyIDR, nil
+	}
+	if len(code) != 3 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+		}
+	}
+	return code, nil
+}
+
+// IsForeignCurrency reports whether an amount in currency has to be converted to rupiah
+func IsForeignCurrency(currency string) bool {
+	return currency != "" && currency != models.CurrencyIDR
+}
+
+// ConvertToIDR converts a foreign amount to whole rupiah at a middle rate
+func ConvertToIDR(amount, rate float64) int {
+	return int(math.Round(amount * rate))
+}
+
+// ConvertFromIDR converts rupiah back to the foreign currency, rounded to cents
+func ConvertFromIDR(amount int, rate float64) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	return math.Round(float64(amount)/rate*100) / 100
+}
+
+// formatDecimal renders a number with Indonesian separators and two decimals, e.g. "16.250,50"
+func formatDecimal(amount float64) string {
+	s := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	whole, cents, _ := strings.Cut(s, ".")
+
+	var result strings.Builder
+	if amount < 0 {
+		result.WriteRune('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			result.WriteRune('.')
+		}
+		result.WriteRune(digit)
+	}
+	return result.String() + "," + cents
+}
+
+// FormatForeignAmount renders an amount with its currency code, e.g. "SGD 1.245,50"
+func FormatForeignAmount(currency string, amount float64) string {
+	return currency + " " + formatDecimal(amount)
+}
+
+// FormatExchangeRate renders a middle rate in rupiah, e.g. "Rp 16.250,50"
+func FormatExchangeRate(rate float64) string {
+	return "Rp " + formatDecimal(rate)
+}
+
+// parseRateNumber reads a number in English (16,250.50) or Indonesian (16.250,50) notation.
+// A lone separator followed by exactly three digits groups thousands.
+func parseRateNumber(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(value, ",") == 1 && len(value)-lastComma-1 != 3 {
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastDot >= 0:
+		if strings.Count(value, ".") > 1 || len(value)-lastDot-1 == 3 {
+			value = strings.ReplaceAll(value, ".", "")
+		}
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseRateDate reads the date of a rate: 2006-01-02, or the month-first date and time of
+// the Bank Indonesia export, e.g. 10/17/2025 12:00:00 AM
+func parseRateDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "1/2/2006 3:04:05 PM", "1/2/2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return dateOnly(date), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// exchangeRateColumns maps the accepted header names of a rate CSV to their field. The
+// Indonesian names are those of the Bank Indonesia "Kurs Transaksi BI" export.
+var exchangeRateColumns = map[string]string{
+	"currency":    "currency",
+	"mata uang":   "currency",
+	"date":        "date",
+	"tanggal":     "date",
+	"unit":        "unit",
+	"nilai":       "unit",
+	"middle_rate": "middle",
+	"middle rate": "middle",
+	"kurs tengah": "middle",
+	"sell":        "sell",
+	"kurs jual":   "sell",
+	"buy":         "buy",
+	"kurs beli":   "buy",
+}
+
+// ParseExchangeRateCSV reads middle rates from a CSV with a header row: a currency column,
+// either a middle rate or a sell and buy rate whose average is the middle rate, optionally the
+// unit the rates are quoted per (100 for JPY) and a date. Rows without a date column take
+// defaultDate. Columns may be separated by comma or semicolon.
+func ParseExchangeRateCSV(r io.Reader, defaultDate time.Time) ([]models.ExchangeRate, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read file: %v", ErrInvalidExchangeRate, err)
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	firstLine, _, _ := strings.Cut(text, "\n")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidExchangeRate, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		if field, ok := exchangeRateColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["currency"]; !ok {
+		return nil, fmt.Errorf("%w: missing column %q", ErrInvalidExchangeRate, "currency")
+	}
+	_, hasMiddle := columns["middle"]
+	_, hasSell := columns["sell"]
+	_, hasBuy := columns["buy"]
+	if !hasMiddle && !(hasSell && hasBuy) {
+		return nil, fmt.Errorf("%w: need a middle_rate column or both sell and buy columns", ErrInvalidExchangeRate)
+	}
+	if _, ok := columns["date"]; !ok && defaultDate.IsZero() {
+		return nil, fmt.Errorf("%w: missing column %q", ErrInvalidExchangeRate, "date")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(record []string, name string, row int) (float64, error) {
+		value, err := parseRateNumber(field(record, name))
+		if err != nil || value <= 0 {
+			return 0, fmt.Errorf("%w: row %d: invalid %s %q", ErrInvalidExchangeRate, row, name, field(record, name))
+		}
+		return value, nil
+	}
+
+	var rates []models.ExchangeRate
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidExchangeRate, row, err)
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		currency, err := NormalizeCurrency(field(record, "currency"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidExchangeRate, row, err)
+		}
+		if !IsForeignCurrency(currency) {
+			return nil, fmt.Errorf("%w: row %d: rupiah needs no rate", ErrInvalidExchangeRate, row)
+		}
+
+		date := dateOnly(defaultDate)
+		if value := field(record, "date"); value != "" {
+			if date, err = parseRateDate(value); err != nil {
+				return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidExchangeRate, row, err)
+			}
+		} else if date.IsZero() {
+			return nil, fmt.Errorf("%w: row %d: date is required", ErrInvalidExchangeRate, row)
+		}
+
+		var middle float64
+		if hasMiddle {
+			if middle, err = number(record, "middle", row); err != nil {
+				return nil, err
+			}
+		} else {
+			sell, err := number(record, "sell", row)
+			if err != nil {
+				return nil, err
+			}
+			buy, err := number(record, "buy", row)
+			if err != nil {
+				return nil, err
+			}
+			middle = (sell + buy) / 2
+		}
+
+		unit := 1.0
+		if field(record, "unit") != "" {
+			if unit, err = number(record, "unit", row); err != nil {
+				return nil, err
+			}
+		}
+
+		rates = append(rates, models.ExchangeRate{
+			Currency:   currency,
+			Date:       date,
+			MiddleRate: math.Round(middle/unit*10000) / 10000,
+			Source:     models.ExchangeRateSourceBI,
+		})
+	}
+	return rates, nil
+}
+
+// exchangeRateLookup returns the middle rate of a currency on a date
+type exchangeRateLookup func(currency string, date time.Time) (float64, error)
+
+// convertClaimReceipts converts the foreign receipts of a claim to rupiah at the rate of their
+// receipt date. The costs of an item are left as entered: they already include every receipt.
+func convertClaimReceipts(req *CreateAtCostClaimRequest, rateOn exchangeRateLookup) error {
+	for i := range req.ClaimItems {
+		item := &req.ClaimItems[i]
+		for j := range item.Receipts {
+			receipt := &item.Receipts[j]
+			currency, err := NormalizeCurrency(receipt.Currency)
+			if err != nil {
+				return err
+			}
+			receipt.Currency = currency
+			if !IsForeignCurrency(currency) {
+				receipt.OriginalAmount = float64(receipt.Amount)
+				receipt.ExchangeRate = 1
+				continue
+			}
+
+			date, err := time.Parse("2006-01-02", receipt.ReceiptDate)
+			if err != nil || receipt.OriginalAmount <= 0 {
+				return fmt.Errorf("%w: receipt %q", ErrInvalidForeignAmount, receipt.ReceiptNumber)
+			}
+			rate, err := rateOn(currency, date)
+			if err != nil {
+				return err
+			}
+			receipt.ExchangeRate = rate
+			receipt.Amount = ConvertToIDR(receipt.OriginalAmount, rate)
+		}
+	}
+	return nil
+}
+
+// abroadCurrency returns the foreign currency the abroad rates of positions are in, empty
+// when they are all in rupiah
+func abroadCurrency(positions []*models.Position) (string, error) {
+	currency := ""
+	for _, position := range positions {
+		if !IsForeignCurrency(position.AbroadCurrency) {
+			continue
+		}
+		if currency != "" && currency != position.AbroadCurrency {
+			return "", fmt.Errorf("%w: %s and %s", ErrMixedAllowanceCurrency, currency, position.AbroadCurrency)
+		}
+		currency = position.AbroadCurrency
+	}
+	return currency, nil
+}
+
+// AllowanceConversion is the rate at which the foreign abroad rates of a trip were converted
+type AllowanceConversion struct {
+	Currency string    `json:"currency"`
+	Rate     float64   `json:"rate"`
+	RateDate time.Time `json:"rate_date"`
+}
+
+// ApplyAllowanceConversion stores the conversion of the abroad rates on a request
+func ApplyAllowanceConversion(request *models.TravelRequest, conversion *AllowanceConversion) {
+	request.AllowanceCurrency = ""
+	request.AllowanceExchangeRate = 0
+	request.AllowanceRateDate = nil
+	if conversion == nil {
+		return
+	}
+	rateDate := conversion.RateDate
+	request.AllowanceCurrency = conversion.Currency
+	request.AllowanceExchangeRate = conversion.Rate
+	request.AllowanceRateDate = &rateDate
+}
+
+// ForeignAllowanceAmount returns the abroad allowance of a traveller in the currency of the
+// converted rates, 0 when the trip was paid in rupiah. On an itinerary only abroad legs count.
+func ForeignAllowanceAmount(request *models.TravelRequest, empRel models.TravelRequestEmployee) float64 {
+	if !IsForeignCurrency(request.AllowanceCurrency) {
+		return 0
+	}
+	if len(request.Legs) == 0 {
+		return ConvertFromIDR(EmployeeAllowanceAmount(request, empRel), request.AllowanceExchangeRate)
+	}
+	amount := 0
+	for _, leg := range request.Legs {
+		if leg.DestinationType != "abroad" {
+			continue
+		}
+		for _, allowance := range leg.Allowances {
+			if allowance.ParticipantID == nil && allowance.EmployeeID == empRel.EmployeeID {
+				amount += allowance.Subtotal
+			}
+		}
+	}
+	return ConvertFromIDR(amount, request.AllowanceExchangeRate)
+}
+
+// DescribeAllowanceConversion renders the rate of the abroad allowance for the Nota Permintaan,
+// e.g. "Tarif luar negeri dalam USD dikonversi dengan kurs tengah 17/10/2025: USD 1 = Rp 16.250,00"
+func DescribeAllowanceConversion(request *models.TravelRequest) string {
+	if !IsForeignCurrency(request.AllowanceCurrency) {
+		return ""
+	}
+	rateDate := ""
+	if request.AllowanceRateDate != nil {
+		rateDate = " " + request.AllowanceRateDate.Format("02/01/2006")
+	}
+	return fmt.Sprintf("Tarif luar negeri dalam %s dikonversi dengan kurs tengah%s: %s 1 = %s",
+		request.AllowanceCurrency, rateDate, request.AllowanceCurrency, FormatExchangeRate(request.AllowanceExchangeRate))
+}
+
+// exchangeRateOn loads the newest rate of currency on or before date from db, which may be a
+// transaction. Rupiah is always 1.
+func exchangeRateOn(db *gorm.DB, currency string, date time.Time) (*models.ExchangeRate, error) {
+	if !IsForeignCurrency(currency) {
+		return &models.ExchangeRate{Currency: models.CurrencyIDR, Date: dateOnly(date), MiddleRate: 1}, nil
+	}
+	var rate models.ExchangeRate
+	err := db.Where("currency = ? AND date <= ?", currency, dateOnly(date)).
+		Order("date DESC, id DESC").First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w %s on %s", ErrExchangeRateMissing, currency, date.Format("2006-01-02"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rate: %w", err)
+	}
+	return &rate, nil
+}
+
+// ExchangeRateImportResult counts the rates added and changed by an import
+type ExchangeRateImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// ExchangeRateService keeps the exchange rate table and converts foreign amounts to rupiah
+type ExchangeRateService struct {
+	repo *repository.Repository
+}
+
+func NewExchangeRateService(repo *repository.Repository) *ExchangeRateService {
+	return &ExchangeRateService{repo: repo}
+}
+
+// Rate returns the newest rate of currency on or before date
+func (s *ExchangeRateService) Rate(currency string, date time.Time) (*models.ExchangeRate, error) {
+	return exchangeRateOn(s.repo.GetDB(), currency, date)
+}
+
+// ConvertClaimReceipts converts the foreign receipts of a claim to rupiah at their receipt date
+func (s *ExchangeRateService) ConvertClaimReceipts(req *CreateAtCostClaimRequest) error {
+	return convertClaimReceipts(req, func(currency string, date time.Time) (float64, error) {
+		rate, err := s.Rate(currency, date)
+		if err != nil {
+			return 0, err
+		}
+		return rate.MiddleRate, nil
+	})
+}
+
+// ConvertAbroadAllowances converts the abroad rates of positions priced in a foreign currency to
+// rupiah at the rate on date. Returns nil when every position is priced in rupiah.
+func (s *ExchangeRateService) ConvertAbroadAllowances(positions []*models.Position, date time.Time) (*AllowanceConversion, error) {
+	currency, err := abroadCurrency(positions)
+	if err != nil || currency == "" {
+		return nil, err
+	}
+	rate, err := s.Rate(currency, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, position := range positions {
+		if position.AbroadCurrency == currency {
+			position.AllowanceAbroad = ConvertToIDR(float64(position.AllowanceAbroad), rate.MiddleRate)
+			position.AbroadCurrency = models.CurrencyIDR
+		}
+	}
+	return &AllowanceConversion{Currency: currency, Rate: rate.MiddleRate, RateDate: rate.Date}, nil
+}
+
+// Import adds the rates to the table, replacing the rate a currency already has on a date
+func (s *ExchangeRateService) Import(rates []models.ExchangeRate) (*ExchangeRateImportResult, error) {
+	result := &ExchangeRateImportResult{}
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, rate := range rates {
+			var existing models.ExchangeRate
+			err := tx.Where("currency = ? AND date = ?", rate.Currency, dateOnly(rate.Date)).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&rate).Error; err != nil {
+					return fmt.Errorf("failed to create exchange rate %s %s: %w", rate.Currency, rate.Date.Format("2006-01-02"), err)
+				}
+				result.Created++
+			case err != nil:
+				return fmt.Errorf("failed to look up exchange rate %s %s: %w", rate.Currency, rate.Date.Format("2006-01-02"), err)
+			default:
+				existing.MiddleRate = rate.MiddleRate
+				existing.Source = rate.Source
+				if err := tx.Save(&existing).Error; err != nil {
+					return fmt.Errorf("failed to update exchange rate %s %s: %w", rate.Currency, rate.Date.Format("2006-01-02"), err)
+				}
+				result.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestParseExchangeRateCSV(t *testing.T) {
+	input := "NO;Nilai;Kurs Jual;Kurs Beli;Tanggal;Mata Uang\n" +
+		"1;1;16.331,00;16.169,00;10/17/2025 12:00:00 AM;USD \n" +
+		"2;100;10.900,50;10.790,50;10/17/2025 12:00:00 AM;JPY\n"
+	rates, err := ParseExchangeRateCSV(strings.NewReader(input), time.Time{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("Expected 2 rates, got %d", len(rates))
+	}
+	if rates[0].Currency != "USD" || rates[0].MiddleRate != 16250 {
+		t.Errorf("Expected USD at 16250, got %s at %v", rates[0].Currency, rates[0].MiddleRate)
+	}
+	if rates[1].MiddleRate != 108.455 {
+		t.Errorf("Expected JPY quoted per 100 to be 108.455 per yen, got %v", rates[1].MiddleRate)
+	}
+	if want := time.Date(2025, 10, 17, 0, 0, 0, 0, time.UTC); !rates[0].Date.Equal(want) {
+		t.Errorf("Expected date %v, got %v", want, rates[0].Date)
+	}
+
+	_, err = ParseExchangeRateCSV(strings.NewReader("currency,middle_rate\nSGD,12100\n"), time.Time{})
+	if !errors.Is(err, ErrInvalidExchangeRate) {
+		t.Errorf("Expected a file without dates to be rejected, got %v", err)
+	}
+	rates, err = ParseExchangeRateCSV(strings.NewReader("currency,middle_rate\nSGD,\"12,100.25\"\n"), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || rates[0].MiddleRate != 12100.25 || rates[0].Date.Day() != 1 {
+		t.Errorf("Expected SGD 12100.25 on the default date, got %+v (%v)", rates, err)
+	}
+}
+
+func TestConvertClaimReceipts(t *testing.T) {
+	req := &CreateAtCostClaimRequest{ClaimItems: []CreateAtCostClaimItemReq{{
+		TransportCost:     150000 + 1625000,
+		AccommodationCost: 2970550,
+		Receipts: []CreateAtCostReceiptReq{
+			{Type: "hotel", Currency: "sgd", OriginalAmount: 245.5, ReceiptDate: "2025-10-17"},
+			{Type: "flight", Currency: "USD", OriginalAmount: 100, ReceiptDate: "2025-10-18"},
+			{Type: "train", Amount: 150000},
+		},
+	}}}
+	rates := map[string]float64{"SGD": 12100, "USD": 16250}
+	err := convertClaimReceipts(req, func(currency string, date time.Time) (float64, error) {
+		return rates[currency], nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	item := req.ClaimItems[0]
+	if item.Receipts[0].Amount != 2970550 || item.Receipts[0].Currency != "SGD" {
+		t.Errorf("Expected SGD 245,50 to be Rp 2.970.550, got %+v", item.Receipts[0])
+	}
+	if item.AccommodationCost != 2970550 || item.TransportCost != 150000+1625000 {
+		t.Errorf("Expected the item costs to be left as entered, got %d / %d", item.TransportCost, item.AccommodationCost)
+	}
+	if item.Receipts[2].Currency != models.CurrencyIDR || item.Receipts[2].OriginalAmount != 150000 {
+		t.Errorf("Expected a rupiah receipt to keep its amount, got %+v", item.Receipts[2])
+	}
+
+	missing := &CreateAtCostClaimRequest{ClaimItems: []CreateAtCostClaimItemReq{{
+		Receipts: []CreateAtCostReceiptReq{{Type: "hotel", Currency: "EUR", OriginalAmount: 80}},
+	}}}
+	err = convertClaimReceipts(missing, func(string, time.Time) (float64, error) { return 17000, nil })
+	if !errors.Is(err, ErrInvalidForeignAmount) {
+		t.Errorf("Expected ErrInvalidForeignAmount without a receipt date, got %v", err)
+	}
+}
+
+func TestAbroadAllowanceConversion(t *testing.T) {
+	positions := []*models.Position{
+		{AbroadCurrency: "USD", AllowanceAbroad: 50},
+		{AbroadCurrency: models.CurrencyIDR, AllowanceAbroad: 750000},
+	}
+	if currency, err := abroadCurrency(positions); err != nil || currency != "USD" {
+		t.Errorf("Expected USD, got %q (%v)", currency, err)
+	}
+	positions = append(positions, &models.Position{AbroadCurrency: "SGD"})
+	if _, err := abroadCurrency(positions); !errors.Is(err, ErrMixedAllowanceCurrency) {
+		t.Errorf("Expected ErrMixedAllowanceCurrency, got %v", err)
+	}
+
+	rateDate := time.Date(2025, 10, 17, 0, 0, 0, 0, time.UTC)
+	request := &models.TravelRequest{
+		DestinationType: "abroad",
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, DailyRate: 812500, DurationDays: 3, Subtotal: 2437500},
+		},
+	}
+	ApplyAllowanceConversion(request, &AllowanceConversion{Currency: "USD", Rate: 16250, RateDate: rateDate})
+	if got := ForeignAllowanceAmount(request, request.TravelRequestEmployees[0]); got != 150 {
+		t.Errorf("Expected USD 150, got %v", got)
+	}
+	if got := FormatForeignAmount("USD", 1250.5); got != "USD 1.250,50" {
+		t.Errorf("Expected USD 1.250,50, got %q", got)
+	}
+	if got := DescribeAllowanceConversion(request); !strings.Contains(got, "USD 1 = Rp 16.250,00") || !strings.Contains(got, "17/10/2025") {
+		t.Errorf("Unexpected conversion note %q", got)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidHoliday = errors.New("invalid holiday")
+
+// DayCounts splits the days of a trip into working days, weekend days and holidays. A holiday
+// that falls on a weekend counts as a weekend day.
+type DayCounts struct {
+	WorkingDays int `json:"working_days"`
+	WeekendDays int `json:"weekend_days"`
+	HolidayDays int `json:"holiday_days"`
+}
+
+// NonWorkingDays returns the weekend days and holidays together
+func (d DayCounts) NonWorkingDays() int {
+	return d.WeekendDays + d.HolidayDays
+}
+
+// HolidayCalendar maps dates (2006-01-02) to the name of the holiday on that date
+type HolidayCalendar map[string]string
+
+func NewHolidayCalendar(holidays []models.Holiday) HolidayCalendar {
+	calendar := make(HolidayCalendar, len(holidays))
+	for _, holiday := range holidays {
+		calendar[holiday.Date.Format("2006-01-02")] = holiday.Name
+	}
+	return calendar
+}
+
+// IsWeekend reports whether date is a Saturday or Sunday
+func IsWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// CountDays counts the days from one date up to and including another
+func (c HolidayCalendar) CountDays(from, to time.Time) DayCounts {
+	var counts DayCounts
+	for day := dateOnly(from); !day.After(dateOnly(to)); day = day.AddDate(0, 0, 1) {
+		switch {
+		case IsWeekend(day):
+			counts.WeekendDays++
+		case c[day.Format("2006-01-02")] != "":
+			counts.HolidayDays++
+		default:
+			counts.WorkingDays++
+		}
+	}
+	return counts
+}
+
+// ApplyDayCounts stores the weekend days and holidays between the departure and return date
+// of a request
+func (c HolidayCalendar) ApplyDayCounts(request *models.TravelRequest) {
+	counts := c.CountDays(request.DepartureDate, request.ReturnDate)
+	request.WeekendDays = counts.WeekendDays
+	request.HolidayDays = counts.HolidayDays
+}
+
+// ParseHolidayCSV reads holidays from a CSV file with a header row. The columns are matched by
+// name: date (2006-01-02), name and optionally collective_leave (true/ya/1 for cuti bersama).
+func ParseHolidayCSV(r io.Reader) ([]models.Holiday, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidHoliday, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"date", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidHoliday, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var holidays []models.Holiday
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidHoliday, row, err)
+		}
+		date, err := time.Parse("2006-01-02", field(record, "date"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: date must be YYYY-MM-DD", ErrInvalidHoliday, row)
+		}
+		name := field(record, "name")
+		if name == "" {
+			return nil, fmt.Errorf("%w: row %d: name is required", ErrInvalidHoliday, row)
+		}
+		switch strings.ToLower(field(record, "collective_leave")) {
+		case "true", "ya", "1":
+			holidays = append(holidays, models.Holiday{Date: date, Name: name, CollectiveLeave: true})
+		default:
+			holidays = append(holidays, models.Holiday{Date: date, Name: name})
+		}
+	}
+	return holidays, nil
+}
+
+// ParseHolidayICal reads the all-day events of an iCalendar file as holidays. An event that spans
+// several days yields one holiday per day; DTEND is exclusive as in the iCalendar format.
+func ParseHolidayICal(r io.Reader) ([]models.Holiday, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHoliday, err)
+	}
+
+	var holidays []models.Holiday
+	var start, end time.Time
+	var summary string
+	inEvent := false
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		property := strings.ToUpper(strings.SplitN(name, ";", 2)[0])
+		switch {
+		case property == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			start, end, summary = time.Time{}, time.Time{}, ""
+		case property == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("%w: event %q has no DTSTART", ErrInvalidHoliday, summary)
+			}
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays = append(holidays, models.Holiday{Date: day, Name: summary})
+			}
+		case !inEvent:
+			continue
+		case property == "DTSTART":
+			if start, err = parseICalDate(value); err != nil {
+				return nil, err
+			}
+		case property == "DTEND":
+			if end, err = parseICalDate(value); err != nil {
+				return nil, err
+			}
+		case property == "SUMMARY":
+			summary = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+		}
+	}
+	return holidays, nil
+}
+
+// unfoldICalLines joins the continuation lines of an iCalendar file, which start with a space or tab
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalDate reads the date of a DATE or DATE-TIME value, e.g. 20250101 or 20250101T000000Z
+func parseICalDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q", ErrInvalidHoliday, value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q", ErrInvalidHoliday, value)
+	}
+	return date, nil
+}
+
+// holidayCalendarBetween loads the holidays between two dates from db, which may be a transaction
+func holidayCalendarBetween(db *gorm.DB, from, to time.Time) (HolidayCalendar, error) {
+	var holidays []models.Holiday
+	err := db.Where("date BETWEEN ? AND ?", dateOnly(from), dateOnly(to)).Find(&holidays).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load holidays: %w", err)
+	}
+	return NewHolidayCalendar(holidays), nil
+}
+
+// HolidayImportResult counts the holidays added and changed by an import
+type HolidayImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// HolidayService keeps the holiday calendar and counts the non-working days of trips
+type HolidayService struct {
+	repo *repository.Repository
+}
+
+func NewHolidayService(repo *repository.Repository) *HolidayService {
+	return &HolidayService{repo: repo}
+}
+
+// Calendar returns the holidays between two dates
+func (s *HolidayService) Calendar(from, to time.Time) (HolidayCalendar, error) {
+	return holidayCalendarBetween(s.repo.GetDB(), from, to)
+}
+
+// ApplyDayCounts stores the weekend days and holidays of a request on it
+func (s *HolidayService) ApplyDayCounts(request *models.TravelRequest) error {
+	calendar, err := s.Calendar(request.DepartureDate, request.ReturnDate)
+	if err != nil {
+		return err
+	}
+	calendar.ApplyDayCounts(request)
+	return nil
+}
+
+// Import adds the holidays to the calendar, renaming holidays that already exist on a date
+func (s *HolidayService) Import(holidays []models.Holiday) (*HolidayImportResult, error) {
+	result := &HolidayImportResult{}
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, holiday := range holidays {
+			var existing models.Holiday
+			err := tx.Where("date = ?", dateOnly(holiday.Date)).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&holiday).Error; err != nil {
+					return fmt.Errorf("failed to create holiday %s: %w", holiday.Date.Format("2006-01-02"), err)
+				}
+				result.Created++
+			case err != nil:
+				return fmt.Errorf("failed to look up holiday %s: %w", holiday.Date.Format("2006-01-02"), err)
+			default:
+				existing.Name = holiday.Name
+				existing.CollectiveLeave = holiday.CollectiveLeave
+				if err := tx.Save(&existing).Error; err != nil {
+					return fmt.Errorf("failed to update holiday %s: %w", holiday.Date.Format("2006-01-02"), err)
+				}
+				result.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
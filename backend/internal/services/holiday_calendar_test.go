@@ -0,0 +1,65 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestHolidayCalendarCountDays(t *testing.T) {
+	date := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	calendar := NewHolidayCalendar([]models.Holiday{
+		{Date: date(3, 31), Name: "Idul Fitri"},
+		{Date: date(3, 30), Name: "Hari Raya Nyepi"}, // Minggu, dihitung akhir pekan
+	})
+
+	// Kamis 27 Maret s.d. Selasa 1 April 2025
+	counts := calendar.CountDays(date(3, 27), date(4, 1))
+	if counts.WorkingDays != 3 || counts.WeekendDays != 2 || counts.HolidayDays != 1 {
+		t.Errorf("Expected 3 working, 2 weekend and 1 holiday day, got %+v", counts)
+	}
+	if counts.NonWorkingDays() != 3 {
+		t.Errorf("Expected 3 non-working days, got %d", counts.NonWorkingDays())
+	}
+}
+
+func TestParseHolidayFiles(t *testing.T) {
+	csvInput := "date,name,collective_leave\n2025-01-01,Tahun Baru,\n2025-04-02,Cuti Bersama Idul Fitri,ya\n"
+	holidays, err := ParseHolidayCSV(strings.NewReader(csvInput))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(holidays) != 2 || !holidays[1].CollectiveLeave {
+		t.Errorf("Expected two holidays, the second a collective leave, got %+v", holidays)
+	}
+
+	ical := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250331\r\nDTEND;VALUE=DATE:20250402\r\nSUMMARY:Hari Raya\r\n  Idul Fitri\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250101\r\nSUMMARY:Tahun Baru\\, Masehi\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	holidays, err = ParseHolidayICal(strings.NewReader(ical))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(holidays) != 3 {
+		t.Fatalf("Expected the two-day event to yield two holidays, got %+v", holidays)
+	}
+	if holidays[1].Date.Day() != 1 || holidays[1].Name != "Hari Raya Idul Fitri" {
+		t.Errorf("Expected the folded summary on 1 April, got %+v", holidays[1])
+	}
+	if holidays[2].Name != "Tahun Baru, Masehi" {
+		t.Errorf("Expected the escaped comma to be unescaped, got %q", holidays[2].Name)
+	}
+}
+
+func TestPolicyNonWorkingDays(t *testing.T) {
+	rules := []models.TravelPolicyRule{{ID: 1, Name: "Akhir pekan", NonWorkingDays: true, Effect: models.PolicyEffectJustify}}
+	if evaluation := EvaluatePolicy(rules, PolicySubject{DurationDays: 2}); len(evaluation.RequiredJustifications) != 0 {
+		t.Errorf("Expected a weekday trip not to match, got %+v", evaluation)
+	}
+	if evaluation := EvaluatePolicy(rules, PolicySubject{DurationDays: 2, NonWorkingDays: 1}); len(evaluation.RequiredJustifications) != 1 {
+		t.Errorf("Expected a weekend trip to require a justification, got %+v", evaluation)
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidItinerary = errors.New("invalid itinerary")
+
+// destinationTypeRank orders the allowance zones from nearest to farthest
+var destinationTypeRank = map[string]int{
+	"in_province":      1,
+	"outside_province": 2,
+	"abroad":           3,
+}
+
+// DestinationTypeLabel returns the Indonesian name of an allowance zone
+func DestinationTypeLabel(destinationType string) string {
+	switch destinationType {
+	case "in_province":
+		return "Dalam Provinsi"
+	case "outside_province":
+		return "Luar Provinsi"
+	case "abroad":
+		return "Luar Negeri"
+	}
+	return destinationType
+}
+
+// ValidateItinerary checks that legs are complete and in travel order: every leg arrives
+// on or after its departure and no leg leaves before the previous one has arrived. Dates are
+// compared on the local calendars of the places.
+func ValidateItinerary(legs []models.TravelLeg) error {
+	if len(legs) == 0 {
+		return fmt.Errorf("%w: at least one leg is required", ErrInvalidItinerary)
+	}
+	for i, leg := range legs {
+		if strings.TrimSpace(leg.FromPlace) == "" || strings.TrimSpace(leg.ToPlace) == "" {
+			return fmt.Errorf("%w: leg %d needs a from and to place", ErrInvalidItinerary, i+1)
+		}
+		if _, ok := destinationTypeRank[leg.DestinationType]; !ok {
+			return fmt.Errorf("%w: leg %d has invalid destination_type %q", ErrInvalidItinerary, i+1, leg.DestinationType)
+		}
+		if dateOnly(leg.ArrivalDate).Before(dateOnly(leg.DepartureDate)) {
+			return fmt.Errorf("%w: leg %d arrives before it departs", ErrInvalidItinerary, i+1)
+		}
+		if i > 0 && dateOnly(leg.DepartureDate).Before(dateOnly(legs[i-1].ArrivalDate)) {
+			return fmt.Errorf("%w: leg %d departs before leg %d arrives", ErrInvalidItinerary, i+1, i)
+		}
+	}
+	return nil
+}
+
+// ItineraryLegDays returns the allowance days of each leg. A leg pays from its departure
+// day up to the day before the next leg departs; the last leg pays until its arrival day.
+// Together the legs cover every day of the trip exactly once.
+func ItineraryLegDays(legs []models.TravelLeg) []int {
+	days := make([]int, len(legs))
+	for i, leg := range legs {
+		if i < len(legs)-1 {
+			days[i] = repository.CalculateDurationDays(leg.DepartureDate, legs[i+1].DepartureDate) - 1
+			continue
+		}
+		days[i] = repository.CalculateDurationDays(leg.DepartureDate, leg.ArrivalDate)
+	}
+	return days
+}
+
+// LegZone returns the rate zone of a leg, with its destination tier
+func LegZone(leg models.TravelLeg) RateZone {
+	return RateZone{
+		DestinationType:   leg.DestinationType,
+		Tier:              leg.DestinationTier,
+		MultiplierPercent: leg.TierMultiplier,
+	}
+}
+
+// CalculateItinerary computes the allowance of every employee per leg day, each leg at the
+// employee's position rate for the zone and tier of that leg. It fills AllowanceDays and Allowances
+// of every leg and returns the per-employee totals (in the same order as employees).
+func (ac *AllowanceCalculator) CalculateItinerary(employees []models.Employee, legs []models.TravelLeg) ([]EmployeeAllowance, int, error) {
+	legDays := ItineraryLegDays(legs)
+	allowances := make([]EmployeeAllowance, len(employees))
+	for i, employee := range employees {
+		allowances[i].EmployeeID = employee.ID
+	}
+
+	total := 0
+	for i := range legs {
+		legs[i].Sequence = i + 1
+		legs[i].AllowanceDays = legDays[i]
+		legs[i].Allowances = make([]models.TravelLegAllowance, 0, len(employees))
+
+		for j, employee := range employees {
+			rate, err := ac.ZoneRate(employee.Position, LegZone(legs[i]))
+			if err != nil {
+				return nil, 0, err
+			}
+
+			subtotal := rate * legDays[i]
+			legs[i].Allowances = append(legs[i].Allowances, models.TravelLegAllowance{
+				EmployeeID: employee.ID,
+				DailyRate:  rate,
+				Days:       legDays[i],
+				Subtotal:   subtotal,
+			})
+			allowances[j].DurationDays += legDays[i]
+			allowances[j].Subtotal += subtotal
+			total += subtotal
+		}
+	}
+
+	return allowances, total, nil
+}
+
+// ApplyItinerary sets the legs of a request and derives its single-destination fields
+// from them: origin, the places visited, the farthest zone, the dates and the transport modes
+func ApplyItinerary(request *models.TravelRequest, legs []models.TravelLeg) {
+	first, last := legs[0], legs[len(legs)-1]
+
+	var places, modes []string
+	seenPlace := map[string]bool{first.FromPlace: true}
+	seenMode := make(map[string]bool)
+	destinationType := first.DestinationType
+	days := 0
+	for _, legDays := range ItineraryLegDays(legs) {
+		days += legDays
+	}
+	for _, leg := range legs {
+		if !seenPlace[leg.ToPlace] {
+			seenPlace[leg.ToPlace] = true
+			places = append(places, leg.ToPlace)
+		}
+		if !seenMode[leg.Transportation] {
+			seenMode[leg.Transportation] = true
+			modes = append(modes, leg.Transportation)
+		}
+		if destinationTypeRank[leg.DestinationType] > destinationTypeRank[destinationType] {
+			destinationType = leg.DestinationType
+		}
+	}
+	if len(places) == 0 {
+		places = append(places, last.ToPlace)
+	}
+
+	request.Legs = legs
+	request.DeparturePlace = first.FromPlace
+	request.Destination = strings.Join(places, ", ")
+	request.DestinationType = destinationType
+	request.DestinationTier = ""
+	request.TierMultiplier = 100
+	request.DepartureDate = first.DepartureDate
+	request.ReturnDate = last.ArrivalDate
+	request.DepartureTimeZone = first.DepartureTimeZone
+	request.ReturnTimeZone = last.ArrivalTimeZone
+	request.DurationDays = days
+	request.Transportation = strings.Join(modes, ", ")
+}
+
+// TravelDaysByDestinationType returns the allowance days of a request per zone
+func TravelDaysByDestinationType(request *models.TravelRequest) map[string]int {
+	days := make(map[string]int)
+	if len(request.Legs) == 0 {
+		days[request.DestinationType] = request.DurationDays
+		return days
+	}
+	for _, leg := range request.Legs {
+		days[leg.DestinationType] += leg.AllowanceDays
+	}
+	return days
+}
+
+// ItineraryGoesAbroad reports whether any leg of an itinerary is paid at the abroad rate
+func ItineraryGoesAbroad(legs []models.TravelLeg) bool {
+	for _, leg := range legs {
+		if leg.DestinationType == "abroad" {
+			return true
+		}
+	}
+	return false
+}
+
+// VisitProofsFromLegs pre-generates the visit proof rows of the Berita Acara, one per leg.
+// The traveller stays at the destination of every leg but the last.
+func VisitProofsFromLegs(reportID uint, legs []models.TravelLeg) []models.VisitProof {
+	proofs := make([]models.VisitProof, 0, len(legs))
+	for i, leg := range legs {
+		proof := models.VisitProof{
+			TravelReportID: reportID,
+			Date:           leg.DepartureDate,
+			DepartFrom:     leg.FromPlace,
+			ArriveAt:       leg.ToPlace,
+		}
+		if i < len(legs)-1 {
+			proof.StayOrStopAt = leg.ToPlace
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs
+}
+
+// CreateItineraryTx saves the legs of a request with their per-traveller allowance.
+// Participants must be saved first so their allowance rows can refer to them.
+func CreateItineraryTx(tx *gorm.DB, requestID uint, legs []models.TravelLeg) error {
+	for i := range legs {
+		legs[i].ID = 0
+		legs[i].TravelRequestID = requestID
+		if err := tx.Omit("Allowances").Create(&legs[i]).Error; err != nil {
+			return fmt.Errorf("failed to create travel leg: %w", err)
+		}
+		for j := range legs[i].Allowances {
+			allowance := &legs[i].Allowances[j]
+			allowance.ID = 0
+			allowance.TravelLegID = legs[i].ID
+			if allowance.Participant != nil {
+				allowance.ParticipantID = &allowance.Participant.ID
+			}
+		}
+		if len(legs[i].Allowances) > 0 {
+			if err := tx.Omit("Participant").Create(&legs[i].Allowances).Error; err != nil {
+				return fmt.Errorf("failed to create leg allowance: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// CreateVisitProofsTx pre-generates the visit proofs of a travel report from the legs
+func CreateVisitProofsTx(tx *gorm.DB, reportID uint, legs []models.TravelLeg) error {
+	proofs := VisitProofsFromLegs(reportID, legs)
+	if len(proofs) == 0 {
+		return nil
+	}
+	if err := tx.Create(&proofs).Error; err != nil {
+		return fmt.Errorf("failed to create visit proofs: %w", err)
+	}
+	return nil
+}
+
+// ReplaceItineraryTx replaces the legs of a request and the visit proofs generated from them
+func ReplaceItineraryTx(tx *gorm.DB, requestID uint, legs []models.TravelLeg) error {
+	var legIDs []uint
+	if err := tx.Model(&models.TravelLeg{}).Where("travel_request_id = ?", requestID).Pluck("id", &legIDs).Error; err != nil {
+		return err
+	}
+	if len(legIDs) > 0 {
+		if err := tx.Where("travel_leg_id IN ?", legIDs).Delete(&models.TravelLegAllowance{}).Error; err != nil {
+			return fmt.Errorf("failed to remove leg allowances: %w", err)
+		}
+		if err := tx.Where("travel_request_id = ?", requestID).Delete(&models.TravelLeg{}).Error; err != nil {
+			return fmt.Errorf("failed to remove travel legs: %w", err)
+		}
+	}
+	if err := CreateItineraryTx(tx, requestID, legs); err != nil {
+		return err
+	}
+
+	var report models.TravelReport
+	err := tx.Where("travel_request_id = ?", requestID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("travel_report_id = ?", report.ID).Delete(&models.VisitProof{}).Error; err != nil {
+		return fmt.Errorf("failed to remove visit proofs: %w", err)
+	}
+	return CreateVisitProofsTx(tx, report.ID, legs)
+}
+
+// describeItinerary renders the legs of a request for the revision history,
+// e.g. "Surabaya-Jakarta 2025-03-01/2025-03-01 pesawat outside_province"
+func describeItinerary(request *models.TravelRequest) string {
+	parts := make([]string, 0, len(request.Legs))
+	for _, leg := range request.Legs {
+		parts = append(parts, fmt.Sprintf("%s-%s %s/%s %s %s",
+			leg.FromPlace, leg.ToPlace,
+			leg.DepartureDate.Format("2006-01-02"), leg.ArrivalDate.Format("2006-01-02"),
+			leg.Transportation, leg.DestinationType))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func testItinerary() []models.TravelLeg {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	return []models.TravelLeg{
+		{FromPlace: "Surabaya", ToPlace: "Malang", DepartureDate: day(1), ArrivalDate: day(1), Transportation: "mobil dinas", DestinationType: "in_province"},
+		{FromPlace: "Malang", ToPlace: "Jakarta", DepartureDate: day(2), ArrivalDate: day(2), Transportation: "kereta api", DestinationType: "outside_province"},
+		{FromPlace: "Jakarta", ToPlace: "Surabaya", DepartureDate: day(4), ArrivalDate: day(4), Transportation: "pesawat", DestinationType: "in_province"},
+	}
+}
+
+func TestCalculateItinerary(t *testing.T) {
+	legs := testItinerary()
+	employee := models.Employee{ID: 1, Position: models.Position{AllowanceInProvince: 100000, AllowanceOutsideProvince: 200000}}
+
+	allowances, total, err := NewAllowanceCalculator().CalculateItinerary([]models.Employee{employee}, legs)
+	if err != nil {
+		t.Fatalf("CalculateItinerary failed: %v", err)
+	}
+
+	wantDays := []int{1, 2, 1}
+	for i, leg := range legs {
+		if leg.AllowanceDays != wantDays[i] {
+			t.Errorf("Leg %d: expected %d days, got %d", i+1, wantDays[i], leg.AllowanceDays)
+		}
+		if leg.Sequence != i+1 || len(leg.Allowances) != 1 {
+			t.Errorf("Leg %d: unexpected sequence %d or %d allowances", i+1, leg.Sequence, len(leg.Allowances))
+		}
+	}
+	if legs[1].Allowances[0].Subtotal != 400000 {
+		t.Errorf("Expected 2 days outside province = 400000, got %d", legs[1].Allowances[0].Subtotal)
+	}
+	if total != 600000 || allowances[0].Subtotal != 600000 || allowances[0].DurationDays != 4 {
+		t.Errorf("Expected 4 days and 600000 in total, got %d days, %d and %d", allowances[0].DurationDays, allowances[0].Subtotal, total)
+	}
+}
+
+func TestApplyItinerary(t *testing.T) {
+	legs := testItinerary()
+	request := &models.TravelRequest{}
+	ApplyItinerary(request, legs)
+
+	if request.DeparturePlace != "Surabaya" || request.Destination != "Malang, Jakarta" {
+		t.Errorf("Unexpected route %s -> %s", request.DeparturePlace, request.Destination)
+	}
+	if request.DestinationType != "outside_province" {
+		t.Errorf("Expected farthest zone outside_province, got %s", request.DestinationType)
+	}
+	if request.DurationDays != 4 || !request.ReturnDate.Equal(legs[2].ArrivalDate) {
+		t.Errorf("Expected 4 days until the last arrival, got %d until %s", request.DurationDays, request.ReturnDate)
+	}
+	if request.Transportation != "mobil dinas, kereta api, pesawat" {
+		t.Errorf("Unexpected transportation %q", request.Transportation)
+	}
+
+	request.Legs[0].AllowanceDays, request.Legs[1].AllowanceDays, request.Legs[2].AllowanceDays = 1, 2, 1
+	days := TravelDaysByDestinationType(request)
+	if days["in_province"] != 2 || days["outside_province"] != 2 {
+		t.Errorf("Unexpected days per zone %v", days)
+	}
+}
+
+func TestValidateItinerary(t *testing.T) {
+	if err := ValidateItinerary(testItinerary()); err != nil {
+		t.Errorf("Expected valid itinerary, got %v", err)
+	}
+
+	overlapping := testItinerary()
+	overlapping[1].ArrivalDate = overlapping[2].DepartureDate.AddDate(0, 0, 1)
+	if err := ValidateItinerary(overlapping); !errors.Is(err, ErrInvalidItinerary) {
+		t.Errorf("Expected ErrInvalidItinerary for a leg leaving before the previous arrives, got %v", err)
+	}
+
+	if err := ValidateItinerary(nil); !errors.Is(err, ErrInvalidItinerary) {
+		t.Errorf("Expected ErrInvalidItinerary without legs, got %v", err)
+	}
+}
+
+func TestVisitProofsFromLegs(t *testing.T) {
+	proofs := VisitProofsFromLegs(9, testItinerary())
+	if len(proofs) != 3 {
+		t.Fatalf("Expected one visit proof per leg, got %d", len(proofs))
+	}
+	if proofs[1].DepartFrom != "Malang" || proofs[1].StayOrStopAt != "Jakarta" || proofs[1].TravelReportID != 9 {
+		t.Errorf("Unexpected visit proof %+v", proofs[1])
+	}
+	if proofs[2].StayOrStopAt != "" {
+		t.Errorf("Expected no stay on the last leg, got %q", proofs[2].StayOrStopAt)
+	}
+}
+
+func TestCalculateAmendmentItinerary(t *testing.T) {
+	legs := testItinerary()
+	employee := models.Employee{ID: 1, Position: models.Position{AllowanceInProvince: 100000, AllowanceOutsideProvince: 200000}}
+	calc := NewAllowanceCalculator()
+	allowances, total, _ := calc.CalculateItinerary([]models.Employee{employee}, legs)
+
+	request := &models.TravelRequest{TotalAllowance: total}
+	ApplyItinerary(request, legs)
+	request.TravelRequestEmployees = []models.TravelRequestEmployee{
+		{EmployeeID: 1, Employee: employee, DurationDays: allowances[0].DurationDays, Subtotal: allowances[0].Subtotal},
+	}
+
+	amendment, err := CalculateAmendment(request, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), "", nil, calc)
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if got := amendment.Employees[0].Delta; got != 100000 {
+		t.Errorf("Expected one extra day at the last leg rate, got delta %d", got)
+	}
+
+	if _, err := CalculateAmendment(request, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), "", nil, calc); !errors.Is(err, ErrAmendmentInvalidDate) {
+		t.Errorf("Expected ErrAmendmentInvalidDate before the last leg departs, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var ErrNoKuitansi = errors.New("no kuitansi to generate")
+
+// Kuitansi is the receipt an employee signs for the allowance of one trip
+type Kuitansi struct {
+	Number   string // {request number}/KWT-{employee ID}
+	Request  *models.TravelRequest
+	Employee models.Employee
+	Amount   int // Uang harian yang diterima, termasuk perubahan karena addendum
+}
+
+// IsAllowancePaidStatus reports whether the allowance of a request in status is paid out
+func IsAllowancePaidStatus(status string) bool {
+	switch status {
+	case models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// BuildKuitansi returns the kuitansi of every employee of request who receives an allowance.
+// They are numbered by employee, so a kuitansi keeps its number however the employees are loaded.
+func BuildKuitansi(request *models.TravelRequest) []Kuitansi {
+	if !IsAllowancePaidStatus(request.Status) {
+		return nil
+	}
+	var receipts []Kuitansi
+	for _, empRel := range request.TravelRequestEmployees {
+		amount := EmployeeAllowanceAmount(request, empRel)
+		if amount <= 0 {
+			continue
+		}
+		receipts = append(receipts, Kuitansi{
+			Number:   utils.GenerateKuitansiNumber(request.RequestNumber, empRel.EmployeeID),
+			Request:  request,
+			Employee: empRel.Employee,
+			Amount:   amount,
+		})
+	}
+	return receipts
+}
+
+// MonthlyKuitansi returns the kuitansi of the trips departing in a month, the same trips as the
+// monthly allowance report, ordered by departure date
+func MonthlyKuitansi(requests []models.TravelRequest, year, month int) []Kuitansi {
+	var trips []*models.TravelRequest
+	for i := range requests {
+		departureDate := requests[i].DepartureDate
+		if departureDate.Year() != year || int(departureDate.Month()) != month {
+			continue
+		}
+		trips = append(trips, &requests[i])
+	}
+	sort.SliceStable(trips, func(i, j int) bool {
+		if !trips[i].DepartureDate.Equal(trips[j].DepartureDate) {
+			return trips[i].DepartureDate.Before(trips[j].DepartureDate)
+		}
+		return trips[i].ID < trips[j].ID
+	})
+
+	var receipts []Kuitansi
+	for _, request := range trips {
+		receipts = append(receipts, BuildKuitansi(request)...)
+	}
+	return receipts
+}
+
+// AmountInWords spells a rupiah amount for the "terbilang" of a kuitansi,
+// e.g. "Satu juta dua ratus ribu rupiah"
+func AmountInWords(amount int) string {
+	words := numberToWords(amount) + " rupiah"
+	if amount == 0 {
+		words = "nol rupiah"
+	}
+	return strings.ToUpper(words[:1]) + words[1:]
+}
+
+// GenerateKuitansi generates a PDF with one kuitansi per page
+func (pg *PDFGenerator) GenerateKuitansi(receipts []Kuitansi) ([]byte, error) {
+	if len(receipts) == 0 {
+		return nil, ErrNoKuitansi
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	for _, receipt := range receipts {
+		pg.addKuitansiPage(pdf, receipt)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (pg *PDFGenerator) addKuitansiPage(pdf *gofpdf.Fpdf, receipt Kuitansi) {
+	request := receipt.Request
+	pdf.AddPage()
+
+	pdf.Image("assets/images/bpd.png", 15, 10, 20, 0, false, "", 0, "")
+	pdf.Image("assets/images/bank jatim.png", 155, 10, 40, 0, false, "", 0, "")
+
+	// Title
+	pdf.SetY(35)
+	pdf.SetFont("Arial", "BU", 14)
+	pdf.CellFormat(0, 7, "KUITANSI", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", receipt.Number), "", 1, "C", false, 0, "")
+	pdf.Ln(8)
+
+	detailRow := func(label, value string) {
+		pdf.CellFormat(45, 7, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 7, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 7, value, "", "L", false)
+		pdf.Ln(1)
+	}
+
+	detailRow("Sudah terima dari", "PT. Bank Pembangunan Daerah Jawa Timur")
+
+	// Terbilang
+	pdf.CellFormat(45, 7, "Uang sebanyak", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 7, ":", "", 0, "L", false, 0, "")
+	pdf.SetFont("Arial", "BI", 11)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.MultiCell(0, 7, AmountInWords(receipt.Amount), "1", "L", true)
+	pdf.SetFont("Arial", "", 11)
+	pdf.Ln(1)
+
+	detailRow("Untuk pembayaran", fmt.Sprintf("Uang harian perjalanan dinas ke %s tanggal %s s.d. %s selama %d (%s) hari, sesuai Nota Permintaan Surat Tugas Perjalanan Dinas Nomor %s",
+		request.Destination,
+		request.DepartureDate.Format("02 January 2006"),
+		request.ReturnDate.Format("02 January 2006"),
+		request.DurationDays, numberToWords(request.DurationDays),
+		request.RequestNumber))
+	pdf.Ln(4)
+
+	// Amount
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(20, 10, "Jumlah", "", 0, "L", false, 0, "")
+	pdf.CellFormat(60, 10, formatCurrency(receipt.Amount), "1", 1, "C", true, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.Ln(10)
+
+	// Signatures: the division paying on the left, the employee receiving on the right
+	repName := "M. MACHFUD HIDAYAT"
+	repPosition := "Vice President"
+	if request.TravelReport != nil {
+		repName = request.TravelReport.RepresentativeName
+		repPosition = request.TravelReport.RepresentativePosition
+	}
+
+	x1, x2 := 15.0, 110.0
+	y := pdf.GetY()
+	pdf.SetXY(x1, y)
+	pdf.CellFormat(85, 6, "Lunas dibayar,", "", 1, "C", false, 0, "")
+	pdf.SetX(x1)
+	pdf.CellFormat(85, 6, "DIVISI DIGITAL BANKING", "", 1, "C", false, 0, "")
+	pdf.SetXY(x2, y)
+	pdf.CellFormat(85, 6, "Surabaya, ............................", "", 1, "C", false, 0, "")
+	pdf.SetX(x2)
+	pdf.CellFormat(85, 6, "Yang menerima,", "", 1, "C", false, 0, "")
+	pdf.Ln(22)
+
+	y = pdf.GetY()
+	pdf.SetFont("Arial", "BU", 11)
+	pdf.SetXY(x1, y)
+	pdf.CellFormat(85, 6, repName, "", 0, "C", false, 0, "")
+	pdf.SetXY(x2, y)
+	pdf.CellFormat(85, 6, fitCellText(pdf, receipt.Employee.Name, 85), "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	y = pdf.GetY()
+	pdf.SetXY(x1, y)
+	pdf.CellFormat(85, 6, repPosition, "", 0, "C", false, 0, "")
+	pdf.SetXY(x2, y)
+	pdf.CellFormat(85, 6, fmt.Sprintf("NIP. %s", receipt.Employee.NIP), "", 1, "C", false, 0, "")
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestNumberToWords(t *testing.T) {
+	cases := map[int]string{
+		3:          "tiga",
+		21:         "dua puluh satu",
+		100:        "seratus",
+		115:        "seratus lima belas",
+		250:        "dua ratus lima puluh",
+		1000:       "seribu",
+		1500:       "seribu lima ratus",
+		25000:      "dua puluh lima ribu",
+		150000:     "seratus lima puluh ribu",
+		1000000:    "satu juta",
+		1250750:    "satu juta dua ratus lima puluh ribu tujuh ratus lima puluh",
+		2000000000: "dua miliar",
+	}
+	for num, want := range cases {
+		if got := numberToWords(num); got != want {
+			t.Errorf("numberToWords(%d) = %q, want %q", num, got, want)
+		}
+	}
+	if got := AmountInWords(1200000); got != "Satu juta dua ratus ribu rupiah" {
+		t.Errorf("Unexpected terbilang %q", got)
+	}
+}
+
+func TestMonthlyKuitansi(t *testing.T) {
+	employees := []models.TravelRequestEmployee{
+		{EmployeeID: 1, Employee: models.Employee{ID: 1, Name: "Andi"}, Subtotal: 900000},
+		{EmployeeID: 2, Employee: models.Employee{ID: 2, Name: "Budi"}, Subtotal: 0, DailyRate: 300000},
+	}
+	requests := []models.TravelRequest{
+		{ID: 3, RequestNumber: "064/0003/DIB/DPEB/NOTA", Status: models.TravelStatusApproved, DepartureDate: time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC), TravelRequestEmployees: employees},
+		{ID: 2, RequestNumber: "064/0002/DIB/DPEB/NOTA", Status: models.TravelStatusCancelled, DepartureDate: time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC), TravelRequestEmployees: employees},
+		{ID: 1, RequestNumber: "064/0001/DIB/DPEB/NOTA", Status: models.TravelStatusCompleted, DepartureDate: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC), TravelRequestEmployees: employees},
+		{ID: 4, RequestNumber: "064/0004/DIB/DPEB/NOTA", Status: models.TravelStatusApproved, DepartureDate: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), TravelRequestEmployees: employees},
+	}
+
+	receipts := MonthlyKuitansi(requests, 2025, 5)
+	if len(receipts) != 2 {
+		t.Fatalf("Expected 2 kuitansi without the cancelled trip and the unpaid employee, got %d", len(receipts))
+	}
+	if receipts[0].Number != "064/0001/DIB/DPEB/NOTA/KWT-1" || receipts[1].Number != "064/0003/DIB/DPEB/NOTA/KWT-1" {
+		t.Errorf("Expected kuitansi ordered by departure date, got %s and %s", receipts[0].Number, receipts[1].Number)
+	}
+	if receipts[0].Amount != 900000 || receipts[0].Employee.Name != "Andi" {
+		t.Errorf("Unexpected kuitansi %+v", receipts[0])
+	}
+}
+
+func TestBuildKuitansiNumberedByEmployee(t *testing.T) {
+	request := &models.TravelRequest{
+		RequestNumber: "064/0005/DIB/DPEB/NOTA",
+		Status:        models.TravelStatusApproved,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 7, Employee: models.Employee{ID: 7, Name: "Citra"}, Subtotal: 600000},
+			{EmployeeID: 3, Employee: models.Employee{ID: 3, Name: "Andi"}, Subtotal: 600000},
+		},
+	}
+	numbers := map[string]string{}
+	for _, receipt := range BuildKuitansi(request) {
+		numbers[receipt.Employee.Name] = receipt.Number
+	}
+
+	// Loading the employees in another order does not renumber their kuitansi
+	request.TravelRequestEmployees[0], request.TravelRequestEmployees[1] = request.TravelRequestEmployees[1], request.TravelRequestEmployees[0]
+	for _, receipt := range BuildKuitansi(request) {
+		if numbers[receipt.Employee.Name] != receipt.Number {
+			t.Errorf("Expected %s to keep %s, got %s", receipt.Employee.Name, numbers[receipt.Employee.Name], receipt.Number)
+		}
+	}
+	if numbers["Citra"] != "064/0005/DIB/DPEB/NOTA/KWT-7" {
+		t.Errorf("Expected the kuitansi numbered by employee, got %s", numbers["Citra"])
+	}
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Statuses of a number in the numbering journal
+const (
+	JournalStatusUsed = "used" // Terpakai oleh dokumen aktif
+	JournalStatusVoid = "void" // Nomor batal, dokumennya dihapus
+	JournalStatusGap  = "gap"  // Tidak pernah dipakai
+)
+
+var ErrTravelRequestNotDeletable = errors.New("travel request has issued documents and can only be cancelled")
+
+// legacyVoidReason is shown for documents deleted before the void register existed
+const legacyVoidReason = "Dihapus (tanpa catatan pembatalan)"
+
+// NumberJournalEntry is one number of an agenda with what happened to it
+type NumberJournalEntry struct {
+	Sequence    int        `json:"sequence"`
+	Number      string     `json:"number"`
+	Status      string     `json:"status"`
+	DocumentID  uint       `json:"document_id,omitempty"`
+	Date        *time.Time `json:"date,omitempty"` // Tanggal penomoran
+	Description string     `json:"description,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	VoidedBy    string     `json:"voided_by,omitempty"`
+	VoidedAt    *time.Time `json:"voided_at,omitempty"`
+}
+
+// NumberJournal is the buku agenda of one (year, document type, unit code)
+type NumberJournal struct {
+	Year         int                  `json:"year"`
+	Month        int                  `json:"month,omitempty"` // 0 = satu tahun penuh
+	DocumentType string               `json:"document_type"`
+	PositionCode string               `json:"position_code"`
+	LastNumber   int                  `json:"last_number"`
+	UsedCount    int                  `json:"used_count"`
+	VoidCount    int                  `json:"void_count"`
+	GapCount     int                  `json:"gap_count"`
+	Entries      []NumberJournalEntry `json:"entries"`
+}
+
+// JournalDocument is a numbered document as seen by the journal, deleted ones included
+type JournalDocument struct {
+	ID          uint
+	Sequence    int
+	Number      string
+	Date        time.Time
+	Description string
+	Deleted     bool
+}
+
+// NumberRegisterService keeps the register of voided numbers (nomor batal) and
+// builds the numbering journal that auditors use to account for every number.
+type NumberRegisterService struct {
+	repo      *repository.Repository
+	approvals *ApprovalService
+}
+
+func NewNumberRegisterService(repo *repository.Repository) *NumberRegisterService {
+	return &NumberRegisterService{
+		repo:      repo,
+		approvals: NewApprovalService(repo),
+	}
+}
+
+// IsDeletableTravelStatus reports whether a request in status may be deleted. An approved trip
+// has its surat tugas and may have claims, so it is cancelled instead.
+func IsDeletableTravelStatus(status string) bool {
+	switch status {
+	case models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted:
+		return false
+	}
+	return true
+}
+
+// VoidTravelRequest deletes a travel request and records its number as void with the reason,
+// actor and time, so the number stays accounted for in the journal. Requests that were
+// approved, or have a surat tugas or at-cost claims numbered for them, keep their documents
+// and are refused.
+func (s *NumberRegisterService) VoidTravelRequest(id uint, actor, reason string) (*models.VoidedNumber, error) {
+	var voided *models.VoidedNumber
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			return err
+		}
+		if !IsDeletableTravelStatus(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrTravelRequestNotDeletable, request.Status)
+		}
+
+		// A request cancelled after approval still has its letter and claims in their agendas
+		var letters, claims int64
+		if err := tx.Model(&models.SuratTugas{}).Where("travel_request_id = ?", request.ID).Count(&letters).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AtCostClaim{}).Where("travel_request_id = ?", request.ID).Count(&claims).Error; err != nil {
+			return err
+		}
+		if letters > 0 || claims > 0 {
+			return fmt.Errorf("%w: %d surat tugas and %d claims", ErrTravelRequestNotDeletable, letters, claims)
+		}
+
+		voided = &models.VoidedNumber{
+			DocumentType: models.DocTypeNotaPermintaan,
+			DocumentID:   request.ID,
+			Year:         request.NumberYear,
+			PositionCode: utils.ExtractPositionCodeFromRequestNumber(request.RequestNumber),
+			Sequence:     request.NumberSequence,
+			Number:       request.RequestNumber,
+			Reason:       reason,
+			VoidedBy:     actor,
+			VoidedAt:     time.Now(),
+		}
+		if err := tx.Create(voided).Error; err != nil {
+			return fmt.Errorf("failed to record voided number: %w", err)
+		}
+
+		if err := s.approvals.SkipPendingTx(tx, models.ApprovalDocTravelRequest, request.ID); err != nil {
+			return err
+		}
+
+		return tx.Delete(&request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return voided, nil
+}
+
+// GetVoidedNumbers returns the voided numbers of a year
+func (s *NumberRegisterService) GetVoidedNumbers(year int, documentType string) ([]models.VoidedNumber, error) {
+	var voided []models.VoidedNumber
+	err := s.repo.GetDB().Where("year = ? AND document_type = ?", year, documentType).
+		Order("position_code ASC, sequence ASC").
+		Find(&voided).Error
+	return voided, err
+}
+
+// Journal builds the numbering journal of a year, one per unit code. month (1-12) narrows
+// it to the numbers given out in that month; positionCode narrows it to one unit.
+func (s *NumberRegisterService) Journal(year, month int, documentType, positionCode string) ([]NumberJournal, error) {
+	if !IsValidDocumentType(documentType) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDocumentType, documentType)
+	}
+
+	documents, err := s.journalDocuments(year, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	voided, err := s.GetVoidedNumbers(year, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	var sequences []models.NumberSequence
+	if err := s.repo.GetDB().Where("year = ? AND document_type = ?", year, documentType).
+		Find(&sequences).Error; err != nil {
+		return nil, err
+	}
+
+	// Group everything by unit code
+	codes := make(map[string]bool)
+	docsByCode := make(map[string][]JournalDocument)
+	for _, doc := range documents {
+		code := utils.ExtractPositionCodeFromRequestNumber(doc.Number)
+		docsByCode[code] = append(docsByCode[code], doc)
+		codes[code] = true
+	}
+	voidsByCode := make(map[string][]models.VoidedNumber)
+	for _, v := range voided {
+		voidsByCode[v.PositionCode] = append(voidsByCode[v.PositionCode], v)
+		codes[v.PositionCode] = true
+	}
+	lastByCode := make(map[string]int)
+	for _, seq := range sequences {
+		lastByCode[seq.PositionCode] = seq.LastNumber
+		codes[seq.PositionCode] = true
+	}
+
+	positionCode = strings.ToUpper(strings.TrimSpace(positionCode))
+	var sortedCodes []string
+	for code := range codes {
+		if code == "" || (positionCode != "" && code != positionCode) {
+			continue
+		}
+		sortedCodes = append(sortedCodes, code)
+	}
+	sort.Strings(sortedCodes)
+
+	journals := make([]NumberJournal, 0, len(sortedCodes))
+	for _, code := range sortedCodes {
+		journal := BuildNumberJournal(year, documentType, code, lastByCode[code], docsByCode[code], voidsByCode[code])
+		if month > 0 {
+			journal = FilterNumberJournalMonth(journal, month)
+		}
+		journals = append(journals, journal)
+	}
+
+	return journals, nil
+}
+
+// BuildNumberJournal lists every number from 1 up to the highest one given out and marks it
+// as used, void or gap. A deleted document without a void record still counts as void.
+func BuildNumberJournal(year int, documentType, positionCode string, lastNumber int, documents []JournalDocument, voided []models.VoidedNumber) NumberJournal {
+	journal := NumberJournal{
+		Year:         year,
+		DocumentType: documentType,
+		PositionCode: positionCode,
+		LastNumber:   lastNumber,
+	}
+
+	docBySeq := make(map[int]JournalDocument)
+	for _, doc := range documents {
+		if doc.Sequence <= 0 {
+			continue
+		}
+		if existing, ok := docBySeq[doc.Sequence]; ok && !existing.Deleted {
+			continue // Prefer the active document if a number appears twice
+		}
+		docBySeq[doc.Sequence] = doc
+		if doc.Sequence > journal.LastNumber {
+			journal.LastNumber = doc.Sequence
+		}
+	}
+	voidBySeq := make(map[int]models.VoidedNumber)
+	for _, v := range voided {
+		if v.Sequence <= 0 {
+			continue
+		}
+		voidBySeq[v.Sequence] = v
+		if v.Sequence > journal.LastNumber {
+			journal.LastNumber = v.Sequence
+		}
+	}
+
+	journal.Entries = make([]NumberJournalEntry, 0, journal.LastNumber)
+	for seq := 1; seq <= journal.LastNumber; seq++ {
+		entry := NumberJournalEntry{
+			Sequence: seq,
+			Number:   FormatDocumentNumber(journal.DocumentType, year, seq, positionCode),
+			Status:   JournalStatusGap,
+		}
+
+		doc, hasDoc := docBySeq[seq]
+		if hasDoc {
+			date := doc.Date
+			entry.Number = doc.Number
+			entry.DocumentID = doc.ID
+			entry.Date = &date
+			entry.Description = doc.Description
+			entry.Status = JournalStatusUsed
+		}
+
+		if v, ok := voidBySeq[seq]; ok && (!hasDoc || doc.Deleted) {
+			voidedAt := v.VoidedAt
+			entry.Status = JournalStatusVoid
+			entry.Number = v.Number
+			entry.DocumentID = v.DocumentID
+			entry.Reason = v.Reason
+			entry.VoidedBy = v.VoidedBy
+			entry.VoidedAt = &voidedAt
+			if entry.Date == nil {
+				entry.Date = &voidedAt
+			}
+		} else if hasDoc && doc.Deleted {
+			entry.Status = JournalStatusVoid
+			entry.Reason = legacyVoidReason
+		}
+
+		journal.Entries = append(journal.Entries, entry)
+	}
+
+	journal.countStatuses()
+	return journal
+}
+
+// FilterNumberJournalMonth keeps the numbers given out in month, together with the gaps
+// between them. Gaps before the first or after the last number of the month are dropped.
+func FilterNumberJournalMonth(journal NumberJournal, month int) NumberJournal {
+	journal.Month = month
+
+	first, last := -1, -1
+	for i, entry := range journal.Entries {
+		if entry.Date == nil || int(entry.Date.In(utils.JakartaLocation()).Month()) != month {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+
+	if first < 0 {
+		journal.Entries = []NumberJournalEntry{}
+	} else {
+		journal.Entries = journal.Entries[first : last+1]
+	}
+
+	journal.countStatuses()
+	return journal
+}
+
+func (j *NumberJournal) countStatuses() {
+	j.UsedCount, j.VoidCount, j.GapCount = 0, 0, 0
+	for _, entry := range j.Entries {
+		switch entry.Status {
+		case JournalStatusUsed:
+			j.UsedCount++
+		case JournalStatusVoid:
+			j.VoidCount++
+		case JournalStatusGap:
+			j.GapCount++
+		}
+	}
+}
+
+// journalDocuments loads the numbered documents of a year, deleted ones included
+func (s *NumberRegisterService) journalDocuments(year int, documentType string) ([]JournalDocument, error) {
+	db := s.repo.GetDB().Unscoped()
+	var documents []JournalDocument
+
+	switch documentType {
+	case models.DocTypeNotaPermintaan:
+		var requests []models.TravelRequest
+		if err := db.Where("number_year = ?", year).Find(&requests).Error; err != nil {
+			return nil, err
+		}
+		for _, request := range requests {
+			documents = append(documents, JournalDocument{
+				ID:          request.ID,
+				Sequence:    request.NumberSequence,
+				Number:      request.RequestNumber,
+				Date:        request.CreatedAt,
+				Description: fmt.Sprintf("%s - %s", request.Purpose, request.Destination),
+				Deleted:     request.DeletedAt.Valid,
+			})
+		}
+	case models.DocTypeAtCostClaim:
+		var claims []models.AtCostClaim
+		err := db.Preload("TravelRequest", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+			Where("number_year = ?", year).Find(&claims).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, claim := range claims {
+			documents = append(documents, JournalDocument{
+				ID:          claim.ID,
+				Sequence:    claim.NumberSequence,
+				Number:      claim.ClaimNumber,
+				Date:        claim.CreatedAt,
+				Description: fmt.Sprintf("At-cost %s - %s", claim.TravelRequest.RequestNumber, claim.TravelRequest.Destination),
+				Deleted:     claim.DeletedAt.Valid,
+			})
+		}
+	case models.DocTypeSuratTugas:
+		var letters []models.SuratTugas
+		if err := db.Where("number_year = ?", year).Find(&letters).Error; err != nil {
+			return nil, err
+		}
+		requestIDs := make([]uint, 0, len(letters))
+		for _, letter := range letters {
+			requestIDs = append(requestIDs, letter.TravelRequestID)
+		}
+		var requests []models.TravelRequest
+		if err := db.Where("id IN ?", requestIDs).Find(&requests).Error; err != nil {
+			return nil, err
+		}
+		requestByID := make(map[uint]models.TravelRequest, len(requests))
+		for _, request := range requests {
+			requestByID[request.ID] = request
+		}
+		for _, letter := range letters {
+			request := requestByID[letter.TravelRequestID]
+			documents = append(documents, JournalDocument{
+				ID:          letter.ID,
+				Sequence:    letter.NumberSequence,
+				Number:      letter.LetterNumber,
+				Date:        letter.CreatedAt,
+				Description: fmt.Sprintf("Surat Tugas %s - %s", request.RequestNumber, request.Destination),
+				Deleted:     letter.DeletedAt.Valid,
+			})
+		}
+	}
+
+	return documents, nil
+}
+
+// JournalStatusLabel returns the Indonesian label of a journal status
+func JournalStatusLabel(status string) string {
+	switch status {
+	case JournalStatusUsed:
+		return "Terpakai"
+	case JournalStatusVoid:
+		return "Batal"
+	case JournalStatusGap:
+		return "Kosong"
+	}
+	return status
+}
+
+// DocumentTypeLabel returns the Indonesian name of a numbering document type
+func DocumentTypeLabel(documentType string) string {
+	switch documentType {
+	case models.DocTypeNotaPermintaan:
+		return "Nota Permintaan"
+	case models.DocTypeAtCostClaim:
+		return "Klaim At-Cost"
+	case models.DocTypeSuratTugas:
+		return "Surat Tugas"
+	}
+	return documentType
+}
+
+func journalPeriodLabel(journal NumberJournal) string {
+	if journal.Month > 0 {
+		return fmt.Sprintf("%s %d", getMonthName(journal.Month), journal.Year)
+	}
+	return fmt.Sprintf("Tahun %d", journal.Year)
+}
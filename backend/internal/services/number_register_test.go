@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestBuildNumberJournal(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	jan := time.Date(2025, time.January, 10, 9, 0, 0, 0, wib)
+	feb := time.Date(2025, time.February, 3, 9, 0, 0, 0, wib)
+
+	documents := []JournalDocument{
+		{ID: 1, Sequence: 1, Number: "064/0001/DIB/DPEB/NOTA", Date: jan},
+		{ID: 2, Sequence: 2, Number: "064/0002/DIB/DPEB/NOTA", Date: jan, Deleted: true},
+		{ID: 3, Sequence: 3, Number: "064/0003/DIB/DPEB/NOTA", Date: jan, Deleted: true},
+		{ID: 5, Sequence: 5, Number: "064/0005/DIB/DPEB/NOTA", Date: feb},
+	}
+	voided := []models.VoidedNumber{
+		{DocumentID: 2, Sequence: 2, Number: "064/0002/DIB/DPEB/NOTA", Reason: "Salah input", VoidedBy: "admin", VoidedAt: jan},
+	}
+
+	journal := BuildNumberJournal(2025, models.DocTypeNotaPermintaan, "DPEB", 6, documents, voided)
+
+	wantStatus := []string{JournalStatusUsed, JournalStatusVoid, JournalStatusVoid, JournalStatusGap, JournalStatusUsed, JournalStatusGap}
+	if len(journal.Entries) != len(wantStatus) {
+		t.Fatalf("Expected %d entries, got %d", len(wantStatus), len(journal.Entries))
+	}
+	for i, want := range wantStatus {
+		if got := journal.Entries[i].Status; got != want {
+			t.Errorf("Entry %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+
+	if got := journal.Entries[1].Reason; got != "Salah input" {
+		t.Errorf("Expected recorded void reason, got %q", got)
+	}
+	if got := journal.Entries[2].Reason; got != legacyVoidReason {
+		t.Errorf("Expected legacy void reason for unrecorded delete, got %q", got)
+	}
+	if got := journal.Entries[3].Number; got != "064/0004/DIB/DPEB/NOTA/2025" {
+		t.Errorf("Expected gap number to be generated, got %q", got)
+	}
+	if journal.UsedCount != 2 || journal.VoidCount != 2 || journal.GapCount != 2 {
+		t.Errorf("Unexpected counts used=%d void=%d gap=%d", journal.UsedCount, journal.VoidCount, journal.GapCount)
+	}
+
+	february := FilterNumberJournalMonth(journal, 2)
+	if len(february.Entries) != 1 || february.Entries[0].Sequence != 5 {
+		t.Errorf("Expected only number 5 in February, got %+v", february.Entries)
+	}
+
+	january := FilterNumberJournalMonth(journal, 1)
+	if len(january.Entries) != 3 || january.VoidCount != 2 {
+		t.Errorf("Expected numbers 1-3 in January with 2 void, got %d entries and %d void", len(january.Entries), january.VoidCount)
+	}
+}
+
+func TestBuildNumberJournalSuratTugasGap(t *testing.T) {
+	documents := []JournalDocument{
+		{ID: 1, Sequence: 1, Number: "064/0001/DIB/DPEB/ST/2025", Date: time.Date(2025, time.March, 4, 9, 0, 0, 0, time.UTC)},
+	}
+
+	journal := BuildNumberJournal(2025, models.DocTypeSuratTugas, "DPEB", 2, documents, nil)
+
+	if len(journal.Entries) != 2 || journal.Entries[1].Status != JournalStatusGap {
+		t.Fatalf("Expected number 2 to be a gap, got %+v", journal.Entries)
+	}
+	if got := journal.Entries[1].Number; got != "064/0002/DIB/DPEB/ST/2025" {
+		t.Errorf("Expected a surat tugas gap number, got %q", got)
+	}
+}
+
+func TestIsDeletableTravelStatus(t *testing.T) {
+	for _, status := range []string{models.TravelStatusDraft, models.TravelStatusSubmitted, models.TravelStatusRejected, models.TravelStatusCancelled} {
+		if !IsDeletableTravelStatus(status) {
+			t.Errorf("Expected a %s request to be deletable", status)
+		}
+	}
+	for _, status := range []string{models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted} {
+		if IsDeletableTravelStatus(status) {
+			t.Errorf("Expected a %s request to be cancelled instead of deleted", status)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidDocumentType = errors.New("invalid numbering document type")
+	ErrSequenceBelowUsed   = errors.New("sequence cannot be set below a number already used")
+)
+
+// NumberAllocation is a document number handed out by the NumberingService
+type NumberAllocation struct {
+	Year         int    `json:"year"`
+	DocumentType string `json:"document_type"`
+	PositionCode string `json:"position_code"`
+	Sequence     int    `json:"sequence"`
+	Number       string `json:"number"`
+}
+
+// NumberingService allocates document numbers (064/{seq}/DIB/{code}/NOTA/{year}).
+// Each (year, document type, unit code) has its own agenda. Numbers are always taken
+// inside the caller's transaction while holding a row lock on the agenda counter, so
+// concurrent submissions never share a number and a rolled back insert gives its
+// number back instead of leaving a gap.
+type NumberingService struct {
+	repo *repository.Repository
+}
+
+func NewNumberingService(repo *repository.Repository) *NumberingService {
+	return &NumberingService{repo: repo}
+}
+
+// IsValidDocumentType reports whether documentType has its own numbering agenda
+func IsValidDocumentType(documentType string) bool {
+	switch documentType {
+	case models.DocTypeNotaPermintaan, models.DocTypeAtCostClaim, models.DocTypeSuratTugas:
+		return true
+	}
+	return false
+}
+
+// FormatDocumentNumber formats the number of a document of an agenda. Each document type has
+// its own suffix (/NOTA, /KLAIM, /ST) and the agenda year ends the number, so numbers of
+// different agendas never look the same.
+func FormatDocumentNumber(documentType string, year, seq int, positionCode string) string {
+	switch documentType {
+	case models.DocTypeSuratTugas:
+		return utils.GenerateSuratTugasNumber(seq, positionCode, year)
+	case models.DocTypeAtCostClaim:
+		return utils.GenerateClaimNumber(seq, positionCode, year)
+	}
+	return utils.GenerateRequestNumber(seq, positionCode, year)
+}
+
+// NumberingYear returns the agenda year of a document dated at t (WIB)
+func NumberingYear(t time.Time) int {
+	return t.In(utils.JakartaLocation()).Year()
+}
+
+// AllocateTx reserves the next number of the agenda for documentType and positionCode
+// in the year of at. tx must be an open transaction; the counter stays locked until it
+// commits or rolls back. The first allocation of a year creates that year's agenda.
+func (s *NumberingService) AllocateTx(tx *gorm.DB, documentType, positionCode string, at time.Time) (*NumberAllocation, error) {
+	if !IsValidDocumentType(documentType) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDocumentType, documentType)
+	}
+
+	sequence, err := s.lockSequenceTx(tx, NumberingYear(at), documentType, positionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence.LastNumber++
+	if err := tx.Model(sequence).Update("last_number", sequence.LastNumber).Error; err != nil {
+		return nil, fmt.Errorf("failed to update sequence: %w", err)
+	}
+
+	return &NumberAllocation{
+		Year:         sequence.Year,
+		DocumentType: documentType,
+		PositionCode: positionCode,
+		Sequence:     sequence.LastNumber,
+		Number:       FormatDocumentNumber(documentType, sequence.Year, sequence.LastNumber, positionCode),
+	}, nil
+}
+
+// PeekNext returns the number the next allocation would get, without locking or consuming it.
+// Another submission may take it first, so it is only a preview.
+func (s *NumberingService) PeekNext(documentType, positionCode string, at time.Time) (*NumberAllocation, error) {
+	if !IsValidDocumentType(documentType) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDocumentType, documentType)
+	}
+
+	year := NumberingYear(at)
+	var sequence models.NumberSequence
+	err := s.repo.GetDB().
+		Where("year = ? AND document_type = ? AND position_code = ?", year, documentType, positionCode).
+		Limit(1).Find(&sequence).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sequence: %w", err)
+	}
+
+	next := sequence.LastNumber + 1
+	return &NumberAllocation{
+		Year:         year,
+		DocumentType: documentType,
+		PositionCode: positionCode,
+		Sequence:     next,
+		Number:       FormatDocumentNumber(documentType, year, next, positionCode),
+	}, nil
+}
+
+// GetSequences returns the agenda counters of a year
+func (s *NumberingService) GetSequences(year int) ([]models.NumberSequence, error) {
+	var sequences []models.NumberSequence
+	err := s.repo.GetDB().Where("year = ?", year).
+		Order("document_type ASC, position_code ASC").
+		Find(&sequences).Error
+	return sequences, err
+}
+
+// SeedSequence sets the last used number of an agenda, e.g. to continue a paper register.
+// The counter may not go below the highest number already given to a document.
+func (s *NumberingService) SeedSequence(year int, documentType, positionCode string, lastNumber int) (*models.NumberSequence, error) {
+	if !IsValidDocumentType(documentType) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDocumentType, documentType)
+	}
+
+	var sequence *models.NumberSequence
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		sequence, err = s.lockSequenceTx(tx, year, documentType, positionCode)
+		if err != nil {
+			return err
+		}
+
+		used, err := s.highestUsedTx(tx, year, documentType, positionCode)
+		if err != nil {
+			return err
+		}
+		if lastNumber < used {
+			return fmt.Errorf("%w: %d already used", ErrSequenceBelowUsed, used)
+		}
+
+		sequence.LastNumber = lastNumber
+		return tx.Model(sequence).Update("last_number", lastNumber).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sequence, nil
+}
+
+func (s *NumberingService) lockSequenceTx(tx *gorm.DB, year int, documentType, positionCode string) (*models.NumberSequence, error) {
+	// Create the agenda on first use; a concurrent creator makes this a no-op
+	sequence := models.NumberSequence{Year: year, DocumentType: documentType, PositionCode: positionCode}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sequence: %w", err)
+	}
+
+	sequence = models.NumberSequence{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("year = ? AND document_type = ? AND position_code = ?", year, documentType, positionCode).
+		First(&sequence).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock sequence: %w", err)
+	}
+
+	return &sequence, nil
+}
+
+// highestUsedTx returns the highest sequence given to a document of an agenda, deleted ones included
+func (s *NumberingService) highestUsedTx(tx *gorm.DB, year int, documentType, positionCode string) (int, error) {
+	var model interface{}
+	var numberColumn string
+	switch documentType {
+	case models.DocTypeNotaPermintaan:
+		model, numberColumn = &models.TravelRequest{}, "request_number"
+	case models.DocTypeAtCostClaim:
+		model, numberColumn = &models.AtCostClaim{}, "claim_number"
+	case models.DocTypeSuratTugas:
+		model, numberColumn = &models.SuratTugas{}, "letter_number"
+	}
+
+	var highest int
+	err := tx.Unscoped().Model(model).
+		Where("number_year = ? AND "+numberColumn+" LIKE ?", year, "%/DIB/"+positionCode+"/%").
+		Select("COALESCE(MAX(number_sequence), 0)").
+		Scan(&highest).Error
+	return highest, err
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openNumberingTestDB connects to the PostgreSQL database given in TEST_DATABASE_DSN.
+// Row locks are what this test exercises, so it needs a real database and is skipped otherwise.
+func openNumberingTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set, skipping numbering stress test")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Seeding checks the numbers already used by documents, so their tables are needed too
+	if err := db.AutoMigrate(
+		&models.Position{},
+		&models.Employee{},
+		&models.TravelRequest{},
+		&models.AtCostClaim{},
+		&models.NumberSequence{},
+	); err != nil {
+		t.Fatalf("Failed to migrate numbering tables: %v", err)
+	}
+
+	return db
+}
+
+// lastNumber reads the counter of an agenda, 0 when it does not exist yet
+func lastNumber(t *testing.T, db *gorm.DB, year int, documentType, positionCode string) int {
+	var sequence models.NumberSequence
+	err := db.Where("year = ? AND document_type = ? AND position_code = ?", year, documentType, positionCode).
+		Limit(1).Find(&sequence).Error
+	if err != nil {
+		t.Fatalf("Failed to read number sequence: %v", err)
+	}
+	return sequence.LastNumber
+}
+
+func TestFormatDocumentNumber(t *testing.T) {
+	cases := []struct {
+		documentType string
+		year         int
+		want         string
+	}{
+		{models.DocTypeNotaPermintaan, 2025, "064/0001/DIB/DPEB/NOTA/2025"},
+		{models.DocTypeAtCostClaim, 2025, "064/0001/DIB/DPEB/KLAIM/2025"},
+		{models.DocTypeSuratTugas, 2025, "064/0001/DIB/DPEB/ST/2025"},
+		{models.DocTypeNotaPermintaan, 2026, "064/0001/DIB/DPEB/NOTA/2026"},
+	}
+	seen := make(map[string]bool)
+	for _, tc := range cases {
+		got := FormatDocumentNumber(tc.documentType, tc.year, 1, "DPEB")
+		if got != tc.want {
+			t.Errorf("FormatDocumentNumber(%s, %d) = %q, want %q", tc.documentType, tc.year, got, tc.want)
+		}
+		if seen[got] {
+			t.Errorf("Number %q given out by two agendas", got)
+		}
+		seen[got] = true
+		if code := utils.ExtractPositionCodeFromRequestNumber(got); code != "DPEB" {
+			t.Errorf("Expected position code DPEB from %q, got %q", got, code)
+		}
+	}
+}
+
+func TestNumberingServiceConcurrentAllocation(t *testing.T) {
+	db := openNumberingTestDB(t)
+	service := NewNumberingService(repository.NewRepository(db))
+
+	now := time.Now()
+	year := NumberingYear(now)
+	start := lastNumber(t, db, year, models.DocTypeNotaPermintaan, "DPEB")
+
+	const workers = 20
+	const perWorker = 10
+
+	var (
+		mu        sync.Mutex
+		committed []int
+		wg        sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				tx := db.Begin()
+				allocation, err := service.AllocateTx(tx, models.DocTypeNotaPermintaan, "DPEB", now)
+				if err != nil {
+					tx.Rollback()
+					t.Errorf("AllocateTx failed: %v", err)
+					return
+				}
+
+				// Every third allocation simulates a failed document insert
+				if (worker+i)%3 == 0 {
+					tx.Rollback()
+					continue
+				}
+
+				if err := tx.Commit().Error; err != nil {
+					t.Errorf("Commit failed: %v", err)
+					return
+				}
+
+				mu.Lock()
+				committed = append(committed, allocation.Sequence)
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	sort.Ints(committed)
+	for i, seq := range committed {
+		if want := start + i + 1; seq != want {
+			t.Fatalf("Expected gapless unique sequence %d at position %d, got %d", want, i, seq)
+		}
+	}
+
+	if want, got := start+len(committed), lastNumber(t, db, year, models.DocTypeNotaPermintaan, "DPEB"); got != want {
+		t.Errorf("Expected counter %d after rollbacks, got %d", want, got)
+	}
+}
+
+func TestNumberingServiceYearlyAgendas(t *testing.T) {
+	db := openNumberingTestDB(t)
+	service := NewNumberingService(repository.NewRepository(db))
+
+	// A year far away so the agenda is fresh on every run
+	year := 2000 + int(time.Now().UnixNano()%900)
+	db.Where("year IN ?", []int{year, year + 1}).Delete(&models.NumberSequence{})
+
+	// 31 December 23:30 WIB is still the old year, 1 January 00:30 WIB the new one
+	wib := time.FixedZone("WIB", 7*60*60)
+	lastDay := time.Date(year, time.December, 31, 23, 30, 0, 0, wib)
+	newYear := time.Date(year+1, time.January, 1, 0, 30, 0, 0, wib)
+
+	allocate := func(documentType string, at time.Time) *NumberAllocation {
+		var allocation *NumberAllocation
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			allocation, err = service.AllocateTx(tx, documentType, "DPEB", at)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("AllocateTx failed: %v", err)
+		}
+		return allocation
+	}
+
+	allocate(models.DocTypeNotaPermintaan, lastDay)
+	if got := allocate(models.DocTypeNotaPermintaan, lastDay); got.Year != year || got.Sequence != 2 {
+		t.Errorf("Expected %d sequence 2, got %d sequence %d", year, got.Year, got.Sequence)
+	}
+	if got := allocate(models.DocTypeAtCostClaim, lastDay); got.Sequence != 1 {
+		t.Errorf("Expected at-cost agenda to start at 1, got %d", got.Sequence)
+	}
+	if got := allocate(models.DocTypeNotaPermintaan, newYear); got.Year != year+1 || got.Sequence != 1 {
+		t.Errorf("Expected rollover to %d sequence 1, got %d sequence %d", year+1, got.Year, got.Sequence)
+	}
+
+	if _, err := service.SeedSequence(year+1, models.DocTypeNotaPermintaan, "DPEB", 50); err != nil {
+		t.Fatalf("SeedSequence failed: %v", err)
+	}
+	if got := allocate(models.DocTypeNotaPermintaan, newYear); got.Sequence != 51 {
+		t.Errorf("Expected seeded agenda to continue at 51, got %d", got.Sequence)
+	}
+
+	if _, err := service.SeedSequence(year+1, models.DocTypeNotaPermintaan, "DPEB", 10); err != nil {
+		t.Fatalf("Lowering an agenda without documents should be allowed: %v", err)
+	}
+	if _, err := service.SeedSequence(year+1, "unknown", "DPEB", 10); !errors.Is(err, ErrInvalidDocumentType) {
+		t.Errorf("Expected ErrInvalidDocumentType, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+)
+
+var ErrInvalidTravelTime = errors.New("invalid travel time")
+
+// AllowanceDays is how much of a trip is paid: whole days at the full daily rate and the
+// departure and return day at a percentage of it. A same-day trip only has a departure day.
+type AllowanceDays struct {
+	CalendarDays        int  `json:"calendar_days"`
+	FullDays            int  `json:"full_days"`
+	DepartureDayPercent int  `json:"departure_day_percent"`
+	ReturnDayPercent    int  `json:"return_day_percent"`
+	Partial             bool `json:"partial"`    // Dihitung dengan aturan hari parsial
+	ShortTrip           bool `json:"short_trip"` // Perjalanan satu hari di bawah batas jam
+}
+
+// FullAllowanceDays pays every calendar day of a trip at the full rate
+func FullAllowanceDays(days int) AllowanceDays {
+	return AllowanceDays{CalendarDays: days, FullDays: days}
+}
+
+// PaidPercent returns the paid days times 100, e.g. 275 for 2,75 days
+func (d AllowanceDays) PaidPercent() int {
+	return d.FullDays*100 + d.DepartureDayPercent + d.ReturnDayPercent
+}
+
+// Amount returns the allowance of the trip at a daily rate
+func (d AllowanceDays) Amount(rate int) int {
+	return rate * d.PaidPercent() / 100
+}
+
+// ParseTravelTime parses a time of day in HH:MM. An empty string is no time.
+func ParseTravelTime(value string) (time.Duration, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %q, use HH:MM", ErrInvalidTravelTime, value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true, nil
+}
+
+// ValidateTravelTimes checks both times and, for a same-day trip, that the traveller returns
+// after departing
+func ValidateTravelTimes(departureDate, returnDate time.Time, departureTime, returnTime string) error {
+	departure, hasDeparture, err := ParseTravelTime(departureTime)
+	if err != nil {
+		return err
+	}
+	ret, hasReturn, err := ParseTravelTime(returnTime)
+	if err != nil {
+		return err
+	}
+	if hasDeparture && hasReturn && dateOnly(departureDate).Equal(dateOnly(returnDate)) && ret <= departure {
+		return fmt.Errorf("%w: return time must be after departure time on a same-day trip", ErrInvalidTravelTime)
+	}
+	return nil
+}
+
+// localTime returns the clock time of day on the calendar date of date, in the time zone of date
+func localTime(date time.Time, timeOfDay time.Duration) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()).Add(timeOfDay)
+}
+
+// tripHours returns the hours between departure and return, false when either time is missing.
+// Each time is read on the clock of the place of its date.
+func tripHours(departureDate, returnDate time.Time, departureTime, returnTime string) (float64, bool) {
+	departure, hasDeparture, err := ParseTravelTime(departureTime)
+	if err != nil || !hasDeparture {
+		return 0, false
+	}
+	ret, hasReturn, err := ParseTravelTime(returnTime)
+	if err != nil || !hasReturn {
+		return 0, false
+	}
+	return localTime(returnDate, ret).Sub(localTime(departureDate, departure)).Hours(), true
+}
+
+// PartialAllowanceDays applies a partial day rule to a trip. Without a rule every day is paid in
+// full. A same-day trip is one full day unless both times are known and it lasts fewer hours than
+// the short trip limit of the rule.
+func PartialAllowanceDays(rule *models.PartialDayRule, departureDate, returnDate time.Time, departureTime, returnTime string) AllowanceDays {
+	days := repository.CalculateDurationDays(departureDate, returnDate)
+	if rule == nil {
+		return FullAllowanceDays(days)
+	}
+
+	result := AllowanceDays{CalendarDays: days, Partial: true}
+	if days == 1 {
+		result.DepartureDayPercent = 100
+		if rule.ShortTripMaxHours > 0 {
+			if hours, ok := tripHours(departureDate, returnDate, departureTime, returnTime); ok && hours < float64(rule.ShortTripMaxHours) {
+				result.DepartureDayPercent = rule.ShortTripPercent
+				result.ShortTrip = true
+			}
+		}
+		return result
+	}
+
+	result.FullDays = days - 2
+	result.DepartureDayPercent = rule.DepartureDayPercent
+	result.ReturnDayPercent = rule.ReturnDayPercent
+	return result
+}
+
+// RequestAllowanceDays rebuilds the paid days of a saved single-destination request
+func RequestAllowanceDays(request *models.TravelRequest) AllowanceDays {
+	if !request.PartialDays {
+		return FullAllowanceDays(request.DurationDays)
+	}
+	days := AllowanceDays{
+		CalendarDays:        request.DurationDays,
+		DepartureDayPercent: request.DepartureDayPercent,
+		ReturnDayPercent:    request.ReturnDayPercent,
+		Partial:             true,
+	}
+	if request.DurationDays > 1 {
+		days.FullDays = request.DurationDays - 2
+	} else {
+		days.ShortTrip = request.DepartureDayPercent < 100
+	}
+	return days
+}
+
+// ApplyAllowanceDays stores the paid days on a request
+func ApplyAllowanceDays(request *models.TravelRequest, days AllowanceDays) {
+	request.PartialDays = days.Partial
+	request.DepartureDayPercent = 0
+	request.ReturnDayPercent = 0
+	request.PaidDayPercent = 0
+	if days.Partial {
+		request.DepartureDayPercent = days.DepartureDayPercent
+		request.ReturnDayPercent = days.ReturnDayPercent
+		request.PaidDayPercent = days.PaidPercent()
+	}
+}
+
+// FormatPaidDays renders paid days times 100 as a day count, e.g. 275 as "2,75"
+func FormatPaidDays(percent int) string {
+	if percent%100 == 0 {
+		return fmt.Sprintf("%d", percent/100)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d,%02d", percent/100, percent%100), "0")
+}
+
+// DescribeAllowanceDays renders the breakdown of the paid days for the Nota Permintaan,
+// e.g. "1 hari penuh + hari berangkat 100% + hari kembali 75% = 2,75 hari"
+func DescribeAllowanceDays(days AllowanceDays) string {
+	if !days.Partial {
+		return fmt.Sprintf("%d hari penuh", days.FullDays)
+	}
+	if days.CalendarDays <= 1 {
+		label := "Perjalanan satu hari"
+		if days.ShortTrip {
+			label = "Perjalanan singkat satu hari"
+		}
+		return fmt.Sprintf("%s %d%% = %s hari", label, days.DepartureDayPercent, FormatPaidDays(days.PaidPercent()))
+	}
+	return fmt.Sprintf("%d hari penuh + hari berangkat %d%% + hari kembali %d%% = %s hari",
+		days.FullDays, days.DepartureDayPercent, days.ReturnDayPercent, FormatPaidDays(days.PaidPercent()))
+}
+
+// PartialDayService looks up the partial day rule of a trip
+type PartialDayService struct {
+	repo *repository.Repository
+}
+
+func NewPartialDayService(repo *repository.Repository) *PartialDayService {
+	return &PartialDayService{repo: repo}
+}
+
+// AllowanceDays returns the paid days of a trip under the active rule of its destination type
+func (s *PartialDayService) AllowanceDays(destinationType string, departureDate, returnDate time.Time, departureTime, returnTime string) (AllowanceDays, error) {
+	rules, err := s.repo.GetActivePartialDayRules()
+	if err != nil {
+		return AllowanceDays{}, fmt.Errorf("failed to load partial day rules: %w", err)
+	}
+	var rule *models.PartialDayRule
+	if active, ok := rules[destinationType]; ok {
+		rule = &active
+	}
+	return PartialAllowanceDays(rule, departureDate, returnDate, departureTime, returnTime), nil
+}
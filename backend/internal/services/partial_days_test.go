@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestPartialAllowanceDays(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	rule := &models.PartialDayRule{ShortTripMaxHours: 8, ShortTripPercent: 50, DepartureDayPercent: 100, ReturnDayPercent: 75}
+
+	days := PartialAllowanceDays(rule, date(3), date(5), "07:00", "18:00")
+	if days.FullDays != 1 || days.PaidPercent() != 275 || days.Amount(200000) != 550000 {
+		t.Errorf("Expected 2,75 paid days for a three-day trip, got %+v", days)
+	}
+
+	days = PartialAllowanceDays(rule, date(3), date(3), "08:00", "13:30")
+	if !days.ShortTrip || days.PaidPercent() != 50 {
+		t.Errorf("Expected a short trip at 50%%, got %+v", days)
+	}
+	if days = PartialAllowanceDays(rule, date(3), date(3), "", ""); days.ShortTrip || days.PaidPercent() != 100 {
+		t.Errorf("Expected a full day when times are unknown, got %+v", days)
+	}
+	if days = PartialAllowanceDays(nil, date(3), date(5), "07:00", "18:00"); days.Partial || days.PaidPercent() != 300 {
+		t.Errorf("Expected full days without a rule, got %+v", days)
+	}
+}
+
+func TestPartialDaysFormatting(t *testing.T) {
+	if got := FormatPaidDays(275); got != "2,75" {
+		t.Errorf("Expected 2,75, got %s", got)
+	}
+	if got := FormatPaidDays(250); got != "2,5" {
+		t.Errorf("Expected 2,5, got %s", got)
+	}
+	if got := FormatPaidDays(300); got != "3" {
+		t.Errorf("Expected 3, got %s", got)
+	}
+
+	request := &models.TravelRequest{DurationDays: 3, PartialDays: true, DepartureDayPercent: 100, ReturnDayPercent: 75, PaidDayPercent: 275}
+	expected := "1 hari penuh + hari berangkat 100% + hari kembali 75% = 2,75 hari"
+	if got := DescribeAllowanceDays(RequestAllowanceDays(request)); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestValidateTravelTimes(t *testing.T) {
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	if err := ValidateTravelTimes(day, day, "25:00", ""); !errors.Is(err, ErrInvalidTravelTime) {
+		t.Errorf("Expected ErrInvalidTravelTime for an invalid time, got %v", err)
+	}
+	if err := ValidateTravelTimes(day, day, "14:00", "09:00"); !errors.Is(err, ErrInvalidTravelTime) {
+		t.Errorf("Expected ErrInvalidTravelTime when returning before departing, got %v", err)
+	}
+	if err := ValidateTravelTimes(day, day.AddDate(0, 0, 1), "14:00", "09:00"); err != nil {
+		t.Errorf("Expected an overnight trip to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"perjalanan-dinas/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrParticipantRateMissing = errors.New("participant receiving allowance needs a rate position")
+
+// participantRate returns the daily rate of a paid participant for a zone
+func (ac *AllowanceCalculator) participantRate(participant *models.TravelParticipant, zone RateZone) (int, error) {
+	if participant.RatePosition == nil {
+		return 0, fmt.Errorf("%w: %s", ErrParticipantRateMissing, participant.Name)
+	}
+	return ac.ZoneRate(*participant.RatePosition, zone)
+}
+
+// CalculateParticipants fills the allowance of the external participants of a single-destination
+// trip. Only participants marked as receiving allowance are paid, at the rates of the position
+// they are ranked with. Returns the total paid to participants.
+func (ac *AllowanceCalculator) CalculateParticipants(participants []models.TravelParticipant, destinationType string, durationDays int) (int, error) {
+	return ac.CalculateParticipantsZone(participants, RateZone{DestinationType: destinationType}, durationDays)
+}
+
+// CalculateParticipantsZone is CalculateParticipants for a destination that may have a tier
+func (ac *AllowanceCalculator) CalculateParticipantsZone(participants []models.TravelParticipant, zone RateZone, durationDays int) (int, error) {
+	return ac.CalculateParticipantsZoneDays(participants, zone, FullAllowanceDays(durationDays))
+}
+
+// CalculateParticipantsZoneDays is CalculateParticipantsZone for a trip whose departure and
+// return day may be paid at a percentage of the daily rate
+func (ac *AllowanceCalculator) CalculateParticipantsZoneDays(participants []models.TravelParticipant, zone RateZone, days AllowanceDays) (int, error) {
+	total := 0
+	for i := range participants {
+		participant := &participants[i]
+		participant.DurationDays = days.CalendarDays
+		participant.DailyRate, participant.Subtotal = 0, 0
+		if !participant.ReceivesAllowance {
+			continue
+		}
+
+		rate, err := ac.participantRate(participant, zone)
+		if err != nil {
+			return 0, err
+		}
+		participant.DailyRate = rate
+		participant.Subtotal = days.Amount(rate)
+		total += participant.Subtotal
+	}
+	return total, nil
+}
+
+// CalculateItineraryParticipants is CalculateParticipants for a multi-leg trip. Paid participants
+// get an allowance row on every leg after the employees; legs must already be calculated.
+func (ac *AllowanceCalculator) CalculateItineraryParticipants(participants []models.TravelParticipant, legs []models.TravelLeg) (int, error) {
+	total := 0
+	for i := range participants {
+		participant := &participants[i]
+		participant.DailyRate, participant.DurationDays, participant.Subtotal = 0, 0, 0
+		for _, leg := range legs {
+			participant.DurationDays += leg.AllowanceDays
+		}
+		if !participant.ReceivesAllowance {
+			continue
+		}
+
+		for j := range legs {
+			rate, err := ac.participantRate(participant, LegZone(legs[j]))
+			if err != nil {
+				return 0, err
+			}
+			subtotal := rate * legs[j].AllowanceDays
+			legs[j].Allowances = append(legs[j].Allowances, models.TravelLegAllowance{
+				Participant: participant,
+				DailyRate:   rate,
+				Days:        legs[j].AllowanceDays,
+				Subtotal:    subtotal,
+			})
+			participant.Subtotal += subtotal
+		}
+		total += participant.Subtotal
+	}
+	return total, nil
+}
+
+// CreateParticipantsTx saves the external participants of a request
+func CreateParticipantsTx(tx *gorm.DB, requestID uint, participants []models.TravelParticipant) error {
+	for i := range participants {
+		participants[i].ID = 0
+		participants[i].TravelRequestID = requestID
+		if err := tx.Omit("RatePosition").Create(&participants[i]).Error; err != nil {
+			return fmt.Errorf("failed to create participant: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReplaceParticipantsTx replaces the external participants of a request
+func ReplaceParticipantsTx(tx *gorm.DB, requestID uint, participants []models.TravelParticipant) error {
+	if err := tx.Where("travel_request_id = ?", requestID).Delete(&models.TravelParticipant{}).Error; err != nil {
+		return fmt.Errorf("failed to remove participants: %w", err)
+	}
+	return CreateParticipantsTx(tx, requestID, participants)
+}
+
+// ParticipantDescription renders the role and institution of a participant for the
+// position column of the Nota and Berita Acara, e.g. "Konsultan - PT Solusi Data"
+func ParticipantDescription(participant models.TravelParticipant) string {
+	if participant.Role == "" {
+		return participant.Institution
+	}
+	return participant.Role + " - " + participant.Institution
+}
+
+// describeParticipants renders the participants and their allowance for the revision history,
+// e.g. "Andi (PT Solusi Data) uang harian 750000"
+func describeParticipants(request *models.TravelRequest) string {
+	parts := make([]string, 0, len(request.Participants))
+	for _, participant := range request.Participants {
+		part := fmt.Sprintf("%s (%s)", participant.Name, participant.Institution)
+		if participant.ReceivesAllowance {
+			part += fmt.Sprintf(" uang harian %d", participant.Subtotal)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
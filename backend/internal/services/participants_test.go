@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestCalculateParticipants(t *testing.T) {
+	officer := &models.Position{AllowanceInProvince: 100000, AllowanceOutsideProvince: 200000}
+	participants := []models.TravelParticipant{
+		{Name: "Andi", Institution: "PT Solusi Data", ReceivesAllowance: true, RatePosition: officer},
+		{Name: "Sari", Institution: "Divisi TI"},
+	}
+
+	total, err := NewAllowanceCalculator().CalculateParticipants(participants, "outside_province", 3)
+	if err != nil {
+		t.Fatalf("CalculateParticipants failed: %v", err)
+	}
+	if total != 600000 || participants[0].Subtotal != 600000 || participants[0].DailyRate != 200000 {
+		t.Errorf("Expected paid participant 3 x 200000, got %d x %d (total %d)", participants[0].DurationDays, participants[0].DailyRate, total)
+	}
+	if participants[1].Subtotal != 0 || participants[1].DurationDays != 3 {
+		t.Errorf("Expected unpaid participant to travel 3 days without allowance, got %+v", participants[1])
+	}
+
+	unranked := []models.TravelParticipant{{Name: "Budi", ReceivesAllowance: true}}
+	if _, err := NewAllowanceCalculator().CalculateParticipants(unranked, "in_province", 1); !errors.Is(err, ErrParticipantRateMissing) {
+		t.Errorf("Expected ErrParticipantRateMissing, got %v", err)
+	}
+}
+
+func TestCalculateItineraryParticipants(t *testing.T) {
+	legs := testItinerary()
+	calc := NewAllowanceCalculator()
+	if _, _, err := calc.CalculateItinerary(nil, legs); err != nil {
+		t.Fatalf("CalculateItinerary failed: %v", err)
+	}
+
+	participants := []models.TravelParticipant{
+		{Name: "Andi", Institution: "PT Solusi Data", ReceivesAllowance: true,
+			RatePosition: &models.Position{AllowanceInProvince: 100000, AllowanceOutsideProvince: 200000}},
+	}
+	total, err := calc.CalculateItineraryParticipants(participants, legs)
+	if err != nil {
+		t.Fatalf("CalculateItineraryParticipants failed: %v", err)
+	}
+	if total != 600000 || participants[0].DurationDays != 4 {
+		t.Errorf("Expected 4 days and 600000, got %d days and %d", participants[0].DurationDays, total)
+	}
+	if len(legs[1].Allowances) != 1 || legs[1].Allowances[0].Participant != &participants[0] {
+		t.Errorf("Expected leg allowance to refer to the participant, got %+v", legs[1].Allowances)
+	}
+}
+
+func TestCalculateAmendmentParticipants(t *testing.T) {
+	request := &models.TravelRequest{
+		DestinationType: "in_province",
+		DepartureDate:   time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+		ReturnDate:      time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC),
+		DurationDays:    2,
+		TotalAllowance:  200000,
+		Participants: []models.TravelParticipant{
+			{ID: 3, Name: "Andi", ReceivesAllowance: true, DailyRate: 100000, DurationDays: 2, Subtotal: 200000},
+			{ID: 4, Name: "Sari", DurationDays: 2},
+		},
+	}
+
+	amendment, err := CalculateAmendment(request, time.Date(2025, 5, 7, 0, 0, 0, 0, time.UTC), "", nil, NewAllowanceCalculator())
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if len(amendment.Participants) != 1 || amendment.Participants[0].Delta != 100000 {
+		t.Fatalf("Expected one paid participant with delta 100000, got %+v", amendment.Participants)
+	}
+	if amendment.NewTotalAllowance != 300000 {
+		t.Errorf("Expected new total 300000, got %d", amendment.NewTotalAllowance)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/utils"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
@@ -18,145 +19,37 @@ func NewPDFGenerator() *PDFGenerator {
 // GenerateNotaPermintaan generates the "Nota Permintaan Surat Tugas Perjalanan Dinas" PDF
 func (pg *PDFGenerator) GenerateNotaPermintaan(request *models.TravelRequest) ([]byte, error) {
 	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Add logos at the top
-	// BPD logo on the left
-	pdf.Image("assets/images/bpd.png", 15, 10, 20, 0, false, "", 0, "")
-	// Bank Jatim logo on the right
-	pdf.Image("assets/images/bank jatim.png", 155, 10, 40, 0, false, "", 0, "")
-
-	// Title
-	pdf.SetY(35)
-	pdf.SetFont("Arial", "B", 14)
-	pdf.CellFormat(0, 7, "NOTA PERMINTAAN", "", 1, "C", false, 0, "")
-	pdf.CellFormat(0, 7, "SURAT TUGAS PERJALANAN DINAS", "", 1, "C", false, 0, "")
-	pdf.Ln(5)
-
-	// Document details
-	pdf.SetFont("Arial", "", 11)
-	pdf.CellFormat(30, 6, "Nomor", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.RequestNumber, "", 1, "L", false, 0, "")
-
-	pdf.CellFormat(30, 6, "Kepada", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, "Divisi Human Capital", "", 1, "L", false, 0, "")
-
-	pdf.CellFormat(30, 6, "Dari", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, "Divisi Digital Banking", "", 1, "L", false, 0, "")
-
-	pdf.CellFormat(30, 6, "Perihal", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, "Permohonan Surat Perjalanan Dinas", "", 1, "L", false, 0, "")
-	pdf.Ln(5)
-
-	// Body text
-	pdf.MultiCell(0, 6, "Dengan ini kami mohon bantuan Saudara untuk membuatkan Surat Tugas Perjalanan Dinas sehubungan dengan penugasan kepada:", "", "L", false)
-	pdf.Ln(2)
-
-	// Employee table
-	pdf.SetFont("Arial", "B", 10)
-	pdf.SetFillColor(240, 240, 240)
-	pdf.CellFormat(10, 8, "NO", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(30, 8, "NIP", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(60, 8, "NAMA", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(80, 8, "JABATAN", "1", 1, "C", true, 0, "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
 
-	pdf.SetFont("Arial", "", 9)
-	empColWidths := []float64{10, 30, 60, 80}
-	for i, empRel := range request.TravelRequestEmployees {
-		drawEmployeeRow(pdf, i+1, empRel.Employee.NIP, empRel.Employee.Name, empRel.Employee.Position.Title, empColWidths, 9)
+	if err := pg.addNotaPermintaanPage(pdf, request); err != nil {
+		return nil, err
 	}
-	pdf.Ln(5)
-
-	// Travel details
-	pdf.SetFont("Arial", "", 11)
-
-	// Maksud perjalanan dinas
-	pdf.SetFont("Arial", "B", 11)
-	pdf.Cell(5, 6, "")
-	pdf.Cell(5, 6, "-")
-	pdf.SetFont("Arial", "", 11)
-	pdf.CellFormat(60, 6, "Maksud perjalanan dinas", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.MultiCell(0, 6, request.Purpose, "", "L", false)
 
-	// Tempat berangkat dan tujuan
-	pdf.Cell(5, 6, "")
-	pdf.Cell(5, 6, "-")
-	pdf.CellFormat(60, 6, "Tempat berangkat", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.DeparturePlace, "", 1, "L", false, 0, "")
-
-	pdf.Cell(10, 6, "")
-	pdf.CellFormat(60, 6, "Tempat tujuan", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.Destination, "", 1, "L", false, 0, "")
-
-	// Lama perjalanan dinas
-	pdf.Cell(5, 6, "")
-	pdf.Cell(5, 6, "-")
-	pdf.CellFormat(60, 6, "Lama perjalanan dinas", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, fmt.Sprintf("%d (%s) hari", request.DurationDays, numberToWords(request.DurationDays)), "", 1, "L", false, 0, "")
-
-	pdf.Cell(10, 6, "")
-	pdf.CellFormat(60, 6, "Tanggal berangkat", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.DepartureDate.Format("02 January 2006"), "", 1, "L", false, 0, "")
-
-	pdf.Cell(10, 6, "")
-	pdf.CellFormat(60, 6, "Tanggal kembali", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.ReturnDate.Format("02 January 2006"), "", 1, "L", false, 0, "")
-	pdf.Ln(2)
-
-	// Angkutan yang digunakan
-	pdf.Cell(5, 6, "")
-	pdf.Cell(5, 6, "-")
-	pdf.CellFormat(60, 6, "Angkutan yang digunakan", "", 0, "L", false, 0, "")
-	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.Transportation, "", 1, "L", false, 0, "")
-	pdf.Ln(8)
-
-	// Closing
-	pdf.MultiCell(0, 6, "Atas perhatian dan kerjasamanya disampaikan terima kasih.", "", "L", false)
-	pdf.Ln(10)
-
-	// Signature section - right aligned with consistent positioning
-	x2 := 110.0
-	pdf.SetX(x2)
-	pdf.CellFormat(90, 6, fmt.Sprintf("Surabaya, %s", time.Now().Format("02 January 2006")), "", 1, "C", false, 0, "")
-	pdf.SetX(x2)
-	pdf.CellFormat(90, 6, "DIVISI DIGITAL BANKING", "", 1, "C", false, 0, "")
-	pdf.Ln(20)
-
-	// Use representative from TravelReport, fallback to defaults if not available
-	repName := "M. MACHFUD HIDAYAT"
-	repPosition := "Vice President"
-	if request.TravelReport != nil {
-		repName = request.TravelReport.RepresentativeName
-		repPosition = request.TravelReport.RepresentativePosition
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	pdf.SetFont("Arial", "BU", 11)
-	pdf.SetX(x2)
-	pdf.CellFormat(90, 6, repName, "", 1, "C", false, 0, "")
-	pdf.SetFont("Arial", "", 11)
-	pdf.SetX(x2)
-	pdf.CellFormat(90, 6, repPosition, "", 1, "C", false, 0, "")
-	pdf.Ln(10)
+// GenerateDraftNotaPermintaan generates a preview of the Nota Permintaan for a request that
+// has not been submitted yet, watermarked "DRAFT" on every page
+func (pg *PDFGenerator) GenerateDraftNotaPermintaan(request *models.TravelRequest) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetHeaderFunc(func() {
+		drawWatermark(pdf, "DRAFT")
+	})
 
-	// Tindasan
-	pdf.SetFont("Arial", "", 8)
-	pdf.CellFormat(0, 6, "Tindasan :", "", 1, "L", false, 0, "")
-	pdf.CellFormat(0, 6, "- Arsip", "", 1, "L", false, 0, "")
+	if err := pg.addNotaPermintaanPage(pdf, request); err != nil {
+		return nil, err
+	}
 
 	var buf bytes.Buffer
-	err := pdf.Output(&buf)
-	if err != nil {
+	if err := pdf.Output(&buf); err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
@@ -178,7 +71,7 @@ func (pg *PDFGenerator) GenerateBeritaAcara(request *models.TravelRequest, repor
 	pdf.SetFont("Arial", "B", 14)
 	pdf.CellFormat(0, 7, "BERITA ACARA PERJALANAN DINAS", "", 1, "C", false, 0, "")
 	pdf.SetFont("Arial", "", 11)
-	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", report.ReportNumber), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", utils.WithRevision(report.ReportNumber, request.Revision)), "", 1, "C", false, 0, "")
 	pdf.Ln(5)
 
 	// Opening text
@@ -199,6 +92,7 @@ func (pg *PDFGenerator) GenerateBeritaAcara(request *models.TravelRequest, repor
 	for i, empRel := range request.TravelRequestEmployees {
 		drawEmployeeRow(pdf, i+1, empRel.Employee.NIP, empRel.Employee.Name, empRel.Employee.Position.Title, empColWidths, 9)
 	}
+	drawParticipantRows(pdf, len(request.TravelRequestEmployees), request.Participants, empColWidths, 9)
 	pdf.Ln(3)
 
 	// Travel details with numbering
@@ -242,6 +136,14 @@ func (pg *PDFGenerator) GenerateBeritaAcara(request *models.TravelRequest, repor
 	pdf.CellFormat(60, 6, "Angkutan yang digunakan", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
 	pdf.CellFormat(0, 6, request.Transportation, "", 1, "L", false, 0, "")
+
+	// 5. Rute perjalanan multi-leg
+	if len(request.Legs) > 0 {
+		pdf.CellFormat(10, 6, "5.", "", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 6, "Rute perjalanan", "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 1, "L", false, 0, "")
+		drawItineraryTable(pdf, request.Legs)
+	}
 	pdf.Ln(5)
 
 	// Closing
@@ -349,7 +251,7 @@ func (pg *PDFGenerator) addNotaPermintaanPage(pdf *gofpdf.Fpdf, request *models.
 	pdf.SetFont("Arial", "", 11)
 	pdf.CellFormat(30, 6, "Nomor", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.RequestNumber, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, utils.WithRevision(request.RequestNumber, request.Revision), "", 1, "L", false, 0, "")
 
 	pdf.CellFormat(30, 6, "Kepada", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
@@ -381,6 +283,7 @@ func (pg *PDFGenerator) addNotaPermintaanPage(pdf *gofpdf.Fpdf, request *models.
 	for i, empRel := range request.TravelRequestEmployees {
 		drawEmployeeRow(pdf, i+1, empRel.Employee.NIP, empRel.Employee.Name, empRel.Employee.Position.Title, empColWidths, 9)
 	}
+	drawParticipantRows(pdf, len(request.TravelRequestEmployees), request.Participants, empColWidths, 9)
 	pdf.Ln(5)
 
 	// Travel details
@@ -417,12 +320,12 @@ func (pg *PDFGenerator) addNotaPermintaanPage(pdf *gofpdf.Fpdf, request *models.
 	pdf.Cell(10, 6, "")
 	pdf.CellFormat(60, 6, "Tanggal berangkat", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.DepartureDate.Format("02 January 2006"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, withTravelTime(request.DepartureDate.Format("02 January 2006"), request.DepartureTime), "", 1, "L", false, 0, "")
 
 	pdf.Cell(10, 6, "")
 	pdf.CellFormat(60, 6, "Tanggal kembali", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
-	pdf.CellFormat(0, 6, request.ReturnDate.Format("02 January 2006"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, withTravelTime(request.ReturnDate.Format("02 January 2006"), request.ReturnTime), "", 1, "L", false, 0, "")
 	pdf.Ln(2)
 
 	// Angkutan yang digunakan
@@ -431,6 +334,28 @@ func (pg *PDFGenerator) addNotaPermintaanPage(pdf *gofpdf.Fpdf, request *models.
 	pdf.CellFormat(60, 6, "Angkutan yang digunakan", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
 	pdf.CellFormat(0, 6, request.Transportation, "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	// Rute perjalanan multi-leg
+	if len(request.Legs) > 0 {
+		pdf.Cell(5, 6, "")
+		pdf.Cell(5, 6, "-")
+		pdf.CellFormat(60, 6, "Rute perjalanan", "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 1, "L", false, 0, "")
+		drawItineraryTable(pdf, request.Legs)
+		pdf.Ln(2)
+	}
+
+	// Uang harian per pegawai
+	pdf.Cell(5, 6, "")
+	pdf.Cell(5, 6, "-")
+	pdf.CellFormat(60, 6, "Uang harian perjalanan dinas", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 1, "L", false, 0, "")
+	if len(request.Legs) > 0 {
+		pg.drawItineraryAllowanceTable(pdf, request)
+	} else {
+		pg.drawAllowanceTable(pdf, request)
+	}
 	pdf.Ln(8)
 
 	// Closing
@@ -484,7 +409,7 @@ func (pg *PDFGenerator) addBeritaAcaraPage(pdf *gofpdf.Fpdf, request *models.Tra
 	pdf.SetFont("Arial", "B", 14)
 	pdf.CellFormat(0, 7, "BERITA ACARA PERJALANAN DINAS", "", 1, "C", false, 0, "")
 	pdf.SetFont("Arial", "", 11)
-	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", report.ReportNumber), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", utils.WithRevision(report.ReportNumber, request.Revision)), "", 1, "C", false, 0, "")
 	pdf.Ln(5)
 
 	// Opening text
@@ -505,6 +430,7 @@ func (pg *PDFGenerator) addBeritaAcaraPage(pdf *gofpdf.Fpdf, request *models.Tra
 	for i, empRel := range request.TravelRequestEmployees {
 		drawEmployeeRow(pdf, i+1, empRel.Employee.NIP, empRel.Employee.Name, empRel.Employee.Position.Title, empColWidths, 9)
 	}
+	drawParticipantRows(pdf, len(request.TravelRequestEmployees), request.Participants, empColWidths, 9)
 	pdf.Ln(3)
 
 	// Travel details with numbering
@@ -548,6 +474,14 @@ func (pg *PDFGenerator) addBeritaAcaraPage(pdf *gofpdf.Fpdf, request *models.Tra
 	pdf.CellFormat(60, 6, "Angkutan yang digunakan", "", 0, "L", false, 0, "")
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
 	pdf.CellFormat(0, 6, request.Transportation, "", 1, "L", false, 0, "")
+
+	// 5. Rute perjalanan multi-leg
+	if len(request.Legs) > 0 {
+		pdf.CellFormat(10, 6, "5.", "", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 6, "Rute perjalanan", "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 1, "L", false, 0, "")
+		drawItineraryTable(pdf, request.Legs)
+	}
 	pdf.Ln(5)
 
 	// Closing
@@ -628,8 +562,9 @@ func (pg *PDFGenerator) addBeritaAcaraPage(pdf *gofpdf.Fpdf, request *models.Tra
 	return nil
 }
 
-// GenerateCombinedPDF generates both documents in a single PDF
-func (pg *PDFGenerator) GenerateCombinedPDF(request *models.TravelRequest, report *models.TravelReport) ([]byte, error) {
+// GenerateCombinedPDF generates both documents in a single PDF, with the surat tugas between
+// them once it is issued (letter may be nil). A cancelled request no longer has a surat tugas.
+func (pg *PDFGenerator) GenerateCombinedPDF(request *models.TravelRequest, report *models.TravelReport, letter *models.SuratTugas) ([]byte, error) {
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.SetMargins(15, 15, 15)
 	pdf.SetAutoPageBreak(true, 15)
@@ -640,6 +575,11 @@ func (pg *PDFGenerator) GenerateCombinedPDF(request *models.TravelRequest, repor
 		return nil, err
 	}
 
+	// Surat Tugas issued on approval
+	if letter != nil && request.Status != models.TravelStatusCancelled {
+		pg.addSuratTugasPage(pdf, request, letter)
+	}
+
 	// Page 2: Berita Acara
 	err = pg.addBeritaAcaraPage(pdf, request, report)
 	if err != nil {
@@ -655,6 +595,201 @@ func (pg *PDFGenerator) GenerateCombinedPDF(request *models.TravelRequest, repor
 	return buf.Bytes(), nil
 }
 
+// drawAllowanceTable draws the per-employee allowance (tarif x hari) with the total row
+func (pg *PDFGenerator) drawAllowanceTable(pdf *gofpdf.Fpdf, request *models.TravelRequest) {
+	colWidths := []float64{10, 70, 35, 20, 45}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(colWidths[0], 7, "NO", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[1], 7, "NAMA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[2], 7, "TARIF / HARI", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[3], 7, "HARI", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[4], 7, "JUMLAH", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 9)
+	allowanceDays := RequestAllowanceDays(request)
+	total := 0
+	for i, empRel := range request.TravelRequestEmployees {
+		amount := EmployeeAllowanceAmount(request, empRel)
+		days := empRel.DurationDays
+		if days == 0 {
+			days = request.DurationDays
+		}
+		rate := empRel.DailyRate
+		if rate == 0 && days > 0 {
+			rate = amount / days
+		}
+		paidDays := fmt.Sprintf("%d", days)
+		if allowanceDays.Partial {
+			paidDays = FormatPaidDays(allowanceDays.PaidPercent())
+		}
+		total += amount
+
+		pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, empRel.Employee.Name, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, allowanceRateText(request, rate), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, paidDays, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[4], 6, formatCurrency(amount), "1", 1, "R", false, 0, "")
+	}
+
+	// External participants receiving allowance follow the employees
+	no := len(request.TravelRequestEmployees)
+	for _, participant := range request.Participants {
+		if !participant.ReceivesAllowance {
+			continue
+		}
+		no++
+		total += participant.Subtotal
+
+		name := fmt.Sprintf("%s (%s)", participant.Name, participant.Institution)
+		pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", no), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, fitCellText(pdf, name, colWidths[1]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, allowanceRateText(request, participant.DailyRate), "1", 0, "R", false, 0, "")
+		paidDays := fmt.Sprintf("%d", participant.DurationDays)
+		if allowanceDays.Partial {
+			paidDays = FormatPaidDays(allowanceDays.PaidPercent())
+		}
+		pdf.CellFormat(colWidths[3], 6, paidDays, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[4], 6, formatCurrency(participant.Subtotal), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(colWidths[0]+colWidths[1]+colWidths[2]+colWidths[3], 6, "TOTAL", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[4], 6, formatCurrency(total), "1", 1, "R", true, 0, "")
+
+	// Rincian hari parsial: hari berangkat, hari kembali atau perjalanan singkat
+	if allowanceDays.Partial {
+		pdf.SetFont("Arial", "I", 9)
+		pdf.CellFormat(0, 6, "Rincian hari: "+DescribeAllowanceDays(allowanceDays), "", 1, "L", false, 0, "")
+	}
+	// Kurs tarif luar negeri dalam valuta asing
+	if conversion := DescribeAllowanceConversion(request); conversion != "" {
+		pdf.SetFont("Arial", "I", 9)
+		pdf.CellFormat(0, 6, conversion, "", 1, "L", false, 0, "")
+	}
+	pdf.SetFont("Arial", "", 11)
+}
+
+// allowanceRateText renders the daily rate of the allowance table. A trip abroad priced in a
+// foreign currency shows the original rate; the amounts stay in rupiah.
+func allowanceRateText(request *models.TravelRequest, rate int) string {
+	if len(request.Legs) == 0 && IsForeignCurrency(request.AllowanceCurrency) {
+		return FormatForeignAmount(request.AllowanceCurrency, ConvertFromIDR(rate, request.AllowanceExchangeRate))
+	}
+	return formatCurrency(rate)
+}
+
+// withTravelTime appends the time of day to a date, e.g. "02 January 2025 pukul 07:30"
+func withTravelTime(date, travelTime string) string {
+	if travelTime == "" {
+		return date
+	}
+	return fmt.Sprintf("%s pukul %s", date, travelTime)
+}
+
+// drawItineraryTable draws the legs of a multi-leg trip in travel order
+func drawItineraryTable(pdf *gofpdf.Fpdf, legs []models.TravelLeg) {
+	colWidths := []float64{10, 35, 35, 25, 25, 25, 25}
+	headers := []string{"NO", "DARI", "KE", "BERANGKAT", "TIBA", "ANGKUTAN", "ZONA"}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetX(15)
+	for i, header := range headers {
+		pdf.CellFormat(colWidths[i], 7, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 9)
+	for i, leg := range legs {
+		pdf.SetX(15)
+		pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, fitCellText(pdf, leg.FromPlace, colWidths[1]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, fitCellText(pdf, leg.ToPlace, colWidths[2]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, leg.DepartureDate.Format("02/01/2006"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[4], 6, leg.ArrivalDate.Format("02/01/2006"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[5], 6, fitCellText(pdf, leg.Transportation, colWidths[5]), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[6], 6, fitCellText(pdf, ZoneLabel(leg.DestinationType, leg.DestinationTier), colWidths[6]), "1", 1, "C", false, 0, "")
+	}
+	pdf.SetFont("Arial", "", 11)
+}
+
+// drawItineraryAllowanceTable draws the allowance of every employee per leg (tarif zona x hari leg)
+func (pg *PDFGenerator) drawItineraryAllowanceTable(pdf *gofpdf.Fpdf, request *models.TravelRequest) {
+	colWidths := []float64{10, 50, 50, 30, 15, 25}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(colWidths[0], 7, "NO", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[1], 7, "NAMA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[2], 7, "RUTE", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[3], 7, "TARIF / HARI", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[4], 7, "HARI", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[5], 7, "JUMLAH", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 9)
+	total := 0
+	drawTraveller := func(no int, name string, match func(models.TravelLegAllowance) bool) {
+		for j, leg := range request.Legs {
+			var allowance models.TravelLegAllowance
+			for _, a := range leg.Allowances {
+				if match(a) {
+					allowance = a
+					break
+				}
+			}
+			total += allowance.Subtotal
+
+			noText, nameText := "", ""
+			if j == 0 {
+				noText = fmt.Sprintf("%d", no)
+				nameText = name
+			}
+			route := fmt.Sprintf("%s - %s", leg.FromPlace, leg.ToPlace)
+
+			pdf.CellFormat(colWidths[0], 6, noText, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[1], 6, fitCellText(pdf, nameText, colWidths[1]), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(colWidths[2], 6, fitCellText(pdf, route, colWidths[2]), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(colWidths[3], 6, formatCurrency(allowance.DailyRate), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(colWidths[4], 6, fmt.Sprintf("%d", allowance.Days), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[5], 6, formatCurrency(allowance.Subtotal), "1", 1, "R", false, 0, "")
+		}
+	}
+
+	for i, empRel := range request.TravelRequestEmployees {
+		employeeID := empRel.EmployeeID
+		drawTraveller(i+1, empRel.Employee.Name, func(a models.TravelLegAllowance) bool {
+			return a.ParticipantID == nil && a.EmployeeID == employeeID
+		})
+	}
+
+	no := len(request.TravelRequestEmployees)
+	for _, participant := range request.Participants {
+		if !participant.ReceivesAllowance {
+			continue
+		}
+		no++
+		participantID := participant.ID
+		drawTraveller(no, fmt.Sprintf("%s (%s)", participant.Name, participant.Institution), func(a models.TravelLegAllowance) bool {
+			return a.ParticipantID != nil && *a.ParticipantID == participantID
+		})
+	}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(colWidths[0]+colWidths[1]+colWidths[2]+colWidths[3]+colWidths[4], 6, "TOTAL", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[5], 6, formatCurrency(total), "1", 1, "R", true, 0, "")
+	pdf.SetFont("Arial", "", 11)
+}
+
+// drawParticipantRows adds the external participants to an employee table, numbered after
+// the employees, with their ID number, name and role at their institution
+func drawParticipantRows(pdf *gofpdf.Fpdf, employeeCount int, participants []models.TravelParticipant, colWidths []float64, fontSize float64) {
+	for i, participant := range participants {
+		drawEmployeeRow(pdf, employeeCount+i+1, participant.IDNumber, participant.Name, ParticipantDescription(participant), colWidths, fontSize)
+	}
+}
+
 // Helper function to draw employee table row with text wrapping for position
 func drawEmployeeRow(pdf *gofpdf.Fpdf, no int, nip, name, position string, colWidths []float64, fontSize float64) {
 	currentY := pdf.GetY()
@@ -724,5 +859,652 @@ func numberToWords(num int) string {
 		return words[tens/10] + " puluh " + words[ones]
 	}
 
+	// Larger numbers are read per group: seratus, seribu, then juta, miliar and triliun
+	switch {
+	case num < 1000:
+		return groupToWords(num/100, "ratus", "seratus", num%100)
+	case num < 1000000:
+		return groupToWords(num/1000, "ribu", "seribu", num%1000)
+	case num < 1000000000:
+		return groupToWords(num/1000000, "juta", "", num%1000000)
+	case num < 1000000000000:
+		return groupToWords(num/1000000000, "miliar", "", num%1000000000)
+	case num < 1000000000000000:
+		return groupToWords(num/1000000000000, "triliun", "", num%1000000000000)
+	}
+
 	return fmt.Sprintf("%d", num)
 }
+
+// groupToWords reads count units of a group followed by the rest, e.g. "dua ratus lima".
+// One unit uses single, e.g. "seribu", when the group has such a form.
+func groupToWords(count int, unit, single string, rest int) string {
+	text := numberToWords(count) + " " + unit
+	if count == 1 && single != "" {
+		text = single
+	}
+	if rest > 0 {
+		text += " " + numberToWords(rest)
+	}
+	return text
+}
+
+// GenerateBukuAgenda generates the "Buku Agenda Penomoran" PDF: every number of each
+// agenda with its status (terpakai, batal, kosong), one section per unit code
+func (pg *PDFGenerator) GenerateBukuAgenda(journals []NumberJournal) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+
+	colWidths := []float64{16, 55, 25, 22, 105, 44}
+	headers := []string{"NO. URUT", "NOMOR", "TANGGAL", "STATUS", "KETERANGAN", "DICATAT OLEH"}
+
+	drawHeader := func() {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(240, 240, 240)
+		for i, header := range headers {
+			pdf.CellFormat(colWidths[i], 7, header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 9)
+	}
+
+	if len(journals) == 0 {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(0, 7, "BUKU AGENDA PENOMORAN", "", 1, "C", false, 0, "")
+		pdf.SetFont("Arial", "", 11)
+		pdf.CellFormat(0, 6, "Tidak ada nomor pada periode ini", "", 1, "C", false, 0, "")
+	}
+
+	for _, journal := range journals {
+		pdf.AddPage()
+
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(0, 7, "BUKU AGENDA PENOMORAN", "", 1, "C", false, 0, "")
+		pdf.SetFont("Arial", "", 11)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s - Unit %s - %s", DocumentTypeLabel(journal.DocumentType), journal.PositionCode, journalPeriodLabel(journal)), "", 1, "C", false, 0, "")
+		pdf.CellFormat(0, 6, fmt.Sprintf("Terpakai: %d   Batal: %d   Kosong: %d   Nomor terakhir: %d",
+			journal.UsedCount, journal.VoidCount, journal.GapCount, journal.LastNumber), "", 1, "C", false, 0, "")
+		pdf.Ln(3)
+
+		drawHeader()
+		for _, entry := range journal.Entries {
+			if pdf.GetY()+6 > 195 {
+				pdf.AddPage()
+				drawHeader()
+			}
+
+			date := "-"
+			if entry.Date != nil {
+				date = entry.Date.In(utils.JakartaLocation()).Format("02-01-2006")
+			}
+			note := entry.Description
+			if entry.Status == JournalStatusVoid {
+				note = "BATAL: " + entry.Reason
+			}
+
+			pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", entry.Sequence), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[1], 6, entry.Number, "1", 0, "L", false, 0, "")
+			pdf.CellFormat(colWidths[2], 6, date, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[3], 6, JournalStatusLabel(entry.Status), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[4], 6, fitCellText(pdf, note, colWidths[4]), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(colWidths[5], 6, fitCellText(pdf, entry.VoidedBy, colWidths[5]), "1", 1, "L", false, 0, "")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fitCellText shortens text with "..." so it fits a single line cell of the given width
+func fitCellText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	maxWidth := width - 2
+	if pdf.GetStringWidth(text) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// drawWatermark writes large diagonal light grey text across the current page.
+// Call it from the header func so the page content is drawn on top of it.
+func drawWatermark(pdf *gofpdf.Fpdf, text string) {
+	pageWidth, pageHeight := pdf.GetPageSize()
+
+	pdf.SetFont("Arial", "B", 110)
+	pdf.SetTextColor(225, 225, 225)
+	textWidth := pdf.GetStringWidth(text)
+
+	pdf.TransformBegin()
+	pdf.TransformRotate(45, pageWidth/2, pageHeight/2)
+	pdf.Text((pageWidth-textWidth)/2, pageHeight/2+15, text)
+	pdf.TransformEnd()
+
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// GenerateAmendmentLetter generates the "Surat Perubahan Perjalanan Dinas" PDF for an extension
+// or early return, referencing the original Nota Permintaan number
+func (pg *PDFGenerator) GenerateAmendmentLetter(request *models.TravelRequest, amendment *models.TravelAmendment) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.Image("assets/images/bpd.png", 15, 10, 20, 0, false, "", 0, "")
+	pdf.Image("assets/images/bank jatim.png", 155, 10, 40, 0, false, "", 0, "")
+
+	// Title
+	pdf.SetY(35)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 7, "SURAT PERUBAHAN PERJALANAN DINAS", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "B", 11)
+	title := "PERPANJANGAN WAKTU PERJALANAN DINAS"
+	if amendment.Type == models.AmendmentTypeEarlyReturn {
+		title = "PENGAKHIRAN PERJALANAN DINAS LEBIH AWAL"
+	}
+	pdf.CellFormat(0, 6, title, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", utils.GenerateAmendmentNumber(request.RequestNumber, amendment.AmendmentNumber)), "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.MultiCell(0, 6, fmt.Sprintf("Merujuk Nota Permintaan Surat Tugas Perjalanan Dinas Nomor %s, dengan ini disampaikan perubahan perjalanan dinas sebagai berikut:", request.RequestNumber), "", "L", false)
+	pdf.Ln(2)
+
+	detailRow := func(label, value string) {
+		pdf.Cell(5, 6, "")
+		pdf.Cell(5, 6, "-")
+		pdf.CellFormat(60, 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 6, value, "", "L", false)
+	}
+
+	detailRow("Maksud perjalanan dinas", request.Purpose)
+	detailRow("Tempat tujuan", request.Destination)
+	detailRow("Tanggal berangkat", request.DepartureDate.Format("02 January 2006"))
+	detailRow("Tanggal kembali semula", amendment.OldReturnDate.Format("02 January 2006"))
+	detailRow("Tanggal kembali menjadi", amendment.NewReturnDate.Format("02 January 2006"))
+	detailRow("Lama perjalanan dinas", fmt.Sprintf("%d (%s) hari menjadi %d (%s) hari",
+		amendment.OldDurationDays, numberToWords(amendment.OldDurationDays),
+		amendment.NewDurationDays, numberToWords(amendment.NewDurationDays)))
+	detailRow("Alasan perubahan", amendment.Reason)
+	pdf.Ln(3)
+
+	// Allowance change per employee
+	colWidths := []float64{10, 55, 30, 20, 20, 45}
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(colWidths[0], 7, "NO", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[1], 7, "NAMA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[2], 7, "TARIF / HARI", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[3], 7, "SEMULA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[4], 7, "MENJADI", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[5], 7, "SELISIH", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 9)
+	totalDelta := 0
+	for i, change := range amendment.Employees {
+		totalDelta += change.Delta
+		pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, change.Employee.Name, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, formatCurrency(change.DailyRate), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, fmt.Sprintf("%d hari", change.OldDurationDays), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[4], 6, fmt.Sprintf("%d hari", change.NewDurationDays), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[5], 6, formatSignedCurrency(change.Delta), "1", 1, "R", false, 0, "")
+	}
+	for i, change := range amendment.Participants {
+		totalDelta += change.Delta
+		name := fmt.Sprintf("%s (%s)", change.Participant.Name, change.Participant.Institution)
+		pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", len(amendment.Employees)+i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, fitCellText(pdf, name, colWidths[1]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, formatCurrency(change.DailyRate), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, fmt.Sprintf("%d hari", change.OldDurationDays), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[4], 6, fmt.Sprintf("%d hari", change.NewDurationDays), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[5], 6, formatSignedCurrency(change.Delta), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(colWidths[0]+colWidths[1]+colWidths[2]+colWidths[3]+colWidths[4], 6, "TOTAL SELISIH", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[5], 6, formatSignedCurrency(totalDelta), "1", 1, "R", true, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.Ln(3)
+
+	pdf.MultiCell(0, 6, fmt.Sprintf("Total uang harian semula %s menjadi %s.",
+		formatCurrency(amendment.OldTotalAllowance), formatCurrency(amendment.NewTotalAllowance)), "", "L", false)
+	pdf.Ln(8)
+
+	// Signature of the approver
+	x2 := 110.0
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, fmt.Sprintf("Surabaya, %s", amendment.CreatedAt.Format("02 January 2006")), "", 1, "C", false, 0, "")
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, "Menyetujui,", "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	pdf.SetFont("Arial", "BU", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, amendment.ApproverName, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, amendment.ApproverPosition, "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatSignedCurrency formats an allowance difference with an explicit sign, e.g. "+Rp 250.000,-"
+func formatSignedCurrency(amount int) string {
+	if amount < 0 {
+		return "-" + formatCurrency(-amount)
+	}
+	return "+" + formatCurrency(amount)
+}
+
+// GenerateCancellationLetter generates the "Pembatalan Perjalanan Dinas" PDF of a cancelled
+// request, listing what happens to its at-cost claims
+func (pg *PDFGenerator) GenerateCancellationLetter(request *models.TravelRequest, claims []models.AtCostClaim) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.Image("assets/images/bpd.png", 15, 10, 20, 0, false, "", 0, "")
+	pdf.Image("assets/images/bank jatim.png", 155, 10, 40, 0, false, "", 0, "")
+
+	// Title
+	pdf.SetY(35)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 7, "PEMBATALAN PERJALANAN DINAS", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", utils.GenerateCancellationNumber(request.RequestNumber)), "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.MultiCell(0, 6, fmt.Sprintf("Merujuk Nota Permintaan Surat Tugas Perjalanan Dinas Nomor %s, dengan ini disampaikan bahwa perjalanan dinas atas nama:", request.RequestNumber), "", "L", false)
+	pdf.Ln(2)
+
+	// Employee table
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(10, 8, "NO", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(30, 8, "NIP", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(60, 8, "NAMA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(80, 8, "JABATAN", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 9)
+	empColWidths := []float64{10, 30, 60, 80}
+	for i, empRel := range request.TravelRequestEmployees {
+		drawEmployeeRow(pdf, i+1, empRel.Employee.NIP, empRel.Employee.Name, empRel.Employee.Position.Title, empColWidths, 9)
+	}
+	drawParticipantRows(pdf, len(request.TravelRequestEmployees), request.Participants, empColWidths, 9)
+	pdf.Ln(3)
+
+	pdf.SetFont("Arial", "", 11)
+	pdf.MultiCell(0, 6, "dinyatakan DIBATALKAN dengan keterangan sebagai berikut:", "", "L", false)
+	pdf.Ln(2)
+
+	detailRow := func(label, value string) {
+		pdf.Cell(5, 6, "")
+		pdf.Cell(5, 6, "-")
+		pdf.CellFormat(60, 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 6, value, "", "L", false)
+	}
+
+	cancelledAt := time.Now()
+	if request.CancelledAt != nil {
+		cancelledAt = *request.CancelledAt
+	}
+
+	detailRow("Maksud perjalanan dinas", request.Purpose)
+	detailRow("Tempat tujuan", request.Destination)
+	detailRow("Rencana berangkat", request.DepartureDate.Format("02 January 2006"))
+	detailRow("Rencana kembali", request.ReturnDate.Format("02 January 2006"))
+	detailRow("Tanggal pembatalan", cancelledAt.Format("02 January 2006"))
+	detailRow("Alasan pembatalan", request.CancellationReason)
+	pdf.Ln(3)
+
+	// At-cost claims of the trip
+	if len(claims) > 0 {
+		pdf.MultiCell(0, 6, "Klaim biaya at-cost atas perjalanan dinas tersebut ditindaklanjuti sebagai berikut:", "", "L", false)
+		pdf.Ln(1)
+
+		colWidths := []float64{10, 70, 35, 30, 35}
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(240, 240, 240)
+		pdf.CellFormat(colWidths[0], 7, "NO", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths[1], 7, "NOMOR KLAIM", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths[2], 7, "NILAI KLAIM", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths[3], 7, "TINDAK LANJUT", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths[4], 7, "DIKEMBALIKAN", "1", 1, "C", true, 0, "")
+
+		pdf.SetFont("Arial", "", 9)
+		totalRefund := 0
+		for i, claim := range claims {
+			totalRefund += claim.RefundAmount
+			pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[1], 6, claim.ClaimNumber, "1", 0, "L", false, 0, "")
+			pdf.CellFormat(colWidths[2], 6, formatCurrency(claim.TotalAmount), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(colWidths[3], 6, claimCancellationLabel(claim.Status), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(colWidths[4], 6, formatCurrency(claim.RefundAmount), "1", 1, "R", false, 0, "")
+		}
+
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(colWidths[0]+colWidths[1]+colWidths[2]+colWidths[3], 6, "TOTAL DIKEMBALIKAN", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(colWidths[4], 6, formatCurrency(totalRefund), "1", 1, "R", true, 0, "")
+		pdf.SetFont("Arial", "", 11)
+		pdf.Ln(3)
+	}
+
+	pdf.MultiCell(0, 6, "Demikian pemberitahuan ini disampaikan, atas perhatian dan kerjasamanya disampaikan terima kasih.", "", "L", false)
+	pdf.Ln(8)
+
+	x2 := 110.0
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, fmt.Sprintf("Surabaya, %s", cancelledAt.Format("02 January 2006")), "", 1, "C", false, 0, "")
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, "DIVISI DIGITAL BANKING", "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	repName := "M. MACHFUD HIDAYAT"
+	repPosition := "Vice President"
+	if request.TravelReport != nil {
+		repName = request.TravelReport.RepresentativeName
+		repPosition = request.TravelReport.RepresentativePosition
+	}
+
+	pdf.SetFont("Arial", "BU", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, repName, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, repPosition, "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// claimCancellationLabel describes what happened to a claim of a cancelled trip
+func claimCancellationLabel(status string) string {
+	switch status {
+	case models.ClaimStatusRefundPending:
+		return "Dikembalikan"
+	case models.ClaimStatusRefunded:
+		return "Sudah dikembalikan"
+	case models.ClaimStatusVoid:
+		return "Dibatalkan"
+	case models.ClaimStatusRejected:
+		return "Ditolak"
+	}
+	return status
+}
+
+// GenerateAdvanceSettlement generates the "Pertanggungjawaban Uang Muka" PDF of a settled
+// advance: per employee the advance received, the final allowance, the approved at-cost claims
+// and the amount due to or from the employee
+func (pg *PDFGenerator) GenerateAdvanceSettlement(request *models.TravelRequest, advance *models.TravelAdvance) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.Image("assets/images/bpd.png", 15, 10, 20, 0, false, "", 0, "")
+	pdf.Image("assets/images/bank jatim.png", 155, 10, 40, 0, false, "", 0, "")
+
+	// Title
+	pdf.SetY(35)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 7, "PERTANGGUNGJAWABAN UANG MUKA", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 6, "PERJALANAN DINAS", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("No: %s", utils.GenerateAdvanceSettlementNumber(request.RequestNumber)), "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.MultiCell(0, 6, fmt.Sprintf("Merujuk Nota Permintaan Surat Tugas Perjalanan Dinas Nomor %s, uang muka perjalanan dinas yang dibayarkan pada tanggal %s dipertanggungjawabkan sebagai berikut:",
+		request.RequestNumber, advance.DisbursementDate.Format("02 January 2006")), "", "L", false)
+	pdf.Ln(2)
+
+	detailRow := func(label, value string) {
+		pdf.Cell(5, 6, "")
+		pdf.Cell(5, 6, "-")
+		pdf.CellFormat(60, 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 6, value, "", "L", false)
+	}
+
+	detailRow("Maksud perjalanan dinas", request.Purpose)
+	detailRow("Tempat tujuan", request.Destination)
+	detailRow("Tanggal berangkat", request.DepartureDate.Format("02 January 2006"))
+	detailRow("Tanggal kembali", request.ReturnDate.Format("02 January 2006"))
+	if request.Status == models.TravelStatusCancelled {
+		detailRow("Keterangan", "Perjalanan dinas dibatalkan, uang harian tidak dibayarkan")
+	} else {
+		detailRow("Lama perjalanan dinas", fmt.Sprintf("%d (%s) hari", request.DurationDays, numberToWords(request.DurationDays)))
+	}
+	pdf.Ln(3)
+
+	// Settlement per employee
+	colWidths := []float64{8, 47, 25, 25, 25, 25, 25}
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(colWidths[0], 7, "NO", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[1], 7, "NAMA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[2], 7, "UANG MUKA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[3], 7, "UANG HARIAN", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[4], 7, "KLAIM AT-COST", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[5], 7, "SELISIH", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[6], 7, "KETERANGAN", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 8)
+	dueToEmployees, dueFromEmployees := 0, 0
+	for i, line := range advance.Employees {
+		if line.Balance > 0 {
+			dueToEmployees += line.Balance
+		} else {
+			dueFromEmployees -= line.Balance
+		}
+		balance := line.Balance
+		if balance < 0 {
+			balance = -balance
+		}
+		pdf.CellFormat(colWidths[0], 6, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, fitCellText(pdf, line.Employee.Name, colWidths[1]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, formatCurrency(line.Amount), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, formatCurrency(line.Allowance), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[4], 6, formatCurrency(line.ClaimAmount), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[5], 6, formatCurrency(balance), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[6], 6, AdvanceBalanceLabel(line.Balance), "1", 1, "C", false, 0, "")
+	}
+
+	pdf.SetFont("Arial", "B", 8)
+	pdf.CellFormat(colWidths[0]+colWidths[1], 6, "TOTAL", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths[2], 6, formatCurrency(advance.TotalAmount), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(colWidths[3], 6, formatCurrency(advance.TotalAllowance), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(colWidths[4], 6, formatCurrency(advance.TotalClaims), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(colWidths[5]+colWidths[6], 6, "", "1", 1, "R", true, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.Ln(3)
+
+	pdf.MultiCell(0, 6, "Kurang bayar dibayarkan kepada pegawai, lebih bayar dikembalikan oleh pegawai ke rekening perusahaan.", "", "L", false)
+	detailRow("Dibayarkan kepada pegawai", formatCurrency(dueToEmployees))
+	detailRow("Dikembalikan oleh pegawai", formatCurrency(dueFromEmployees))
+	pdf.Ln(8)
+
+	settledAt := time.Now()
+	if advance.SettledAt != nil {
+		settledAt = *advance.SettledAt
+	}
+
+	x2 := 110.0
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, fmt.Sprintf("Surabaya, %s", settledAt.Format("02 January 2006")), "", 1, "C", false, 0, "")
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, "DIVISI DIGITAL BANKING", "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	repName := "M. MACHFUD HIDAYAT"
+	repPosition := "Vice President"
+	if request.TravelReport != nil {
+		repName = request.TravelReport.RepresentativeName
+		repPosition = request.TravelReport.RepresentativePosition
+	}
+
+	pdf.SetFont("Arial", "BU", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, repName, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, repPosition, "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateSuratTugas generates the "Surat Tugas Perjalanan Dinas" PDF of an approved request.
+// The letter of a cancelled request is watermarked "BATAL" on every page.
+func (pg *PDFGenerator) GenerateSuratTugas(request *models.TravelRequest, letter *models.SuratTugas) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	if request.Status == models.TravelStatusCancelled {
+		pdf.SetHeaderFunc(func() {
+			drawWatermark(pdf, "BATAL")
+		})
+	}
+	pg.addSuratTugasPage(pdf, request, letter)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addSuratTugasPage adds the surat tugas page: the travellers with NIP and position, the trip
+// and the signer copied when the letter was issued. The trip is shown as it is now, so the
+// amendments in request.Amendments made after the letter was issued are referenced.
+func (pg *PDFGenerator) addSuratTugasPage(pdf *gofpdf.Fpdf, request *models.TravelRequest, letter *models.SuratTugas) {
+	pdf.AddPage()
+
+	pdf.Image("assets/images/bpd.png", 15, 10, 20, 0, false, "", 0, "")
+	pdf.Image("assets/images/bank jatim.png", 155, 10, 40, 0, false, "", 0, "")
+
+	// Title
+	pdf.SetY(35)
+	pdf.SetFont("Arial", "BU", 14)
+	pdf.CellFormat(0, 7, "SURAT TUGAS", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 6, "PERJALANAN DINAS", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Nomor: %s", letter.LetterNumber), "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.MultiCell(0, 6, fmt.Sprintf("Berdasarkan Nota Permintaan Surat Tugas Perjalanan Dinas Divisi Digital Banking Nomor %s, PT. Bank Pembangunan Daerah Jawa Timur dengan ini menugaskan kepada:",
+		utils.WithRevision(request.RequestNumber, request.Revision)), "", "L", false)
+	pdf.Ln(2)
+
+	// Traveller table
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(10, 8, "NO", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(30, 8, "NIP", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(60, 8, "NAMA", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(80, 8, "JABATAN", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 9)
+	empColWidths := []float64{10, 30, 60, 80}
+	for i, empRel := range request.TravelRequestEmployees {
+		drawEmployeeRow(pdf, i+1, empRel.Employee.NIP, empRel.Employee.Name, empRel.Employee.Position.Title, empColWidths, 9)
+	}
+	drawParticipantRows(pdf, len(request.TravelRequestEmployees), request.Participants, empColWidths, 9)
+	pdf.Ln(5)
+
+	pdf.SetFont("Arial", "", 11)
+	pdf.MultiCell(0, 6, "Untuk melaksanakan perjalanan dinas dengan ketentuan sebagai berikut:", "", "L", false)
+	pdf.Ln(2)
+
+	detailRow := func(label, value string) {
+		pdf.Cell(5, 6, "")
+		pdf.Cell(5, 6, "-")
+		pdf.CellFormat(60, 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 6, value, "", "L", false)
+	}
+
+	detailRow("Maksud perjalanan dinas", request.Purpose)
+	detailRow("Tempat berangkat", request.DeparturePlace)
+	detailRow("Tempat tujuan", request.Destination)
+	detailRow("Tanggal berangkat", withTravelTime(request.DepartureDate.Format("02 January 2006"), request.DepartureTime))
+	detailRow("Tanggal kembali", withTravelTime(request.ReturnDate.Format("02 January 2006"), request.ReturnTime))
+	detailRow("Lama perjalanan dinas", fmt.Sprintf("%d (%s) hari", request.DurationDays, numberToWords(request.DurationDays)))
+	detailRow("Angkutan yang digunakan", request.Transportation)
+	if len(request.Legs) > 0 {
+		pdf.Cell(5, 6, "")
+		pdf.Cell(5, 6, "-")
+		pdf.CellFormat(60, 6, "Rute perjalanan", "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 1, "L", false, 0, "")
+		drawItineraryTable(pdf, request.Legs)
+	}
+	for _, amendment := range SuratTugasAmendments(letter, request.Amendments) {
+		detailRow("Perubahan", fmt.Sprintf("Surat Perubahan Perjalanan Dinas Nomor %s tanggal %s",
+			utils.GenerateAmendmentNumber(request.RequestNumber, amendment.AmendmentNumber),
+			amendment.CreatedAt.In(utils.JakartaLocation()).Format("02 January 2006")))
+	}
+	pdf.Ln(4)
+
+	// Closing
+	pdf.MultiCell(0, 6, "Setelah melaksanakan tugas, yang bersangkutan wajib menyampaikan laporan perjalanan dinas kepada Divisi Digital Banking. Surat tugas ini agar dilaksanakan dengan penuh tanggung jawab.", "", "L", false)
+	pdf.Ln(8)
+
+	place := letter.IssuePlace
+	if place == "" {
+		place = "Surabaya"
+	}
+
+	x2 := 110.0
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, fmt.Sprintf("%s, %s", place, letter.IssueDate.Format("02 January 2006")), "", 1, "C", false, 0, "")
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, letter.SignerUnit, "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	// An unconfigured signer writes their name by hand
+	signerName := letter.SignerName
+	if signerName == "" {
+		signerName = "(..............................)"
+	}
+
+	pdf.SetFont("Arial", "BU", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, signerName, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.SetX(x2)
+	pdf.CellFormat(90, 6, letter.SignerPosition, "", 1, "C", false, 0, "")
+	if letter.SignerNIP != "" {
+		pdf.SetX(x2)
+		pdf.CellFormat(90, 6, fmt.Sprintf("NIP. %s", letter.SignerNIP), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(10)
+
+	// Tindasan
+	pdf.SetFont("Arial", "", 8)
+	pdf.CellFormat(0, 6, "Tindasan :", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "- Divisi Digital Banking", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "- Arsip", "", 1, "L", false, 0, "")
+}
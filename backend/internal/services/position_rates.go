@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRateOverlap       = errors.New("rate period overlaps another rate of the position")
+	ErrInvalidRatePeriod = errors.New("valid_to cannot be before valid_from")
+	ErrPositionInUse     = errors.New("position is still assigned to employees")
+)
+
+// EffectiveRate returns the rate valid on date, or nil when no rate covers it.
+// When periods overlap the rate that started last wins.
+func EffectiveRate(rates []models.PositionRate, date time.Time) *models.PositionRate {
+	day := dateOnly(date)
+	var effective *models.PositionRate
+	for i := range rates {
+		rate := &rates[i]
+		if day.Before(dateOnly(rate.ValidFrom)) {
+			continue
+		}
+		if rate.ValidTo != nil && day.After(dateOnly(*rate.ValidTo)) {
+			continue
+		}
+		if effective == nil || rate.ValidFrom.After(effective.ValidFrom) {
+			effective = rate
+		}
+	}
+	return effective
+}
+
+// applyRate copies the allowance of rate onto position
+func applyRate(position *models.Position, rate *models.PositionRate) {
+	position.AllowanceInProvince = rate.AllowanceInProvince
+	position.AllowanceOutsideProvince = rate.AllowanceOutsideProvince
+	position.AllowanceAbroad = rate.AllowanceAbroad
+	position.AbroadCurrency = rate.AbroadCurrency
+}
+
+// rateEnd returns the last day of a rate, far in the future for an open-ended rate
+func rateEnd(rate models.PositionRate) time.Time {
+	if rate.ValidTo == nil {
+		return time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	return dateOnly(*rate.ValidTo)
+}
+
+// RatePeriodsOverlap reports whether two rates are valid on at least one common day
+func RatePeriodsOverlap(a, b models.PositionRate) bool {
+	return DatesOverlap(dateOnly(a.ValidFrom), rateEnd(a), dateOnly(b.ValidFrom), rateEnd(b))
+}
+
+// describePositionRate renders a rate for the audit trail,
+// e.g. "2025-01-01 s.d. seterusnya: 100000/200000/300000", with the currency of a foreign
+// abroad rate appended as in "100000/200000/50 USD"
+func describePositionRate(rate *models.PositionRate) string {
+	if rate == nil {
+		return ""
+	}
+	validTo := "seterusnya"
+	if rate.ValidTo != nil {
+		validTo = rate.ValidTo.Format("2006-01-02")
+	}
+	description := fmt.Sprintf("%s s.d. %s: %d/%d/%d", rate.ValidFrom.Format("2006-01-02"), validTo,
+		rate.AllowanceInProvince, rate.AllowanceOutsideProvince, rate.AllowanceAbroad)
+	if IsForeignCurrency(rate.AbroadCurrency) {
+		description += " " + rate.AbroadCurrency
+	}
+	return description
+}
+
+// validateRatePeriod checks that rate does not end before it starts nor overlap another rate
+func validateRatePeriod(rate models.PositionRate, others []models.PositionRate) error {
+	if rate.ValidTo != nil && dateOnly(*rate.ValidTo).Before(dateOnly(rate.ValidFrom)) {
+		return ErrInvalidRatePeriod
+	}
+	for _, other := range others {
+		if other.ID != rate.ID && RatePeriodsOverlap(rate, other) {
+			return fmt.Errorf("%w: %s", ErrRateOverlap, describePositionRate(&other))
+		}
+	}
+	return nil
+}
+
+// PositionRateService manages the effective-dated allowance rates of positions
+type PositionRateService struct {
+	repo *repository.Repository
+}
+
+func NewPositionRateService(repo *repository.Repository) *PositionRateService {
+	return &PositionRateService{repo: repo}
+}
+
+// ApplyEffectiveRates sets the allowance of every position to the rate valid on date.
+// Positions without a rate covering date keep their own allowance.
+func (s *PositionRateService) ApplyEffectiveRates(positions []*models.Position, date time.Time) error {
+	ids := make([]uint, 0, len(positions))
+	seen := make(map[uint]bool)
+	for _, position := range positions {
+		if !seen[position.ID] {
+			seen[position.ID] = true
+			ids = append(ids, position.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rates, err := s.repo.GetPositionRatesByPositionIDs(ids)
+	if err != nil {
+		return fmt.Errorf("failed to load position rates: %w", err)
+	}
+	byPosition := make(map[uint][]models.PositionRate)
+	for _, rate := range rates {
+		byPosition[rate.PositionID] = append(byPosition[rate.PositionID], rate)
+	}
+
+	for _, position := range positions {
+		if rate := EffectiveRate(byPosition[position.ID], date); rate != nil {
+			applyRate(position, rate)
+		}
+	}
+	return nil
+}
+
+// CreatePosition saves a new position with its allowance as the first rate, valid from validFrom
+func (s *PositionRateService) CreatePosition(position *models.Position, validFrom time.Time, changedBy string) error {
+	return s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Rates").Create(position).Error; err != nil {
+			return fmt.Errorf("failed to create position: %w", err)
+		}
+		rate := &models.PositionRate{
+			PositionID:               position.ID,
+			ValidFrom:                validFrom,
+			AllowanceInProvince:      position.AllowanceInProvince,
+			AllowanceOutsideProvince: position.AllowanceOutsideProvince,
+			AllowanceAbroad:          position.AllowanceAbroad,
+			AbroadCurrency:           position.AbroadCurrency,
+		}
+		if err := tx.Create(rate).Error; err != nil {
+			return fmt.Errorf("failed to create position rate: %w", err)
+		}
+		return recordRateChangeTx(tx, rate, models.RateChangeCreate, "", describePositionRate(rate), changedBy)
+	})
+}
+
+// CreateRate adds a rate to a position. An open-ended rate that started earlier is closed
+// the day before the new rate starts, so a new tariff can simply be added with its start date.
+func (s *PositionRateService) CreateRate(positionID uint, rate *models.PositionRate, changedBy string) error {
+	return s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		position, rates, err := lockPositionRatesTx(tx, positionID)
+		if err != nil {
+			return err
+		}
+
+		rate.ID = 0
+		rate.PositionID = positionID
+		for i := range rates {
+			previous := &rates[i]
+			if previous.ValidTo != nil || !dateOnly(previous.ValidFrom).Before(dateOnly(rate.ValidFrom)) {
+				continue
+			}
+			old := describePositionRate(previous)
+			closedOn := dateOnly(rate.ValidFrom).AddDate(0, 0, -1)
+			previous.ValidTo = &closedOn
+			if err := tx.Save(previous).Error; err != nil {
+				return fmt.Errorf("failed to close previous rate: %w", err)
+			}
+			if err := recordRateChangeTx(tx, previous, models.RateChangeUpdate, old, describePositionRate(previous), changedBy); err != nil {
+				return err
+			}
+		}
+		if err := validateRatePeriod(*rate, rates); err != nil {
+			return err
+		}
+
+		if err := tx.Create(rate).Error; err != nil {
+			return fmt.Errorf("failed to create position rate: %w", err)
+		}
+		if err := recordRateChangeTx(tx, rate, models.RateChangeCreate, "", describePositionRate(rate), changedBy); err != nil {
+			return err
+		}
+		return syncCurrentRateTx(tx, position, append(rates, *rate))
+	})
+}
+
+// UpdateRate changes the period or allowance of a rate
+func (s *PositionRateService) UpdateRate(positionID, rateID uint, updated models.PositionRate, changedBy string) (*models.PositionRate, error) {
+	var rate *models.PositionRate
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		position, rates, err := lockPositionRatesTx(tx, positionID)
+		if err != nil {
+			return err
+		}
+		for i := range rates {
+			if rates[i].ID == rateID {
+				rate = &rates[i]
+			}
+		}
+		if rate == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		old := describePositionRate(rate)
+		rate.ValidFrom = updated.ValidFrom
+		rate.ValidTo = updated.ValidTo
+		rate.AllowanceInProvince = updated.AllowanceInProvince
+		rate.AllowanceOutsideProvince = updated.AllowanceOutsideProvince
+		rate.AllowanceAbroad = updated.AllowanceAbroad
+		rate.AbroadCurrency = updated.AbroadCurrency
+		if err := validateRatePeriod(*rate, rates); err != nil {
+			return err
+		}
+
+		if err := tx.Save(rate).Error; err != nil {
+			return fmt.Errorf("failed to update position rate: %w", err)
+		}
+		if err := recordRateChangeTx(tx, rate, models.RateChangeUpdate, old, describePositionRate(rate), changedBy); err != nil {
+			return err
+		}
+		return syncCurrentRateTx(tx, position, rates)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// DeleteRate removes a rate of a position
+func (s *PositionRateService) DeleteRate(positionID, rateID uint, changedBy string) error {
+	return s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		position, rates, err := lockPositionRatesTx(tx, positionID)
+		if err != nil {
+			return err
+		}
+
+		remaining := make([]models.PositionRate, 0, len(rates))
+		var deleted *models.PositionRate
+		for i := range rates {
+			if rates[i].ID == rateID {
+				deleted = &rates[i]
+				continue
+			}
+			remaining = append(remaining, rates[i])
+		}
+		if deleted == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Delete(deleted).Error; err != nil {
+			return fmt.Errorf("failed to delete position rate: %w", err)
+		}
+		if err := recordRateChangeTx(tx, deleted, models.RateChangeDelete, describePositionRate(deleted), "", changedBy); err != nil {
+			return err
+		}
+		return syncCurrentRateTx(tx, position, remaining)
+	})
+}
+
+// lockPositionRatesTx locks a position row for the rest of the transaction and returns it
+// with its rates
+func lockPositionRatesTx(tx *gorm.DB, positionID uint) (*models.Position, []models.PositionRate, error) {
+	var position models.Position
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&position, positionID).Error; err != nil {
+		return nil, nil, err
+	}
+	var rates []models.PositionRate
+	if err := tx.Where("position_id = ?", positionID).Order("valid_from ASC").Find(&rates).Error; err != nil {
+		return nil, nil, err
+	}
+	return &position, rates, nil
+}
+
+// syncCurrentRateTx mirrors the rate valid today onto the position
+func syncCurrentRateTx(tx *gorm.DB, position *models.Position, rates []models.PositionRate) error {
+	rate := EffectiveRate(rates, time.Now())
+	if rate == nil {
+		return nil
+	}
+	applyRate(position, rate)
+	return tx.Model(position).Updates(map[string]interface{}{
+		"allowance_in_province":      position.AllowanceInProvince,
+		"allowance_outside_province": position.AllowanceOutsideProvince,
+		"allowance_abroad":           position.AllowanceAbroad,
+		"abroad_currency":            position.AbroadCurrency,
+	}).Error
+}
+
+func recordRateChangeTx(tx *gorm.DB, rate *models.PositionRate, action, oldValue, newValue, changedBy string) error {
+	change := &models.PositionRateChange{
+		PositionID:     rate.PositionID,
+		PositionRateID: rate.ID,
+		Action:         action,
+		OldValue:       oldValue,
+		NewValue:       newValue,
+		ChangedBy:      changedBy,
+		ChangedAt:      time.Now(),
+	}
+	if err := tx.Create(change).Error; err != nil {
+		return fmt.Errorf("failed to record rate change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestEffectiveRate(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	closed := date(2024, 12, 31)
+	rates := []models.PositionRate{
+		{ID: 1, ValidFrom: date(2000, 1, 1), ValidTo: &closed, AllowanceInProvince: 100000},
+		{ID: 2, ValidFrom: date(2025, 1, 1), AllowanceInProvince: 125000},
+	}
+
+	if rate := EffectiveRate(rates, date(2024, 12, 31)); rate == nil || rate.ID != 1 {
+		t.Errorf("Expected the old rate on its last day, got %+v", rate)
+	}
+	if rate := EffectiveRate(rates, date(2025, 1, 1)); rate == nil || rate.ID != 2 {
+		t.Errorf("Expected the new rate from its first day, got %+v", rate)
+	}
+	if rate := EffectiveRate(rates, date(1999, 6, 1)); rate != nil {
+		t.Errorf("Expected no rate before the first period, got %+v", rate)
+	}
+
+	position := models.Position{AllowanceInProvince: 1}
+	applyRate(&position, EffectiveRate(rates, date(2024, 3, 1)))
+	if position.AllowanceInProvince != 100000 {
+		t.Errorf("Expected the rate valid on departure to be applied, got %d", position.AllowanceInProvince)
+	}
+}
+
+func TestValidateRatePeriod(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	closed := date(2024, 12, 31)
+	existing := []models.PositionRate{
+		{ID: 1, ValidFrom: date(2024, 1, 1), ValidTo: &closed},
+		{ID: 2, ValidFrom: date(2025, 1, 1)},
+	}
+
+	if err := validateRatePeriod(models.PositionRate{ValidFrom: date(2025, 6, 1)}, existing); !errors.Is(err, ErrRateOverlap) {
+		t.Errorf("Expected ErrRateOverlap with the open-ended rate, got %v", err)
+	}
+	before := date(2023, 1, 1)
+	if err := validateRatePeriod(models.PositionRate{ValidFrom: date(2023, 6, 1), ValidTo: &before}, existing); !errors.Is(err, ErrInvalidRatePeriod) {
+		t.Errorf("Expected ErrInvalidRatePeriod, got %v", err)
+	}
+	end := date(2023, 12, 31)
+	if err := validateRatePeriod(models.PositionRate{ValidFrom: date(2023, 1, 1), ValidTo: &end}, existing); err != nil {
+		t.Errorf("Expected an earlier closed period to be accepted, got %v", err)
+	}
+	if err := validateRatePeriod(existing[1], existing); err != nil {
+		t.Errorf("Expected a rate not to overlap itself, got %v", err)
+	}
+}
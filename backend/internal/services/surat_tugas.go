@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSuratTugasNotAllowed = errors.New("surat tugas can only be issued for approved travel requests")
+
+// CanIssueSuratTugas reports whether a surat tugas may be issued for a request in status.
+// Requests approved before surat tugas were generated get theirs later, during or after the trip.
+func CanIssueSuratTugas(status string) bool {
+	switch status {
+	case models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// BuildSuratTugas returns the surat tugas of request with the allocated number, signed by signer
+func BuildSuratTugas(request *models.TravelRequest, allocation *NumberAllocation, signer *models.SuratTugasSignerConfig, issueDate time.Time, actor string) *models.SuratTugas {
+	letter := &models.SuratTugas{
+		TravelRequestID: request.ID,
+		LetterNumber:    allocation.Number,
+		NumberYear:      allocation.Year,
+		NumberSequence:  allocation.Sequence,
+		IssueDate:       dateOnly(issueDate),
+		IssuedBy:        actor,
+	}
+	if signer != nil {
+		letter.SignerName = signer.Name
+		letter.SignerNIP = signer.NIP
+		letter.SignerPosition = signer.Position
+		letter.SignerUnit = signer.Unit
+		letter.IssuePlace = signer.City
+	}
+	return letter
+}
+
+// SuratTugasAmendments returns the amendments made after letter was issued, which change the
+// trip it was issued for
+func SuratTugasAmendments(letter *models.SuratTugas, amendments []models.TravelAmendment) []models.TravelAmendment {
+	var after []models.TravelAmendment
+	for _, amendment := range amendments {
+		if amendment.CreatedAt.After(letter.CreatedAt) {
+			after = append(after, amendment)
+		}
+	}
+	return after
+}
+
+// SuratTugasService issues the surat tugas of approved travel requests
+type SuratTugasService struct {
+	repo      *repository.Repository
+	numbering *NumberingService
+}
+
+func NewSuratTugasService(repo *repository.Repository) *SuratTugasService {
+	return &SuratTugasService{
+		repo:      repo,
+		numbering: NewNumberingService(repo),
+	}
+}
+
+// Issue issues the surat tugas of an approved travel request, or returns the one already issued
+func (s *SuratTugasService) Issue(requestID uint, actor string) (*models.SuratTugas, error) {
+	var letter *models.SuratTugas
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if !CanIssueSuratTugas(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrSuratTugasNotAllowed, request.Status)
+		}
+
+		var err error
+		letter, err = s.IssueTx(tx, &request, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return letter, nil
+}
+
+// IssueTx issues the surat tugas of request inside the caller's transaction, which must hold the
+// request's row lock. A request has one surat tugas: if it is already issued it is returned and
+// no number is taken.
+func (s *SuratTugasService) IssueTx(tx *gorm.DB, request *models.TravelRequest, actor string) (*models.SuratTugas, error) {
+	var existing models.SuratTugas
+	err := tx.Where("travel_request_id = ?", request.ID).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	positionCode := utils.ExtractPositionCodeFromRequestNumber(request.RequestNumber)
+	if positionCode == "" {
+		return nil, fmt.Errorf("failed to extract position code from request number")
+	}
+
+	var signer models.SuratTugasSignerConfig
+	if err := tx.Limit(1).Find(&signer).Error; err != nil {
+		return nil, fmt.Errorf("failed to get surat tugas signer: %w", err)
+	}
+
+	// Number taken in the same transaction so a failed approval gives it back: 064/{seq}/DIB/{code}/ST
+	now := time.Now()
+	allocation, err := s.numbering.AllocateTx(tx, models.DocTypeSuratTugas, positionCode, now)
+	if err != nil {
+		return nil, err
+	}
+
+	letter := BuildSuratTugas(request, allocation, &signer, now.In(utils.JakartaLocation()), actor)
+	if err := tx.Create(letter).Error; err != nil {
+		return nil, fmt.Errorf("failed to create surat tugas: %w", err)
+	}
+	return letter, nil
+}
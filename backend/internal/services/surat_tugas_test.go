@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/utils"
+)
+
+func TestBuildSuratTugas(t *testing.T) {
+	number := FormatDocumentNumber(models.DocTypeSuratTugas, 2025, 12, "DPEB")
+	if number != "064/0012/DIB/DPEB/ST/2025" {
+		t.Fatalf("Expected a surat tugas number with /ST, got %s", number)
+	}
+	if code := utils.ExtractPositionCodeFromRequestNumber(number); code != "DPEB" {
+		t.Errorf("Expected the journal to group the letter under DPEB, got %q", code)
+	}
+
+	request := &models.TravelRequest{ID: 5, RequestNumber: "064/0040/DIB/DPEB/NOTA", Status: models.TravelStatusApproved}
+	allocation := &NumberAllocation{Year: 2025, DocumentType: models.DocTypeSuratTugas, PositionCode: "DPEB", Sequence: 12, Number: number}
+	signer := &models.SuratTugasSignerConfig{Name: "Sri Wahyuni", NIP: "1234", Position: "Pemimpin Divisi", Unit: "DIVISI HUMAN CAPITAL", City: "Surabaya"}
+	issued := time.Date(2025, 3, 4, 9, 30, 0, 0, utils.JakartaLocation())
+
+	letter := BuildSuratTugas(request, allocation, signer, issued, "admin")
+	if letter.TravelRequestID != 5 || letter.LetterNumber != number || letter.NumberYear != 2025 || letter.NumberSequence != 12 {
+		t.Errorf("Unexpected numbering %+v", letter)
+	}
+	if letter.SignerName != "Sri Wahyuni" || letter.SignerUnit != "DIVISI HUMAN CAPITAL" || letter.IssuePlace != "Surabaya" {
+		t.Errorf("Expected the signer to be copied onto the letter, got %+v", letter)
+	}
+	if !letter.IssueDate.Equal(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected issue date %v", letter.IssueDate)
+	}
+
+	// The letter keeps its signer when the config changes later
+	signer.Name = "Budi Santoso"
+	if letter.SignerName != "Sri Wahyuni" {
+		t.Errorf("Expected the issued letter to keep its signer, got %s", letter.SignerName)
+	}
+
+	if CanIssueSuratTugas(models.TravelStatusSubmitted) || CanIssueSuratTugas(models.TravelStatusCancelled) {
+		t.Error("Expected surat tugas only for approved requests")
+	}
+}
+
+func TestSuratTugasAmendments(t *testing.T) {
+	issuedAt := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	letter := &models.SuratTugas{CreatedAt: issuedAt}
+	amendments := []models.TravelAmendment{
+		{AmendmentNumber: 1, CreatedAt: issuedAt.Add(-time.Hour)},
+		{AmendmentNumber: 2, CreatedAt: issuedAt.AddDate(0, 0, 3)},
+	}
+
+	after := SuratTugasAmendments(letter, amendments)
+	if len(after) != 1 || after[0].AmendmentNumber != 2 {
+		t.Errorf("Expected only the amendment made after the letter was issued, got %+v", after)
+	}
+	if got := SuratTugasAmendments(letter, nil); len(got) != 0 {
+		t.Errorf("Expected no amendments, got %+v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/utils"
+)
+
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// ValidateTimeZone checks that name is an IANA time zone, e.g. Asia/Makassar
+func ValidateTimeZone(name string) error {
+	if name == "" || name == "Local" {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeZone, name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeZone, name)
+	}
+	return nil
+}
+
+// PlaceTimeZone returns the time zone of a place in the city master, WIB for unknown places
+func PlaceTimeZone(cities []models.City, place string) string {
+	if city := FindCity(cities, place); city != nil && city.TimeZone != "" {
+		return city.TimeZone
+	}
+	return utils.DefaultTimeZone
+}
+
+// LocalDate returns the calendar date of date as midnight in the named time zone, so a date
+// parsed as 2006-01-02 denotes that day at the place it belongs to
+func LocalDate(date time.Time, zone string) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, utils.TimeZone(zone))
+}
+
+// TimeZone returns the time zone of a place in the city master, WIB for unknown places
+func (s *CityService) TimeZone(place string) (string, error) {
+	cities, err := s.repo.GetAllCities()
+	if err != nil {
+		return "", fmt.Errorf("failed to load cities: %w", err)
+	}
+	return PlaceTimeZone(cities, place), nil
+}
+
+// ApplyLegTimeZones sets the time zones of the places of every leg and moves its dates to
+// midnight in them: the departure date at FromPlace and the arrival date at ToPlace
+func (s *CityService) ApplyLegTimeZones(legs []models.TravelLeg) error {
+	if len(legs) == 0 {
+		return nil
+	}
+	cities, err := s.repo.GetAllCities()
+	if err != nil {
+		return fmt.Errorf("failed to load cities: %w", err)
+	}
+	for i := range legs {
+		legs[i].DepartureTimeZone = PlaceTimeZone(cities, legs[i].FromPlace)
+		legs[i].ArrivalTimeZone = PlaceTimeZone(cities, legs[i].ToPlace)
+		legs[i].DepartureDate = LocalDate(legs[i].DepartureDate, legs[i].DepartureTimeZone)
+		legs[i].ArrivalDate = LocalDate(legs[i].ArrivalDate, legs[i].ArrivalTimeZone)
+	}
+	return nil
+}
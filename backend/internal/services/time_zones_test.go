@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+)
+
+func TestNormalizeCityTimeZone(t *testing.T) {
+	city := models.City{Name: "Makassar", ProvinceCode: "73", CountryCode: "id"}
+	if err := NormalizeCity(&city); err != nil || city.TimeZone != "Asia/Makassar" {
+		t.Errorf("Expected Makassar in Asia/Makassar, got %q (%v)", city.TimeZone, err)
+	}
+	city = models.City{Name: "Nowhere", ProvinceCode: "35", CountryCode: "ID", TimeZone: "Asia/Surabaya"}
+	if err := NormalizeCity(&city); !errors.Is(err, ErrInvalidCity) {
+		t.Errorf("Expected an unknown time zone to be rejected, got %v", err)
+	}
+}
+
+func TestLocalTripDates(t *testing.T) {
+	cities := []models.City{
+		{Name: "Surabaya", TimeZone: "Asia/Jakarta"},
+		{Name: "Jayapura", TimeZone: "Asia/Jayapura"},
+	}
+	parsed := time.Date(2025, 10, 17, 0, 0, 0, 0, time.UTC)
+	departure := LocalDate(parsed, PlaceTimeZone(cities, "Surabaya"))
+	arrival := LocalDate(parsed, PlaceTimeZone(cities, "jayapura"))
+
+	// Midnight in Jayapura is two hours before midnight in Surabaya, still the same day
+	if !arrival.Before(departure) {
+		t.Fatalf("Expected WIT midnight before WIB midnight, got %v and %v", arrival, departure)
+	}
+	if days := repository.CalculateDurationDays(departure, arrival); days != 1 {
+		t.Errorf("Expected a same-day flight to Jayapura to last 1 day, got %d", days)
+	}
+	leg := []models.TravelLeg{{FromPlace: "Surabaya", ToPlace: "Jayapura", DestinationType: "outside_province", DepartureDate: departure, ArrivalDate: arrival}}
+	if err := ValidateItinerary(leg); err != nil {
+		t.Errorf("Expected the leg to be valid on the local calendars, got %v", err)
+	}
+
+	// Leaving Surabaya at 08:00 WIB and landing at 14:00 WIT takes four hours
+	if hours, ok := tripHours(departure, arrival, "08:00", "14:00"); !ok || hours != 4 {
+		t.Errorf("Expected 4 hours, got %v", hours)
+	}
+	if got := PlaceTimeZone(cities, "Atlantis"); got != "Asia/Jakarta" {
+		t.Errorf("Expected unknown places in WIB, got %q", got)
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAdvanceNotAllowed    = errors.New("advances can only be paid for approved or in progress travel requests")
+	ErrAdvanceExists        = errors.New("travel request already has an advance")
+	ErrInvalidAdvance       = errors.New("invalid advance")
+	ErrAdvanceNotSettleable = errors.New("advances can only be settled once the trip is completed or cancelled")
+	ErrAdvanceSettled       = errors.New("advance is already settled")
+)
+
+// IsAdvanceableTravelStatus reports whether an advance may be paid for a request in status
+func IsAdvanceableTravelStatus(status string) bool {
+	return status == models.TravelStatusApproved || status == models.TravelStatusInProgress
+}
+
+// IsSettleableTravelStatus reports whether the advance of a request in status can be settled.
+// A cancelled trip is settled too: its travellers return the whole advance.
+func IsSettleableTravelStatus(status string) bool {
+	return status == models.TravelStatusCompleted || status == models.TravelStatusCancelled
+}
+
+// AdvanceLine is the advance paid to one employee
+type AdvanceLine struct {
+	EmployeeID uint
+	Amount     int
+}
+
+// BuildAdvance checks that every line is for a different employee of request and returns the
+// advance, not yet saved
+func BuildAdvance(request *models.TravelRequest, lines []AdvanceLine, disbursementDate time.Time) (*models.TravelAdvance, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one employee is required", ErrInvalidAdvance)
+	}
+	travellers := make(map[uint]models.Employee, len(request.TravelRequestEmployees))
+	for _, empRel := range request.TravelRequestEmployees {
+		travellers[empRel.EmployeeID] = empRel.Employee
+	}
+
+	advance := &models.TravelAdvance{
+		TravelRequestID:  request.ID,
+		Status:           models.AdvanceStatusDisbursed,
+		DisbursementDate: dateOnly(disbursementDate),
+	}
+	seen := make(map[uint]bool, len(lines))
+	for _, line := range lines {
+		employee, ok := travellers[line.EmployeeID]
+		if !ok {
+			return nil, fmt.Errorf("%w: employee %d is not on this trip", ErrInvalidAdvance, line.EmployeeID)
+		}
+		if seen[line.EmployeeID] {
+			return nil, fmt.Errorf("%w: employee %d is listed twice", ErrInvalidAdvance, line.EmployeeID)
+		}
+		if line.Amount <= 0 {
+			return nil, fmt.Errorf("%w: amount of employee %d must be positive", ErrInvalidAdvance, line.EmployeeID)
+		}
+		seen[line.EmployeeID] = true
+		advance.Employees = append(advance.Employees, models.TravelAdvanceEmployee{
+			EmployeeID: line.EmployeeID,
+			Employee:   employee,
+			Amount:     line.Amount,
+		})
+		advance.TotalAmount += line.Amount
+	}
+	return advance, nil
+}
+
+// SettleAdvance nets the advance of every employee against their final allowance on request
+// and their items on the approved at-cost claims. A cancelled trip pays no allowance.
+func SettleAdvance(advance *models.TravelAdvance, request *models.TravelRequest, claims []models.AtCostClaim) {
+	allowances := make(map[uint]int, len(request.TravelRequestEmployees))
+	if request.Status != models.TravelStatusCancelled {
+		for _, empRel := range request.TravelRequestEmployees {
+			allowances[empRel.EmployeeID] += empRel.Subtotal
+		}
+	}
+	claimed := make(map[uint]int)
+	for _, claim := range claims {
+		if claim.Status != models.ClaimStatusApproved {
+			continue
+		}
+		for _, item := range claim.ClaimItems {
+			claimed[item.EmployeeID] += item.TotalCost
+		}
+	}
+
+	advance.TotalAllowance, advance.TotalClaims, advance.TotalBalance = 0, 0, 0
+	for i := range advance.Employees {
+		line := &advance.Employees[i]
+		line.Allowance = allowances[line.EmployeeID]
+		line.ClaimAmount = claimed[line.EmployeeID]
+		line.Balance = line.Allowance + line.ClaimAmount - line.Amount
+		advance.TotalAllowance += line.Allowance
+		advance.TotalClaims += line.ClaimAmount
+		advance.TotalBalance += line.Balance
+	}
+}
+
+// AdvanceBalanceLabel describes the balance of a settled advance from the employee's side
+func AdvanceBalanceLabel(balance int) string {
+	switch {
+	case balance > 0:
+		return "Kurang bayar"
+	case balance < 0:
+		return "Lebih bayar"
+	}
+	return "Nihil"
+}
+
+// TravelAdvanceService pays advances to travellers and settles them after the trip
+type TravelAdvanceService struct {
+	repo *repository.Repository
+}
+
+func NewTravelAdvanceService(repo *repository.Repository) *TravelAdvanceService {
+	return &TravelAdvanceService{repo: repo}
+}
+
+// Create records the advance paid to employees of a travel request. A request has at most one
+// advance.
+func (s *TravelAdvanceService) Create(requestID uint, lines []AdvanceLine, disbursementDate time.Time, notes, actor string) (*models.TravelAdvance, error) {
+	var advance *models.TravelAdvance
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if !IsAdvanceableTravelStatus(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrAdvanceNotAllowed, request.Status)
+		}
+
+		var count int64
+		if err := tx.Model(&models.TravelAdvance{}).Where("travel_request_id = ?", requestID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAdvanceExists
+		}
+
+		if err := tx.Preload("Employee.Position").Where("travel_request_id = ?", requestID).Order("id ASC").
+			Find(&request.TravelRequestEmployees).Error; err != nil {
+			return err
+		}
+
+		var err error
+		advance, err = BuildAdvance(&request, lines, disbursementDate)
+		if err != nil {
+			return err
+		}
+		advance.Notes = notes
+		advance.CreatedBy = actor
+
+		if err := tx.Omit("Employees").Create(advance).Error; err != nil {
+			return fmt.Errorf("failed to create advance: %w", err)
+		}
+		for i := range advance.Employees {
+			advance.Employees[i].AdvanceID = advance.ID
+		}
+		if err := tx.Omit("Employee").Create(&advance.Employees).Error; err != nil {
+			return fmt.Errorf("failed to create advance employees: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return advance, nil
+}
+
+// Settle nets the advance of a travel request against the final allowance and the approved
+// at-cost claims of its employees
+func (s *TravelAdvanceService) Settle(requestID uint, actor string) (*models.TravelAdvance, error) {
+	var advance models.TravelAdvance
+	err := s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("travel_request_id = ?", requestID).First(&advance).Error; err != nil {
+			return err
+		}
+		if advance.Status == models.AdvanceStatusSettled {
+			return ErrAdvanceSettled
+		}
+
+		var request models.TravelRequest
+		if err := tx.Preload("TravelRequestEmployees").First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if !IsSettleableTravelStatus(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrAdvanceNotSettleable, request.Status)
+		}
+
+		var claims []models.AtCostClaim
+		if err := tx.Preload("ClaimItems").Where("travel_request_id = ? AND status = ?", requestID, models.ClaimStatusApproved).
+			Find(&claims).Error; err != nil {
+			return fmt.Errorf("failed to get at-cost claims: %w", err)
+		}
+		if err := tx.Where("advance_id = ?", advance.ID).Order("id ASC").Find(&advance.Employees).Error; err != nil {
+			return err
+		}
+
+		SettleAdvance(&advance, &request, claims)
+		now := time.Now()
+		for _, line := range advance.Employees {
+			err := tx.Model(&line).Updates(map[string]interface{}{
+				"allowance":    line.Allowance,
+				"claim_amount": line.ClaimAmount,
+				"balance":      line.Balance,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update advance employee: %w", err)
+			}
+		}
+		err := tx.Model(&advance).Updates(map[string]interface{}{
+			"status":          models.AdvanceStatusSettled,
+			"total_allowance": advance.TotalAllowance,
+			"total_claims":    advance.TotalClaims,
+			"total_balance":   advance.TotalBalance,
+			"settled_at":      now,
+			"settled_by":      actor,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to settle advance: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetTravelAdvanceByTravelRequestID(requestID)
+}
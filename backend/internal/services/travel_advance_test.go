@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestBuildAdvance(t *testing.T) {
+	request := &models.TravelRequest{
+		ID: 7,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, Employee: models.Employee{ID: 1, Name: "Andi"}},
+			{EmployeeID: 2, Employee: models.Employee{ID: 2, Name: "Budi"}},
+		},
+	}
+	date := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	advance, err := BuildAdvance(request, []AdvanceLine{{EmployeeID: 1, Amount: 1000000}, {EmployeeID: 2, Amount: 500000}}, date)
+	if err != nil {
+		t.Fatalf("BuildAdvance failed: %v", err)
+	}
+	if advance.TotalAmount != 1500000 || len(advance.Employees) != 2 || advance.Status != models.AdvanceStatusDisbursed {
+		t.Errorf("Unexpected advance %+v", advance)
+	}
+
+	if _, err := BuildAdvance(request, []AdvanceLine{{EmployeeID: 3, Amount: 100000}}, date); !errors.Is(err, ErrInvalidAdvance) {
+		t.Errorf("Expected an employee outside the trip to be rejected, got %v", err)
+	}
+	if _, err := BuildAdvance(request, []AdvanceLine{{EmployeeID: 1, Amount: 100000}, {EmployeeID: 1, Amount: 100000}}, date); !errors.Is(err, ErrInvalidAdvance) {
+		t.Errorf("Expected a duplicate employee to be rejected, got %v", err)
+	}
+}
+
+func TestSettleAdvance(t *testing.T) {
+	request := &models.TravelRequest{
+		Status: models.TravelStatusCompleted,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, Subtotal: 900000},
+			{EmployeeID: 2, Subtotal: 600000},
+		},
+	}
+	claims := []models.AtCostClaim{
+		{Status: models.ClaimStatusApproved, ClaimItems: []models.AtCostClaimItem{{EmployeeID: 1, TotalCost: 400000}}},
+		{Status: models.ClaimStatusPending, ClaimItems: []models.AtCostClaimItem{{EmployeeID: 2, TotalCost: 300000}}},
+	}
+	advance := &models.TravelAdvance{
+		TotalAmount: 2000000,
+		Employees:   []models.TravelAdvanceEmployee{{EmployeeID: 1, Amount: 1000000}, {EmployeeID: 2, Amount: 1000000}},
+	}
+
+	SettleAdvance(advance, request, claims)
+	if got := advance.Employees[0]; got.Allowance != 900000 || got.ClaimAmount != 400000 || got.Balance != 300000 {
+		t.Errorf("Expected employee 1 to be owed Rp 300.000, got %+v", got)
+	}
+	if got := advance.Employees[1]; got.ClaimAmount != 0 || got.Balance != -400000 {
+		t.Errorf("Expected employee 2 to return Rp 400.000 without the pending claim, got %+v", got)
+	}
+	if advance.TotalBalance != -100000 || AdvanceBalanceLabel(advance.Employees[1].Balance) != "Lebih bayar" {
+		t.Errorf("Unexpected totals %+v", advance)
+	}
+
+	request.Status = models.TravelStatusCancelled
+	SettleAdvance(advance, request, nil)
+	if advance.TotalAllowance != 0 || advance.TotalBalance != -2000000 {
+		t.Errorf("Expected a cancelled trip to return the whole advance, got %+v", advance)
+	}
+}
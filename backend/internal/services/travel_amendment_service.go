@@ -0,0 +1,409 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAmendmentNotAllowed    = errors.New("only approved, in progress or completed travel requests can be amended")
+	ErrAmendmentNoChange      = errors.New("new return date is the same as the current one")
+	ErrAmendmentInvalidDate   = errors.New("new return date cannot be before departure date")
+	ErrAmendmentReasonMissing = errors.New("reason is required for an amendment")
+)
+
+// IsAmendableTravelStatus reports whether the return date of a request in this status is
+// changed through an amendment. Earlier statuses are simply edited.
+func IsAmendableTravelStatus(status string) bool {
+	switch status {
+	case models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// CalculateAmendment works out the new duration and the allowance of every traveller when the
+// return date of request moves to newReturnDate, arriving at newReturnTime (HH:MM, may be empty).
+// Each traveller keeps the daily rate of the original request; legacy rows without a stored rate
+// use their position rate. A single-destination trip is paid under rule, the partial day rule of
+// its destination type (nil = every day in full), like a new request. On a multi-leg trip only
+// the last leg moves, the added or removed days are paid at the rate of that leg.
+func CalculateAmendment(request *models.TravelRequest, newReturnDate time.Time, newReturnTime string, rule *models.PartialDayRule, calc *AllowanceCalculator) (*models.TravelAmendment, error) {
+	// The new return date is a day at the place of return, like the old one
+	timeZone := request.ReturnTimeZone
+	if timeZone == "" {
+		timeZone = utils.DefaultTimeZone
+	}
+	newReturnDate = LocalDate(newReturnDate, timeZone)
+	if dateOnly(newReturnDate).Before(dateOnly(request.DepartureDate)) {
+		return nil, ErrAmendmentInvalidDate
+	}
+	lastLeg := lastTravelLeg(request)
+	if lastLeg != nil && dateOnly(newReturnDate).Before(dateOnly(lastLeg.DepartureDate)) {
+		return nil, fmt.Errorf("%w: the last leg departs on %s", ErrAmendmentInvalidDate, lastLeg.DepartureDate.Format("2006-01-02"))
+	}
+	if dateOnly(newReturnDate).Equal(dateOnly(request.ReturnDate)) {
+		return nil, ErrAmendmentNoChange
+	}
+	newReturnTime = strings.TrimSpace(newReturnTime)
+	if err := ValidateTravelTimes(request.DepartureDate, newReturnDate, request.DepartureTime, newReturnTime); err != nil {
+		return nil, err
+	}
+
+	amendment := &models.TravelAmendment{
+		TravelRequestID:   request.ID,
+		Type:              models.AmendmentTypeExtension,
+		OldReturnDate:     request.ReturnDate,
+		NewReturnDate:     newReturnDate,
+		TimeZone:          timeZone,
+		OldReturnTime:     request.ReturnTime,
+		NewReturnTime:     newReturnTime,
+		OldDurationDays:   request.DurationDays,
+		OldTotalAllowance: request.TotalAllowance,
+	}
+	days := AmendedAllowanceDays(request, amendment, rule)
+	amendment.NewDurationDays = days.CalendarDays
+	if dateOnly(newReturnDate).Before(dateOnly(request.ReturnDate)) {
+		amendment.Type = models.AmendmentTypeEarlyReturn
+	}
+
+	for _, empRel := range request.TravelRequestEmployees {
+		rate := empRel.DailyRate
+		zone := RequestZone(request)
+		if lastLeg != nil {
+			rate = legDailyRate(lastLeg, empRel.EmployeeID)
+			zone = LegZone(*lastLeg)
+		}
+		if rate == 0 {
+			var err error
+			rate, err = calc.ZoneRate(empRel.Employee.Position, zone)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		oldDays := empRel.DurationDays
+		if oldDays == 0 {
+			oldDays = request.DurationDays
+		}
+		oldSubtotal := EmployeeAllowanceAmount(request, empRel)
+		newSubtotal := days.Amount(rate)
+		if lastLeg != nil {
+			newSubtotal = oldSubtotal + rate*(amendment.NewDurationDays-oldDays)
+		}
+
+		amendment.Employees = append(amendment.Employees, models.TravelAmendmentEmployee{
+			EmployeeID:      empRel.EmployeeID,
+			Employee:        empRel.Employee,
+			DailyRate:       rate,
+			OldDurationDays: oldDays,
+			NewDurationDays: amendment.NewDurationDays,
+			OldSubtotal:     oldSubtotal,
+			NewSubtotal:     newSubtotal,
+			Delta:           newSubtotal - oldSubtotal,
+		})
+		amendment.NewTotalAllowance += newSubtotal
+	}
+
+	for _, participant := range request.Participants {
+		if !participant.ReceivesAllowance {
+			continue
+		}
+
+		rate := participant.DailyRate
+		zone := RequestZone(request)
+		if lastLeg != nil {
+			rate = legParticipantRate(lastLeg, participant.ID)
+			zone = LegZone(*lastLeg)
+		}
+		if rate == 0 {
+			var err error
+			rate, err = calc.participantRate(&participant, zone)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		oldDays := participant.DurationDays
+		if oldDays == 0 {
+			oldDays = request.DurationDays
+		}
+		newSubtotal := days.Amount(rate)
+		if lastLeg != nil {
+			newSubtotal = participant.Subtotal + rate*(amendment.NewDurationDays-oldDays)
+		}
+
+		amendment.Participants = append(amendment.Participants, models.TravelAmendmentParticipant{
+			ParticipantID:   participant.ID,
+			Participant:     participant,
+			DailyRate:       rate,
+			OldDurationDays: oldDays,
+			NewDurationDays: amendment.NewDurationDays,
+			OldSubtotal:     participant.Subtotal,
+			NewSubtotal:     newSubtotal,
+			Delta:           newSubtotal - participant.Subtotal,
+		})
+		amendment.NewTotalAllowance += newSubtotal
+	}
+
+	return amendment, nil
+}
+
+// AmendedAllowanceDays returns the paid days of request once it returns on the new return date of
+// amendment. Partial day rules only apply to single-destination requests.
+func AmendedAllowanceDays(request *models.TravelRequest, amendment *models.TravelAmendment, rule *models.PartialDayRule) AllowanceDays {
+	if len(request.Legs) > 0 {
+		return FullAllowanceDays(repository.CalculateDurationDays(request.DepartureDate, amendment.NewReturnDate))
+	}
+	return PartialAllowanceDays(rule, request.DepartureDate, amendment.NewReturnDate, request.DepartureTime, amendment.NewReturnTime)
+}
+
+// lastTravelLeg returns the last leg of a multi-leg request, nil for a single destination
+func lastTravelLeg(request *models.TravelRequest) *models.TravelLeg {
+	if len(request.Legs) == 0 {
+		return nil
+	}
+	return &request.Legs[len(request.Legs)-1]
+}
+
+// legDailyRate returns the rate paid to an employee on a leg, 0 when not recorded
+func legDailyRate(leg *models.TravelLeg, employeeID uint) int {
+	for _, allowance := range leg.Allowances {
+		if allowance.EmployeeID == employeeID {
+			return allowance.DailyRate
+		}
+	}
+	return 0
+}
+
+// legParticipantRate returns the rate paid to an external participant on a leg, 0 when not recorded
+func legParticipantRate(leg *models.TravelLeg, participantID uint) int {
+	for _, allowance := range leg.Allowances {
+		if allowance.ParticipantID != nil && *allowance.ParticipantID == participantID {
+			return allowance.DailyRate
+		}
+	}
+	return 0
+}
+
+// TravelAmendmentService records extensions and early returns of approved trips
+type TravelAmendmentService struct {
+	repo *repository.Repository
+	calc *AllowanceCalculator
+}
+
+func NewTravelAmendmentService(repo *repository.Repository) *TravelAmendmentService {
+	return &TravelAmendmentService{
+		repo: repo,
+		calc: NewAllowanceCalculator(),
+	}
+}
+
+// Amend moves the return date and time of a travel request, recalculates every traveller's
+// allowance and records the amendment with its reason and approver
+func (s *TravelAmendmentService) Amend(id uint, newReturnDate time.Time, newReturnTime, reason string, approverID uint, actor string) (*models.TravelAmendment, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrAmendmentReasonMissing
+	}
+
+	approver, err := s.repo.GetAdminByID(approverID)
+	if err != nil {
+		return nil, fmt.Errorf("approver not found: %w", err)
+	}
+	approverName := approver.Name
+	if approverName == "" {
+		approverName = approver.Username
+	}
+
+	rules, err := s.repo.GetActivePartialDayRules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load partial day rules: %w", err)
+	}
+
+	var amendment *models.TravelAmendment
+	err = s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			return err
+		}
+		if !IsAmendableTravelStatus(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrAmendmentNotAllowed, request.Status)
+		}
+		if err := tx.Preload("Employee.Position").Where("travel_request_id = ?", id).Order("id ASC").
+			Find(&request.TravelRequestEmployees).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Allowances").Where("travel_request_id = ?", id).Order("sequence ASC").
+			Find(&request.Legs).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("RatePosition").Where("travel_request_id = ?", id).Order("id ASC").
+			Find(&request.Participants).Error; err != nil {
+			return err
+		}
+
+		var rule *models.PartialDayRule
+		if active, ok := rules[request.DestinationType]; ok {
+			rule = &active
+		}
+		amendment, err = CalculateAmendment(&request, newReturnDate, newReturnTime, rule, s.calc)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.TravelAmendment{}).Where("travel_request_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		amendment.AmendmentNumber = int(count) + 1
+		amendment.Reason = reason
+		amendment.ApproverID = approver.ID
+		amendment.ApproverName = approverName
+		amendment.ApproverPosition = approver.Position
+		amendment.CreatedBy = actor
+
+		if err := tx.Omit("Employees", "Participants").Create(amendment).Error; err != nil {
+			return fmt.Errorf("failed to create amendment: %w", err)
+		}
+		for i := range amendment.Employees {
+			amendment.Employees[i].AmendmentID = amendment.ID
+		}
+		if len(amendment.Employees) > 0 {
+			if err := tx.Omit("Employee").Create(&amendment.Employees).Error; err != nil {
+				return fmt.Errorf("failed to create amendment employees: %w", err)
+			}
+		}
+		for i := range amendment.Participants {
+			amendment.Participants[i].AmendmentID = amendment.ID
+		}
+		if len(amendment.Participants) > 0 {
+			if err := tx.Omit("Participant").Create(&amendment.Participants).Error; err != nil {
+				return fmt.Errorf("failed to create amendment participants: %w", err)
+			}
+		}
+
+		// Weekend days and holidays follow the new return date
+		calendar, err := holidayCalendarBetween(tx, request.DepartureDate, amendment.NewReturnDate)
+		if err != nil {
+			return err
+		}
+		dayCounts := calendar.CountDays(request.DepartureDate, amendment.NewReturnDate)
+
+		// The paid days describe the new return day
+		ApplyAllowanceDays(&request, AmendedAllowanceDays(&request, amendment, rule))
+
+		err = tx.Model(&request).Updates(map[string]interface{}{
+			"return_date":           amendment.NewReturnDate,
+			"return_time":           amendment.NewReturnTime,
+			"duration_days":         amendment.NewDurationDays,
+			"partial_days":          request.PartialDays,
+			"departure_day_percent": request.DepartureDayPercent,
+			"return_day_percent":    request.ReturnDayPercent,
+			"paid_day_percent":      request.PaidDayPercent,
+			"total_allowance":       amendment.NewTotalAllowance,
+			"weekend_days":          dayCounts.WeekendDays,
+			"holiday_days":          dayCounts.HolidayDays,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update travel request: %w", err)
+		}
+
+		for i, empRel := range request.TravelRequestEmployees {
+			change := amendment.Employees[i]
+			err := tx.Model(&empRel).Updates(map[string]interface{}{
+				"daily_rate":    change.DailyRate,
+				"duration_days": change.NewDurationDays,
+				"subtotal":      change.NewSubtotal,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update employee allowance: %w", err)
+			}
+		}
+
+		// Unpaid participants only follow the new duration
+		err = tx.Model(&models.TravelParticipant{}).Where("travel_request_id = ?", id).
+			Update("duration_days", amendment.NewDurationDays).Error
+		if err != nil {
+			return fmt.Errorf("failed to update participants: %w", err)
+		}
+		for _, change := range amendment.Participants {
+			err := tx.Model(&models.TravelParticipant{}).Where("id = ?", change.ParticipantID).Updates(map[string]interface{}{
+				"daily_rate": change.DailyRate,
+				"subtotal":   change.NewSubtotal,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update participant allowance: %w", err)
+			}
+		}
+
+		if leg := lastTravelLeg(&request); leg != nil {
+			return amendLastLegTx(tx, leg, amendment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return amendment, nil
+}
+
+// AmendmentDayDelta returns the days an amendment adds to the trip, negative for an early return
+func AmendmentDayDelta(amendment *models.TravelAmendment) int {
+	return amendment.NewDurationDays - amendment.OldDurationDays
+}
+
+// amendLastLegTx moves the arrival of the last leg and adds the amended days to its allowances
+func amendLastLegTx(tx *gorm.DB, leg *models.TravelLeg, amendment *models.TravelAmendment) error {
+	dayDelta := AmendmentDayDelta(amendment)
+	err := tx.Model(leg).Updates(map[string]interface{}{
+		"arrival_date":   amendment.NewReturnDate,
+		"allowance_days": leg.AllowanceDays + dayDelta,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update travel leg: %w", err)
+	}
+
+	for _, allowance := range leg.Allowances {
+		delta := 0
+		for _, change := range amendment.Employees {
+			if allowance.ParticipantID == nil && allowance.EmployeeID == change.EmployeeID {
+				delta = change.Delta
+			}
+		}
+		for _, change := range amendment.Participants {
+			if allowance.ParticipantID != nil && *allowance.ParticipantID == change.ParticipantID {
+				delta = change.Delta
+			}
+		}
+
+		err := tx.Model(&allowance).Updates(map[string]interface{}{
+			"days":     allowance.Days + dayDelta,
+			"subtotal": allowance.Subtotal + delta,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update leg allowance: %w", err)
+		}
+	}
+	return nil
+}
+
+// AmendmentDeltaByEmployee sums the allowance change of all amendments of a request per employee
+func AmendmentDeltaByEmployee(request *models.TravelRequest) map[uint]int {
+	deltas := make(map[uint]int)
+	for _, amendment := range request.Amendments {
+		for _, change := range amendment.Employees {
+			deltas[change.EmployeeID] += change.Delta
+		}
+	}
+	return deltas
+}
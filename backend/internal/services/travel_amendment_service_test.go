@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestCalculateAmendment(t *testing.T) {
+	request := &models.TravelRequest{
+		ID:              7,
+		DestinationType: "outside_province",
+		DepartureDate:   time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+		ReturnDate:      time.Date(2025, 5, 7, 0, 0, 0, 0, time.UTC),
+		DurationDays:    3,
+		TotalAllowance:  1500000,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, DailyRate: 300000, DurationDays: 3, Subtotal: 900000},
+			{EmployeeID: 2, DailyRate: 200000, DurationDays: 3, Subtotal: 600000},
+		},
+	}
+	calc := NewAllowanceCalculator()
+
+	extension, err := CalculateAmendment(request, time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC), "", nil, calc)
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if extension.Type != models.AmendmentTypeExtension || extension.NewDurationDays != 4 {
+		t.Errorf("Expected 4 day extension, got %s of %d days", extension.Type, extension.NewDurationDays)
+	}
+	if extension.NewTotalAllowance != 2000000 {
+		t.Errorf("Expected new total 2000000, got %d", extension.NewTotalAllowance)
+	}
+	if got := extension.Employees[0].Delta; got != 300000 {
+		t.Errorf("Expected first employee delta 300000, got %d", got)
+	}
+
+	early, err := CalculateAmendment(request, time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC), "", nil, calc)
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if early.Type != models.AmendmentTypeEarlyReturn || early.Employees[1].Delta != -400000 {
+		t.Errorf("Expected early return with delta -400000, got %s with %d", early.Type, early.Employees[1].Delta)
+	}
+
+	if _, err := CalculateAmendment(request, request.ReturnDate, "", nil, calc); !errors.Is(err, ErrAmendmentNoChange) {
+		t.Errorf("Expected ErrAmendmentNoChange, got %v", err)
+	}
+	if _, err := CalculateAmendment(request, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), "", nil, calc); !errors.Is(err, ErrAmendmentInvalidDate) {
+		t.Errorf("Expected ErrAmendmentInvalidDate, got %v", err)
+	}
+}
+
+func TestCalculateAmendmentPartialDays(t *testing.T) {
+	rule := &models.PartialDayRule{ShortTripMaxHours: 8, ShortTripPercent: 50, DepartureDayPercent: 100, ReturnDayPercent: 75}
+	request := &models.TravelRequest{
+		DestinationType:     "outside_province",
+		DepartureDate:       time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+		ReturnDate:          time.Date(2025, 5, 7, 0, 0, 0, 0, time.UTC),
+		DepartureTime:       "07:00",
+		ReturnTime:          "18:00",
+		DurationDays:        3,
+		PartialDays:         true,
+		DepartureDayPercent: 100,
+		ReturnDayPercent:    75,
+		PaidDayPercent:      275,
+		TotalAllowance:      1100000,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, DailyRate: 400000, DurationDays: 3, Subtotal: 1100000},
+		},
+	}
+
+	// Four calendar days: two full days, the departure day in full and the return day at 75%
+	amendment, err := CalculateAmendment(request, time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC), "20:00", rule, NewAllowanceCalculator())
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if amendment.NewDurationDays != 4 || amendment.Employees[0].NewSubtotal != 1500000 || amendment.Employees[0].Delta != 400000 {
+		t.Errorf("Expected 3,75 paid days of Rp 400.000, got %d days and %+v", amendment.NewDurationDays, amendment.Employees[0])
+	}
+	if amendment.OldReturnTime != "18:00" || amendment.NewReturnTime != "20:00" {
+		t.Errorf("Expected the return time to move to 20:00, got %q -> %q", amendment.OldReturnTime, amendment.NewReturnTime)
+	}
+
+	days := AmendedAllowanceDays(request, amendment, rule)
+	ApplyAllowanceDays(request, days)
+	request.DurationDays = amendment.NewDurationDays
+	if request.PaidDayPercent != 375 || DescribeAllowanceDays(RequestAllowanceDays(request)) != DescribeAllowanceDays(days) {
+		t.Errorf("Expected the stored breakdown to match the amended subtotals, got %+v", request)
+	}
+
+	// Returning the same day shortly after departing is a short trip
+	short, err := CalculateAmendment(request, request.DepartureDate, "12:00", rule, NewAllowanceCalculator())
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if short.NewDurationDays != 1 || short.Employees[0].NewSubtotal != 200000 {
+		t.Errorf("Expected a short trip paid at 50%%, got %+v", short.Employees[0])
+	}
+	if _, err := CalculateAmendment(request, request.DepartureDate, "06:00", rule, NewAllowanceCalculator()); !errors.Is(err, ErrInvalidTravelTime) {
+		t.Errorf("Expected a return before departure to be rejected, got %v", err)
+	}
+}
+
+func TestCalculateAmendmentLastLegDays(t *testing.T) {
+	legs := testItinerary()
+	employee := models.Employee{ID: 1, Position: models.Position{AllowanceInProvince: 100000, AllowanceOutsideProvince: 200000}}
+	allowances, total, err := NewAllowanceCalculator().CalculateItinerary([]models.Employee{employee}, legs)
+	if err != nil {
+		t.Fatalf("CalculateItinerary failed: %v", err)
+	}
+	request := &models.TravelRequest{ID: 9, TotalAllowance: total}
+	ApplyItinerary(request, legs)
+	request.TravelRequestEmployees = []models.TravelRequestEmployee{{
+		EmployeeID: 1, Employee: employee, DailyRate: allowances[0].DailyRate,
+		DurationDays: allowances[0].DurationDays, Subtotal: allowances[0].Subtotal,
+	}}
+
+	amendment, err := CalculateAmendment(request, request.ReturnDate.AddDate(0, 0, 2), "", nil, NewAllowanceCalculator())
+	if err != nil {
+		t.Fatalf("CalculateAmendment failed: %v", err)
+	}
+	if amendment.OldDurationDays != 4 || amendment.NewDurationDays != 6 {
+		t.Errorf("Expected 4 days extended to 6, got %d to %d", amendment.OldDurationDays, amendment.NewDurationDays)
+	}
+	// Only the two added days go to the last leg, not the whole trip again
+	if got := AmendmentDayDelta(amendment); got != 2 {
+		t.Errorf("Expected the last leg to gain 2 days, got %d", got)
+	}
+	if got := amendment.Employees[0].Delta; got != 200000 {
+		t.Errorf("Expected 2 extra days in province = 200000, got %d", got)
+	}
+}
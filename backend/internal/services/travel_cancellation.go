@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ClaimStatusAfterCancellation returns what happens to an at-cost claim when its trip is
+// cancelled: paid claims must be refunded, claims still under review become void.
+// Claims that are already rejected, void or refunded are left alone.
+func ClaimStatusAfterCancellation(status string) (string, bool) {
+	switch status {
+	case models.ClaimStatusApproved:
+		return models.ClaimStatusRefundPending, true
+	case models.ClaimStatusPending, "":
+		return models.ClaimStatusVoid, true
+	}
+	return status, false
+}
+
+// cancelTx records the cancellation of a travel request and settles its at-cost claims.
+// It runs inside the status transition to cancelled, so every way of cancelling behaves the same.
+func (w *TravelRequestWorkflow) cancelTx(tx *gorm.DB, request *models.TravelRequest, actor, reason string) error {
+	now := time.Now()
+	err := tx.Model(request).Updates(map[string]interface{}{
+		"cancelled_at":        now,
+		"cancelled_by":        actor,
+		"cancellation_reason": reason,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record cancellation: %w", err)
+	}
+	request.CancelledAt = &now
+	request.CancelledBy = actor
+	request.CancellationReason = reason
+
+	var claims []models.AtCostClaim
+	if err := tx.Where("travel_request_id = ?", request.ID).Find(&claims).Error; err != nil {
+		return fmt.Errorf("failed to get at-cost claims: %w", err)
+	}
+
+	for _, claim := range claims {
+		status, changed := ClaimStatusAfterCancellation(claim.Status)
+		if !changed {
+			continue
+		}
+
+		updates := map[string]interface{}{"status": status}
+		if status == models.ClaimStatusRefundPending {
+			updates["refund_amount"] = claim.TotalAmount
+		}
+		if err := tx.Model(&claim).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update at-cost claim: %w", err)
+		}
+		if err := w.approvals.SkipPendingTx(tx, models.ApprovalDocAtCostClaim, claim.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
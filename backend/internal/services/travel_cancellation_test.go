@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestClaimStatusAfterCancellation(t *testing.T) {
+	tests := []struct {
+		status  string
+		want    string
+		changed bool
+	}{
+		{models.ClaimStatusApproved, models.ClaimStatusRefundPending, true},
+		{models.ClaimStatusPending, models.ClaimStatusVoid, true},
+		{"", models.ClaimStatusVoid, true},
+		{models.ClaimStatusRejected, models.ClaimStatusRejected, false},
+		{models.ClaimStatusRefunded, models.ClaimStatusRefunded, false},
+		{models.ClaimStatusVoid, models.ClaimStatusVoid, false},
+	}
+
+	for _, tt := range tests {
+		got, changed := ClaimStatusAfterCancellation(tt.status)
+		if got != tt.want || changed != tt.changed {
+			t.Errorf("ClaimStatusAfterCancellation(%q) = %q, %v; want %q, %v", tt.status, got, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+func TestCheckClaimStatusChange(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want error
+	}{
+		{models.ClaimStatusPending, models.ClaimStatusApproved, nil},
+		{models.ClaimStatusApproved, models.ClaimStatusRejected, nil},
+		{models.ClaimStatusRefundPending, models.ClaimStatusRefunded, nil},
+		{models.ClaimStatusApproved, models.ClaimStatusRefunded, ErrClaimNotRefundable},
+		{models.ClaimStatusVoid, models.ClaimStatusRefunded, ErrClaimNotRefundable},
+		{models.ClaimStatusVoid, models.ClaimStatusApproved, ErrClaimStatusLocked},
+		{models.ClaimStatusRefundPending, models.ClaimStatusPending, ErrClaimStatusLocked},
+		{models.ClaimStatusRefunded, models.ClaimStatusApproved, ErrClaimStatusLocked},
+	}
+
+	for _, tt := range tests {
+		err := CheckClaimStatusChange(tt.from, tt.to)
+		if (tt.want == nil && err != nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("CheckClaimStatusChange(%q, %q) = %v; want %v", tt.from, tt.to, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrScheduleConflict             = errors.New("employees are already assigned to overlapping trips")
+	ErrOverrideNotAllowed           = errors.New("only admins can override schedule conflicts")
+	ErrOverrideJustificationMissing = errors.New("justification is required to override schedule conflicts")
+)
+
+// scheduledTravelStatuses are the statuses of trips that hold their travellers. Drafts do not
+// yet, and rejected, cancelled or completed trips no longer do.
+var scheduledTravelStatuses = []string{models.TravelStatusSubmitted, models.TravelStatusApproved, models.TravelStatusInProgress}
+
+// countedTravelStatuses are the statuses of trips counted against the travel quota
+var countedTravelStatuses = []string{models.TravelStatusSubmitted, models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted}
+
+// TripAssignment is one employee assigned to one trip. Its dates are calendar dates at the
+// places of departure and return.
+type TripAssignment struct {
+	EmployeeID      uint      `json:"employee_id"`
+	NIP             string    `json:"nip"`
+	EmployeeName    string    `json:"employee_name"`
+	TravelRequestID uint      `json:"travel_request_id"`
+	RequestNumber   string    `json:"request_number"`
+	Destination     string    `json:"destination"`
+	Status          string    `json:"status"`
+	DepartureDate   time.Time `json:"departure_date"`
+	ReturnDate      time.Time `json:"return_date"`
+
+	DepartureTimeZone string `json:"-"`
+	ReturnTimeZone    string `json:"-"`
+}
+
+// TravelConflict is a pair of trips of the same employee whose dates overlap.
+// Trips are inclusive of both ends, so returning and leaving on the same day overlaps.
+type TravelConflict struct {
+	EmployeeID   uint           `json:"employee_id"`
+	NIP          string         `json:"nip"`
+	EmployeeName string         `json:"employee_name"`
+	Trip         TripAssignment `json:"trip"`
+	ConflictWith TripAssignment `json:"conflict_with"`
+	OverlapStart time.Time      `json:"overlap_start"`
+	OverlapEnd   time.Time      `json:"overlap_end"`
+	OverlapDays  int            `json:"overlap_days"`
+}
+
+// DatesOverlap reports whether two inclusive date ranges share at least one day
+func DatesOverlap(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return !aStart.After(bEnd) && !bStart.After(aEnd)
+}
+
+// newTravelConflict describes the overlap of trip with other
+func newTravelConflict(trip, other TripAssignment) TravelConflict {
+	start, end := trip.DepartureDate, trip.ReturnDate
+	if other.DepartureDate.After(start) {
+		start = other.DepartureDate
+	}
+	if other.ReturnDate.Before(end) {
+		end = other.ReturnDate
+	}
+	return TravelConflict{
+		EmployeeID:   trip.EmployeeID,
+		NIP:          trip.NIP,
+		EmployeeName: trip.EmployeeName,
+		Trip:         trip,
+		ConflictWith: other,
+		OverlapStart: start,
+		OverlapEnd:   end,
+		OverlapDays:  repository.CalculateDurationDays(start, end),
+	}
+}
+
+// DetectConflicts lists every pair of assignments of the same employee on different trips
+// whose dates overlap, ordered by employee and then by the earlier trip
+func DetectConflicts(assignments []TripAssignment) []TravelConflict {
+	sorted := make([]TripAssignment, len(assignments))
+	copy(sorted, assignments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].EmployeeID != sorted[j].EmployeeID {
+			return sorted[i].EmployeeID < sorted[j].EmployeeID
+		}
+		if !sorted[i].DepartureDate.Equal(sorted[j].DepartureDate) {
+			return sorted[i].DepartureDate.Before(sorted[j].DepartureDate)
+		}
+		return sorted[i].TravelRequestID < sorted[j].TravelRequestID
+	})
+
+	var conflicts []TravelConflict
+	for i, trip := range sorted {
+		for _, other := range sorted[i+1:] {
+			if other.EmployeeID != trip.EmployeeID || other.DepartureDate.After(trip.ReturnDate) {
+				break
+			}
+			if other.TravelRequestID == trip.TravelRequestID {
+				continue
+			}
+			conflicts = append(conflicts, newTravelConflict(trip, other))
+		}
+	}
+	return conflicts
+}
+
+// TravelConflictService finds employees booked on overlapping trips
+type TravelConflictService struct {
+	repo *repository.Repository
+}
+
+func NewTravelConflictService(repo *repository.Repository) *TravelConflictService {
+	return &TravelConflictService{repo: repo}
+}
+
+// ConflictOverride is the request of an admin to save a trip despite its schedule conflicts
+type ConflictOverride struct {
+	Requested     bool
+	Admin         string // Username of the admin, empty when not authenticated
+	Justification string
+}
+
+// ConflictError carries the conflicts that stopped a request
+type ConflictError struct {
+	Err       error
+	Conflicts []TravelConflict
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// activeTripAssignments returns the assignments of scheduled trips that touch [from, to],
+// optionally limited to some employees and leaving one request out
+func activeTripAssignments(db *gorm.DB, employeeIDs []uint, from, to time.Time, excludeRequestID uint) ([]TripAssignment, error) {
+	return tripAssignments(db, scheduledTravelStatuses, employeeIDs, from, to, excludeRequestID)
+}
+
+// tripAssignments returns the assignments of trips in statuses that touch [from, to],
+// optionally limited to some employees and leaving one request out
+func tripAssignments(db *gorm.DB, statuses []string, employeeIDs []uint, from, to time.Time, excludeRequestID uint) ([]TripAssignment, error) {
+	query := db.Table("travel_request_employees AS tre").
+		Select("tre.employee_id, e.nip, e.name AS employee_name, tr.id AS travel_request_id, "+
+			"tr.request_number, tr.destination, tr.status, tr.departure_date, tr.return_date, "+
+			"tr.departure_time_zone, tr.return_time_zone").
+		Joins("JOIN travel_requests tr ON tr.id = tre.travel_request_id AND tr.deleted_at IS NULL").
+		Joins("JOIN employees e ON e.id = tre.employee_id").
+		Where("tre.deleted_at IS NULL").
+		Where("tr.status IN ?", statuses).
+		// A day of margin on both sides catches trips whose local dates touch the range
+		Where("tr.departure_date <= ? AND tr.return_date >= ?", dateOnly(to).AddDate(0, 0, 1), dateOnly(from).AddDate(0, 0, -1))
+	if len(employeeIDs) > 0 {
+		query = query.Where("tre.employee_id IN ?", employeeIDs)
+	}
+	if excludeRequestID != 0 {
+		query = query.Where("tr.id <> ?", excludeRequestID)
+	}
+
+	var assignments []TripAssignment
+	if err := query.Order("tre.employee_id, tr.departure_date, tr.id").Scan(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	active := assignments[:0]
+	for _, assignment := range assignments {
+		assignment.DepartureDate = dateOnly(utils.InTimeZone(assignment.DepartureDate, assignment.DepartureTimeZone))
+		assignment.ReturnDate = dateOnly(utils.InTimeZone(assignment.ReturnDate, assignment.ReturnTimeZone))
+		if DatesOverlap(assignment.DepartureDate, assignment.ReturnDate, dateOnly(from), dateOnly(to)) {
+			active = append(active, assignment)
+		}
+	}
+	return active, nil
+}
+
+// FindConflicts returns the existing trips that overlap request for any of its employees.
+// excludeRequestID leaves the request itself out when it is being edited.
+func (s *TravelConflictService) FindConflicts(request *models.TravelRequest, employees []models.Employee, excludeRequestID uint) ([]TravelConflict, error) {
+	return findConflicts(s.repo.GetDB(), request, employees, excludeRequestID)
+}
+
+// CheckConflictsTx refuses request with a ConflictError when its employees are already on
+// another trip at the same dates, unless override lets an admin save it, which is recorded on
+// request. The employees' rows are locked first, so requests for the same employee saved at the
+// same time are checked one after the other.
+func (s *TravelConflictService) CheckConflictsTx(tx *gorm.DB, request *models.TravelRequest, employees []models.Employee, excludeRequestID uint, override ConflictOverride) error {
+	if err := lockEmployeesTx(tx, employees); err != nil {
+		return err
+	}
+
+	conflicts, err := findConflicts(tx, request, employees, excludeRequestID)
+	if err != nil {
+		return err
+	}
+	request.ConflictOverrideBy = ""
+	request.ConflictOverrideReason = ""
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	switch {
+	case !override.Requested:
+		return &ConflictError{Err: ErrScheduleConflict, Conflicts: conflicts}
+	case override.Admin == "":
+		return &ConflictError{Err: ErrOverrideNotAllowed, Conflicts: conflicts}
+	case strings.TrimSpace(override.Justification) == "":
+		return &ConflictError{Err: ErrOverrideJustificationMissing, Conflicts: conflicts}
+	}
+	request.ConflictOverrideBy = override.Admin
+	request.ConflictOverrideReason = override.Justification
+	return nil
+}
+
+// lockEmployeesTx locks the rows of employees in id order until tx ends, so requests booking
+// the same employees are checked one after the other
+func lockEmployeesTx(tx *gorm.DB, employees []models.Employee) error {
+	employeeIDs := make([]uint, 0, len(employees))
+	for _, employee := range employees {
+		employeeIDs = append(employeeIDs, employee.ID)
+	}
+	if len(employeeIDs) == 0 {
+		return nil
+	}
+	var locked []models.Employee
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("id IN ?", employeeIDs).Order("id ASC").Find(&locked).Error
+}
+
+func findConflicts(db *gorm.DB, request *models.TravelRequest, employees []models.Employee, excludeRequestID uint) ([]TravelConflict, error) {
+	employeeIDs := make([]uint, 0, len(employees))
+	for _, employee := range employees {
+		employeeIDs = append(employeeIDs, employee.ID)
+	}
+
+	existing, err := activeTripAssignments(db, employeeIDs, request.DepartureDate, request.ReturnDate, excludeRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []TravelConflict
+	for _, employee := range employees {
+		trip := TripAssignment{
+			EmployeeID:      employee.ID,
+			NIP:             employee.NIP,
+			EmployeeName:    employee.Name,
+			TravelRequestID: excludeRequestID,
+			RequestNumber:   request.RequestNumber,
+			Destination:     request.Destination,
+			Status:          request.Status,
+			DepartureDate:   dateOnly(request.DepartureDate),
+			ReturnDate:      dateOnly(request.ReturnDate),
+		}
+		for _, other := range existing {
+			if other.EmployeeID == employee.ID {
+				conflicts = append(conflicts, newTravelConflict(trip, other))
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// ConflictReport lists every overlapping pair of trips of the same employee with at least
+// one day inside [from, to]
+func (s *TravelConflictService) ConflictReport(from, to time.Time) ([]TravelConflict, error) {
+	assignments, err := activeTripAssignments(s.repo.GetDB(), nil, from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := DetectConflicts(assignments)
+	report := make([]TravelConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		if DatesOverlap(conflict.OverlapStart, conflict.OverlapEnd, from, to) {
+			report = append(report, conflict)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	assignments := []TripAssignment{
+		{EmployeeID: 1, TravelRequestID: 10, DepartureDate: day(2), ReturnDate: day(5)},
+		{EmployeeID: 1, TravelRequestID: 11, DepartureDate: day(5), ReturnDate: day(6)},
+		{EmployeeID: 1, TravelRequestID: 12, DepartureDate: day(8), ReturnDate: day(9)},
+		{EmployeeID: 2, TravelRequestID: 11, DepartureDate: day(5), ReturnDate: day(6)},
+		{EmployeeID: 2, TravelRequestID: 13, DepartureDate: day(1), ReturnDate: day(10)},
+		{EmployeeID: 3, TravelRequestID: 14, DepartureDate: day(1), ReturnDate: day(3)},
+		{EmployeeID: 3, TravelRequestID: 14, DepartureDate: day(1), ReturnDate: day(3)},
+	}
+
+	conflicts := DetectConflicts(assignments)
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %d", len(conflicts))
+	}
+
+	handover := conflicts[0]
+	if handover.EmployeeID != 1 || handover.Trip.TravelRequestID != 10 || handover.ConflictWith.TravelRequestID != 11 {
+		t.Errorf("Expected employee 1 conflict between 10 and 11, got %+v", handover)
+	}
+	if handover.OverlapDays != 1 || !handover.OverlapStart.Equal(day(5)) {
+		t.Errorf("Expected same-day handover to overlap 1 day on the 5th, got %d from %s", handover.OverlapDays, handover.OverlapStart)
+	}
+
+	nested := conflicts[1]
+	if nested.EmployeeID != 2 || nested.Trip.TravelRequestID != 13 || nested.OverlapDays != 2 {
+		t.Errorf("Expected employee 2 to overlap 2 days between 13 and 11, got %+v", nested)
+	}
+}
+
+func TestDatesOverlap(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	if !DatesOverlap(day(1), day(3), day(3), day(4)) {
+		t.Error("Expected ranges sharing an end day to overlap")
+	}
+	if DatesOverlap(day(1), day(3), day(4), day(5)) {
+		t.Error("Expected consecutive ranges not to overlap")
+	}
+}
+
+func TestScheduledTravelStatuses(t *testing.T) {
+	scheduled := map[string]bool{}
+	for _, status := range scheduledTravelStatuses {
+		scheduled[status] = true
+	}
+	for _, status := range []string{models.TravelStatusSubmitted, models.TravelStatusApproved, models.TravelStatusInProgress} {
+		if !scheduled[status] {
+			t.Errorf("Expected %s trips to block their employees", status)
+		}
+	}
+	for _, status := range []string{models.TravelStatusDraft, models.TravelStatusRejected, models.TravelStatusCancelled, models.TravelStatusCompleted} {
+		if scheduled[status] {
+			t.Errorf("Expected %s trips not to block their employees", status)
+		}
+	}
+}
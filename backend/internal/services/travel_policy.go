@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+)
+
+var (
+	ErrPolicyViolation             = errors.New("travel policy violated")
+	ErrPolicyJustificationRequired = errors.New("travel policy requires a justification")
+)
+
+// receiptTransportModes maps at-cost receipt types to the transport mode they prove
+var receiptTransportModes = map[string]string{
+	"flight": "pesawat",
+	"train":  "kereta api",
+}
+
+// PolicyTraveller is one traveller a policy is evaluated for
+type PolicyTraveller struct {
+	EmployeeID    uint   `json:"employee_id"`
+	Name          string `json:"name"`
+	PositionLevel string `json:"position_level"`
+}
+
+// PolicySubject is what a policy rule is evaluated against
+type PolicySubject struct {
+	Travellers       []PolicyTraveller
+	DestinationTypes []string // Zona tujuan, semua leg untuk itinerary multi-leg
+	DurationDays     int
+	TransportModes   []string
+	NonWorkingDays   int // Hari akhir pekan dan libur nasional selama perjalanan
+}
+
+// PolicyFinding is one rule that matched a subject. EmployeeID is set when the rule is
+// limited to a position level and names the traveller it matched.
+type PolicyFinding struct {
+	RuleID        uint   `json:"rule_id"`
+	RuleName      string `json:"rule_name"`
+	Effect        string `json:"effect"`
+	Message       string `json:"message"`
+	EmployeeID    uint   `json:"employee_id,omitempty"`
+	EmployeeName  string `json:"employee_name,omitempty"`
+	TransportMode string `json:"transport_mode,omitempty"`
+}
+
+// PolicyEvaluation is the outcome of evaluating the policy rules against a subject
+type PolicyEvaluation struct {
+	Violations             []PolicyFinding `json:"violations"`
+	RequiredJustifications []PolicyFinding `json:"required_justifications"`
+}
+
+// Check returns ErrPolicyViolation when any deny rule matched, or ErrPolicyJustificationRequired
+// when a justify rule matched and justification is empty
+func (e *PolicyEvaluation) Check(justification string) error {
+	if len(e.Violations) > 0 {
+		return &PolicyError{Err: ErrPolicyViolation, Evaluation: e}
+	}
+	if len(e.RequiredJustifications) > 0 && strings.TrimSpace(justification) == "" {
+		return &PolicyError{Err: ErrPolicyJustificationRequired, Evaluation: e}
+	}
+	return nil
+}
+
+// PolicyError carries the evaluation that refused a document
+type PolicyError struct {
+	Err        error
+	Evaluation *PolicyEvaluation
+}
+
+func (e *PolicyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// EvaluatePolicy matches rules against subject. A rule limited to a position level yields one
+// finding per traveller of that level; other rules yield at most one finding for the document.
+func EvaluatePolicy(rules []models.TravelPolicyRule, subject PolicySubject) *PolicyEvaluation {
+	evaluation := &PolicyEvaluation{
+		Violations:             []PolicyFinding{},
+		RequiredJustifications: []PolicyFinding{},
+	}
+
+	for _, rule := range rules {
+		if !policyDestinationMatches(rule, subject) || !policyDurationMatches(rule, subject.DurationDays) {
+			continue
+		}
+		if rule.NonWorkingDays && subject.NonWorkingDays == 0 {
+			continue
+		}
+		mode, ok := policyTransportMatch(rule, subject.TransportModes)
+		if !ok {
+			continue
+		}
+
+		finding := PolicyFinding{
+			RuleID:        rule.ID,
+			RuleName:      rule.Name,
+			Effect:        rule.Effect,
+			Message:       rule.Message,
+			TransportMode: mode,
+		}
+		var findings []PolicyFinding
+		if rule.PositionLevel == "" {
+			findings = append(findings, finding)
+		} else {
+			for _, traveller := range subject.Travellers {
+				if strings.EqualFold(traveller.PositionLevel, rule.PositionLevel) {
+					finding.EmployeeID = traveller.EmployeeID
+					finding.EmployeeName = traveller.Name
+					findings = append(findings, finding)
+				}
+			}
+		}
+
+		if rule.Effect == models.PolicyEffectDeny {
+			evaluation.Violations = append(evaluation.Violations, findings...)
+		} else {
+			evaluation.RequiredJustifications = append(evaluation.RequiredJustifications, findings...)
+		}
+	}
+	return evaluation
+}
+
+func policyDestinationMatches(rule models.TravelPolicyRule, subject PolicySubject) bool {
+	if rule.DestinationType == "" {
+		return true
+	}
+	for _, destinationType := range subject.DestinationTypes {
+		if destinationType == rule.DestinationType {
+			return true
+		}
+	}
+	return false
+}
+
+func policyDurationMatches(rule models.TravelPolicyRule, durationDays int) bool {
+	if rule.MinDurationDays > 0 && durationDays < rule.MinDurationDays {
+		return false
+	}
+	if rule.MaxDurationDays > 0 && durationDays > rule.MaxDurationDays {
+		return false
+	}
+	return true
+}
+
+// policyTransportMatch returns the transport mode that satisfies the rule. Modes are free text,
+// so "Pesawat Garuda" matches a rule on "pesawat".
+func policyTransportMatch(rule models.TravelPolicyRule, modes []string) (string, bool) {
+	if rule.TransportMode == "" {
+		return "", true
+	}
+	want := strings.ToLower(strings.TrimSpace(rule.TransportMode))
+	for _, mode := range modes {
+		if strings.Contains(strings.ToLower(mode), want) {
+			return mode, true
+		}
+	}
+	return "", false
+}
+
+// splitTransportation splits a transportation field such as "pesawat, kereta api" into modes
+func splitTransportation(transportation string) []string {
+	var modes []string
+	for _, mode := range strings.Split(transportation, ",") {
+		if mode = strings.TrimSpace(mode); mode != "" {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
+// TravelRequestPolicySubject builds the policy subject of a travel request, its employees and the
+// participants paid an allowance
+func TravelRequestPolicySubject(request *models.TravelRequest, employees []models.Employee) PolicySubject {
+	subject := PolicySubject{
+		DurationDays:   request.DurationDays,
+		TransportModes: splitTransportation(request.Transportation),
+		NonWorkingDays: request.WeekendDays + request.HolidayDays,
+	}
+	for _, employee := range employees {
+		subject.Travellers = append(subject.Travellers, PolicyTraveller{
+			EmployeeID:    employee.ID,
+			Name:          employee.Name,
+			PositionLevel: employee.Position.Level,
+		})
+	}
+	// Paid participants travel at the level of the position they are ranked with
+	for _, participant := range request.Participants {
+		if participant.ReceivesAllowance && participant.RatePosition != nil {
+			subject.Travellers = append(subject.Travellers, PolicyTraveller{
+				Name:          participant.Name,
+				PositionLevel: participant.RatePosition.Level,
+			})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, leg := range request.Legs {
+		if !seen[leg.DestinationType] {
+			seen[leg.DestinationType] = true
+			subject.DestinationTypes = append(subject.DestinationTypes, leg.DestinationType)
+		}
+	}
+	if len(subject.DestinationTypes) == 0 {
+		subject.DestinationTypes = []string{request.DestinationType}
+	}
+	return subject
+}
+
+// AtCostClaimPolicySubject builds the policy subject of an at-cost claim: the claimed employees
+// on the trip of the claim, travelling with the modes of the trip and of the receipts
+func AtCostClaimPolicySubject(request *models.TravelRequest, req *CreateAtCostClaimRequest) PolicySubject {
+	claimed := make(map[uint]bool)
+	modes := make(map[string]bool)
+	var receiptModes []string
+	for _, item := range req.ClaimItems {
+		claimed[item.EmployeeID] = true
+		for _, receipt := range item.Receipts {
+			if mode, ok := receiptTransportModes[receipt.Type]; ok && !modes[mode] {
+				modes[mode] = true
+				receiptModes = append(receiptModes, mode)
+			}
+		}
+	}
+
+	var employees []models.Employee
+	for _, empRel := range request.TravelRequestEmployees {
+		if claimed[empRel.EmployeeID] {
+			employees = append(employees, empRel.Employee)
+		}
+	}
+
+	subject := TravelRequestPolicySubject(request, employees)
+	subject.TransportModes = append(subject.TransportModes, receiptModes...)
+	return subject
+}
+
+// TravelPolicyService evaluates travel requests and at-cost claims against the policy rules
+type TravelPolicyService struct {
+	repo *repository.Repository
+}
+
+func NewTravelPolicyService(repo *repository.Repository) *TravelPolicyService {
+	return &TravelPolicyService{repo: repo}
+}
+
+// Evaluate matches the active rules of a document type against subject
+func (s *TravelPolicyService) Evaluate(documentType string, subject PolicySubject) (*PolicyEvaluation, error) {
+	rules, err := s.repo.GetActiveTravelPolicyRules(documentType)
+	if err != nil {
+		return nil, err
+	}
+	return EvaluatePolicy(rules, subject), nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	rules := []models.TravelPolicyRule{
+		{ID: 1, Name: "Jr. Officer tidak naik pesawat dalam provinsi", PositionLevel: "Jr. Officer",
+			DestinationType: "in_province", TransportMode: "pesawat", Effect: models.PolicyEffectDeny},
+		{ID: 2, Name: "Perjalanan lebih dari 5 hari", MinDurationDays: 6, Effect: models.PolicyEffectJustify},
+		{ID: 3, Name: "Luar negeri", DestinationType: "abroad", Effect: models.PolicyEffectDeny},
+	}
+	subject := PolicySubject{
+		Travellers: []PolicyTraveller{
+			{EmployeeID: 1, Name: "Budi", PositionLevel: "Jr. Officer"},
+			{EmployeeID: 2, Name: "Sari", PositionLevel: "AVP"},
+		},
+		DestinationTypes: []string{"in_province"},
+		DurationDays:     7,
+		TransportModes:   []string{"Pesawat Garuda"},
+	}
+
+	evaluation := EvaluatePolicy(rules, subject)
+	if len(evaluation.Violations) != 1 || evaluation.Violations[0].EmployeeID != 1 {
+		t.Fatalf("Expected one violation for the Jr. Officer, got %+v", evaluation.Violations)
+	}
+	if evaluation.Violations[0].TransportMode != "Pesawat Garuda" {
+		t.Errorf("Expected the matched mode to be reported, got %q", evaluation.Violations[0].TransportMode)
+	}
+	if len(evaluation.RequiredJustifications) != 1 || evaluation.RequiredJustifications[0].RuleID != 2 {
+		t.Errorf("Expected the duration rule to require a justification, got %+v", evaluation.RequiredJustifications)
+	}
+	if err := evaluation.Check("urgent"); !errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("Expected ErrPolicyViolation, got %v", err)
+	}
+
+	subject.Travellers = subject.Travellers[1:]
+	evaluation = EvaluatePolicy(rules, subject)
+	if err := evaluation.Check(""); !errors.Is(err, ErrPolicyJustificationRequired) {
+		t.Errorf("Expected ErrPolicyJustificationRequired, got %v", err)
+	}
+	if err := evaluation.Check("Audit cabang butuh 7 hari"); err != nil {
+		t.Errorf("Expected justification to satisfy the policy, got %v", err)
+	}
+}
+
+func TestAtCostClaimPolicySubject(t *testing.T) {
+	request := &models.TravelRequest{
+		DestinationType: "outside_province",
+		DurationDays:    3,
+		Transportation:  "angkutan umum",
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, Employee: models.Employee{ID: 1, Position: models.Position{Level: "Officer"}}},
+			{EmployeeID: 2, Employee: models.Employee{ID: 2, Position: models.Position{Level: "AVP"}}},
+		},
+	}
+	req := &CreateAtCostClaimRequest{ClaimItems: []CreateAtCostClaimItemReq{
+		{EmployeeID: 2, Receipts: []CreateAtCostReceiptReq{{Type: "flight"}, {Type: "hotel"}}},
+	}}
+
+	subject := AtCostClaimPolicySubject(request, req)
+	if len(subject.Travellers) != 1 || subject.Travellers[0].PositionLevel != "AVP" {
+		t.Errorf("Expected only the claimed AVP, got %+v", subject.Travellers)
+	}
+	if len(subject.TransportModes) != 2 || subject.TransportModes[1] != "pesawat" {
+		t.Errorf("Expected the flight receipt to add pesawat, got %v", subject.TransportModes)
+	}
+}
+
+func TestTravelRequestPolicySubjectParticipants(t *testing.T) {
+	request := &models.TravelRequest{
+		DestinationType: "abroad",
+		DurationDays:    4,
+		Transportation:  "pesawat",
+		Participants: []models.TravelParticipant{
+			{Name: "Konsultan", ReceivesAllowance: true, RatePosition: &models.Position{Level: "VP"}},
+			{Name: "Narasumber"},
+		},
+	}
+	employees := []models.Employee{{ID: 1, Name: "Budi", Position: models.Position{Level: "Officer"}}}
+
+	subject := TravelRequestPolicySubject(request, employees)
+	if len(subject.Travellers) != 2 || subject.Travellers[1].Name != "Konsultan" || subject.Travellers[1].PositionLevel != "VP" {
+		t.Fatalf("Expected the employee and the paid participant at VP level, got %+v", subject.Travellers)
+	}
+
+	rules := []models.TravelPolicyRule{{ID: 1, Name: "VP abroad", PositionLevel: "VP", DestinationType: "abroad", Effect: models.PolicyEffectJustify}}
+	evaluation := EvaluatePolicy(rules, subject)
+	if len(evaluation.RequiredJustifications) != 1 || evaluation.RequiredJustifications[0].EmployeeName != "Konsultan" {
+		t.Errorf("Expected the paid participant to need a justification, got %+v", evaluation.RequiredJustifications)
+	}
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrQuotaExceeded stops a request that exceeds a quota rule in block mode
+var ErrQuotaExceeded = errors.New("travel quota exceeded")
+
+// Quota rule kinds
+const (
+	QuotaTripsPerYear    = "trips_per_year"
+	QuotaConsecutiveDays = "consecutive_days"
+)
+
+// QuotaViolation is one quota rule an employee would exceed with a trip
+type QuotaViolation struct {
+	EmployeeID    uint   `json:"employee_id"`
+	NIP           string `json:"nip"`
+	EmployeeName  string `json:"employee_name"`
+	PositionLevel string `json:"position_level"`
+	Rule          string `json:"rule"`   // trips_per_year, consecutive_days
+	Period        string `json:"period"` // Tahun (2025) atau bulan (2025-03)
+	Limit         int    `json:"limit"`
+	Actual        int    `json:"actual"`
+	Blocking      bool   `json:"blocking"` // true jika aturan block, false jika hanya peringatan
+}
+
+// QuotaError carries the violations that stopped a request
+type QuotaError struct {
+	Err        error
+	Violations []QuotaViolation
+}
+
+func (e *QuotaError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QuotaError) Unwrap() error {
+	return e.Err
+}
+
+// TravelStreak is a run of consecutive travel days within one month
+type TravelStreak struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Days  int       `json:"days"`
+}
+
+// MonthlyTravelStreak is the longest run of consecutive travel days of an employee in a month
+type MonthlyTravelStreak struct {
+	Month         string `json:"month"` // 2025-03
+	LongestStreak int    `json:"longest_streak"`
+}
+
+// EmployeeQuota is the quota usage of an employee in a year
+type EmployeeQuota struct {
+	EmployeeID     uint                    `json:"employee_id"`
+	NIP            string                  `json:"nip"`
+	EmployeeName   string                  `json:"employee_name"`
+	PositionLevel  string                  `json:"position_level"`
+	Year           int                     `json:"year"`
+	Rule           *models.TravelQuotaRule `json:"rule"` // nil jika level tidak memiliki aturan kuota
+	TripsUsed      int                     `json:"trips_used"`
+	TripsRemaining *int                    `json:"trips_remaining"` // nil = tidak dibatasi
+	Months         []MonthlyTravelStreak   `json:"months"`
+}
+
+// TravelStreaks returns the runs of consecutive travel days of trips inside the month of month.
+// Trips that touch or overlap are merged, days outside the month are ignored.
+func TravelStreaks(trips []TripAssignment, month time.Time) []TravelStreak {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	travelling := make(map[string]bool)
+	for _, trip := range trips {
+		start := dateOnly(trip.DepartureDate)
+		end := dateOnly(trip.ReturnDate)
+		if start.Before(monthStart) {
+			start = monthStart
+		}
+		if end.After(monthEnd) {
+			end = monthEnd
+		}
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			travelling[day.Format("2006-01-02")] = true
+		}
+	}
+
+	var streaks []TravelStreak
+	var current *TravelStreak
+	for day := monthStart; !day.After(monthEnd); day = day.AddDate(0, 0, 1) {
+		if !travelling[day.Format("2006-01-02")] {
+			current = nil
+			continue
+		}
+		if current == nil {
+			streaks = append(streaks, TravelStreak{Start: day})
+			current = &streaks[len(streaks)-1]
+		}
+		current.End = day
+		current.Days++
+	}
+	return streaks
+}
+
+// LongestTravelStreak returns the longest run of consecutive travel days inside the month of month
+func LongestTravelStreak(trips []TripAssignment, month time.Time) int {
+	longest := 0
+	for _, streak := range TravelStreaks(trips, month) {
+		if streak.Days > longest {
+			longest = streak.Days
+		}
+	}
+	return longest
+}
+
+// EvaluateQuota checks a new trip of an employee against the quota rule of their level.
+// existing are the employee's other active trips. Trips count in the year they depart; the
+// consecutive day limit applies to the runs the new trip is part of, in every month it touches.
+func EvaluateQuota(rule models.TravelQuotaRule, employee models.Employee, existing []TripAssignment, trip TripAssignment) []QuotaViolation {
+	newViolation := func(kind, period string, limit, actual int) QuotaViolation {
+		return QuotaViolation{
+			EmployeeID:    employee.ID,
+			NIP:           employee.NIP,
+			EmployeeName:  employee.Name,
+			PositionLevel: employee.Position.Level,
+			Rule:          kind,
+			Period:        period,
+			Limit:         limit,
+			Actual:        actual,
+			Blocking:      rule.Enforcement == models.QuotaEnforcementBlock,
+		}
+	}
+
+	var violations []QuotaViolation
+	if rule.MaxTripsPerYear > 0 {
+		year := trip.DepartureDate.Year()
+		trips := countTripsInYear(existing, year) + 1
+		if trips > rule.MaxTripsPerYear {
+			violations = append(violations, newViolation(QuotaTripsPerYear, strconv.Itoa(year), rule.MaxTripsPerYear, trips))
+		}
+	}
+
+	if rule.MaxConsecutiveDays > 0 {
+		all := append(append([]TripAssignment{}, existing...), trip)
+		start, end := dateOnly(trip.DepartureDate), dateOnly(trip.ReturnDate)
+		month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for !month.After(end) {
+			longest := 0
+			for _, streak := range TravelStreaks(all, month) {
+				if DatesOverlap(streak.Start, streak.End, start, end) && streak.Days > longest {
+					longest = streak.Days
+				}
+			}
+			if longest > rule.MaxConsecutiveDays {
+				violations = append(violations, newViolation(QuotaConsecutiveDays, month.Format("2006-01"), rule.MaxConsecutiveDays, longest))
+			}
+			month = month.AddDate(0, 1, 0)
+		}
+	}
+	return violations
+}
+
+// HasBlockingViolation reports whether any violation comes from a rule that blocks
+func HasBlockingViolation(violations []QuotaViolation) bool {
+	for _, violation := range violations {
+		if violation.Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// countTripsInYear counts the distinct trips departing in year
+func countTripsInYear(trips []TripAssignment, year int) int {
+	seen := make(map[uint]bool)
+	for _, trip := range trips {
+		if trip.DepartureDate.Year() == year {
+			seen[trip.TravelRequestID] = true
+		}
+	}
+	return len(seen)
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// TravelQuotaService applies the quota rules of position levels to travel requests
+type TravelQuotaService struct {
+	repo *repository.Repository
+}
+
+func NewTravelQuotaService(repo *repository.Repository) *TravelQuotaService {
+	return &TravelQuotaService{repo: repo}
+}
+
+// CheckRequest returns the quota violations request would cause for each of its employees.
+// excludeRequestID leaves the request itself out when it is being edited.
+func (s *TravelQuotaService) CheckRequest(request *models.TravelRequest, employees []models.Employee, excludeRequestID uint) ([]QuotaViolation, error) {
+	return s.checkRequest(s.repo.GetDB(), request, employees, excludeRequestID)
+}
+
+// CheckRequestTx checks the quota of a request being saved in tx. The employees are locked
+// first, as in CheckConflictsTx, so two requests cannot both take an employee's last trip.
+// A rule in block mode stops the request with a QuotaError; warnings are returned.
+func (s *TravelQuotaService) CheckRequestTx(tx *gorm.DB, request *models.TravelRequest, employees []models.Employee, excludeRequestID uint) ([]QuotaViolation, error) {
+	if err := lockEmployeesTx(tx, employees); err != nil {
+		return nil, err
+	}
+	violations, err := s.checkRequest(tx, request, employees, excludeRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if HasBlockingViolation(violations) {
+		return nil, &QuotaError{Err: ErrQuotaExceeded, Violations: violations}
+	}
+	return violations, nil
+}
+
+func (s *TravelQuotaService) checkRequest(db *gorm.DB, request *models.TravelRequest, employees []models.Employee, excludeRequestID uint) ([]QuotaViolation, error) {
+	rules, err := s.repo.GetActiveTravelQuotaRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var employeeIDs []uint
+	for _, employee := range employees {
+		if _, ok := rules[employee.Position.Level]; ok {
+			employeeIDs = append(employeeIDs, employee.ID)
+		}
+	}
+	if len(employeeIDs) == 0 {
+		return nil, nil
+	}
+
+	// Whole departure year for the trip count, up to the return month for the day streaks
+	from := time.Date(request.DepartureDate.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(request.DepartureDate.Year(), 12, 31, 0, 0, 0, 0, time.UTC)
+	if monthEnd := time.Date(request.ReturnDate.Year(), request.ReturnDate.Month()+1, 0, 0, 0, 0, 0, time.UTC); monthEnd.After(to) {
+		to = monthEnd
+	}
+	existing, err := tripAssignments(db, countedTravelStatuses, employeeIDs, from, to, excludeRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []QuotaViolation
+	for _, employee := range employees {
+		rule, ok := rules[employee.Position.Level]
+		if !ok {
+			continue
+		}
+		var trips []TripAssignment
+		for _, assignment := range existing {
+			if assignment.EmployeeID == employee.ID {
+				trips = append(trips, assignment)
+			}
+		}
+		trip := TripAssignment{
+			EmployeeID:      employee.ID,
+			TravelRequestID: excludeRequestID,
+			DepartureDate:   request.DepartureDate,
+			ReturnDate:      request.ReturnDate,
+		}
+		violations = append(violations, EvaluateQuota(rule, employee, trips, trip)...)
+	}
+	return violations, nil
+}
+
+// EmployeeQuota returns the trips an employee has used and has left in a year, and the
+// longest run of travel days in every month they travelled
+func (s *TravelQuotaService) EmployeeQuota(employee *models.Employee, year int) (*EmployeeQuota, error) {
+	rules, err := s.repo.GetActiveTravelQuotaRules()
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+	trips, err := tripAssignments(s.repo.GetDB(), countedTravelStatuses, []uint{employee.ID}, from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &EmployeeQuota{
+		EmployeeID:    employee.ID,
+		NIP:           employee.NIP,
+		EmployeeName:  employee.Name,
+		PositionLevel: employee.Position.Level,
+		Year:          year,
+		TripsUsed:     countTripsInYear(trips, year),
+		Months:        []MonthlyTravelStreak{},
+	}
+	if rule, ok := rules[employee.Position.Level]; ok {
+		quota.Rule = &rule
+		if rule.MaxTripsPerYear > 0 {
+			remaining := rule.MaxTripsPerYear - quota.TripsUsed
+			if remaining < 0 {
+				remaining = 0
+			}
+			quota.TripsRemaining = &remaining
+		}
+	}
+
+	for month := from; month.Year() == year; month = month.AddDate(0, 1, 0) {
+		if longest := LongestTravelStreak(trips, month); longest > 0 {
+			quota.Months = append(quota.Months, MonthlyTravelStreak{
+				Month:         month.Format("2006-01"),
+				LongestStreak: longest,
+			})
+		}
+	}
+	return quota, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestTravelStreaks(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	trips := []TripAssignment{
+		{TravelRequestID: 1, DepartureDate: day(2, 26), ReturnDate: day(3, 3)},
+		{TravelRequestID: 2, DepartureDate: day(3, 4), ReturnDate: day(3, 6)},
+		{TravelRequestID: 3, DepartureDate: day(3, 10), ReturnDate: day(3, 11)},
+	}
+
+	streaks := TravelStreaks(trips, day(3, 1))
+	if len(streaks) != 2 {
+		t.Fatalf("Expected 2 streaks in March, got %d", len(streaks))
+	}
+	if streaks[0].Days != 6 || !streaks[0].Start.Equal(day(3, 1)) {
+		t.Errorf("Expected adjacent trips to merge into 6 days from March 1, got %d from %s", streaks[0].Days, streaks[0].Start)
+	}
+	if got := LongestTravelStreak(trips, day(2, 1)); got != 3 {
+		t.Errorf("Expected 3 travel days at the end of February, got %d", got)
+	}
+}
+
+func TestEvaluateQuota(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	employee := models.Employee{ID: 1, Name: "Budi", Position: models.Position{Level: "Officer"}}
+	existing := []TripAssignment{
+		{EmployeeID: 1, TravelRequestID: 1, DepartureDate: day(1), ReturnDate: day(4)},
+		{EmployeeID: 1, TravelRequestID: 2, DepartureDate: day(20), ReturnDate: day(21)},
+	}
+	rule := models.TravelQuotaRule{MaxTripsPerYear: 2, MaxConsecutiveDays: 6, Enforcement: models.QuotaEnforcementBlock}
+
+	// Continues the first trip for 3 more days: 7 days in a row and a third trip
+	violations := EvaluateQuota(rule, employee, existing, TripAssignment{DepartureDate: day(5), ReturnDate: day(7)})
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %d", len(violations))
+	}
+	if violations[0].Rule != QuotaTripsPerYear || violations[0].Actual != 3 || violations[0].Period != "2025" {
+		t.Errorf("Unexpected trip violation %+v", violations[0])
+	}
+	if violations[1].Rule != QuotaConsecutiveDays || violations[1].Actual != 7 || violations[1].Period != "2025-03" {
+		t.Errorf("Unexpected consecutive days violation %+v", violations[1])
+	}
+	if !HasBlockingViolation(violations) {
+		t.Error("Expected block rule to produce blocking violations")
+	}
+
+	// A separate short trip only counts against the yearly limit
+	rule.Enforcement = models.QuotaEnforcementWarn
+	violations = EvaluateQuota(rule, employee, existing, TripAssignment{DepartureDate: day(10), ReturnDate: day(11)})
+	if len(violations) != 1 || violations[0].Rule != QuotaTripsPerYear || HasBlockingViolation(violations) {
+		t.Errorf("Expected a single warning on the yearly limit, got %+v", violations)
+	}
+}
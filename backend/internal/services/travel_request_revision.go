@@ -0,0 +1,256 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+	"perjalanan-dinas/backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTravelRequestLocked  = errors.New("travel request can no longer be edited")
+	ErrRequesterUnitChanged = errors.New("the first employee must stay in the unit the request is numbered for")
+)
+
+// IsEditableTravelStatus reports whether a request in this status may still be edited.
+// Once approved the request is the basis of the Surat Tugas and can only be amended.
+func IsEditableTravelStatus(status string) bool {
+	return status == models.TravelStatusDraft || status == models.TravelStatusSubmitted
+}
+
+// CheckRequesterUnit refuses an edit whose first employee belongs to another unit than the one
+// in the request number. The number is taken from the agenda of that unit and numbers are never
+// given back, so such a request has to be cancelled and requested again.
+func CheckRequesterUnit(requestNumber string, updated *models.TravelRequest) error {
+	code := utils.ExtractPositionCodeFromRequestNumber(requestNumber)
+	if code == "" || len(updated.TravelRequestEmployees) == 0 {
+		return nil
+	}
+	if newCode := updated.TravelRequestEmployees[0].Employee.Position.Code; newCode != code {
+		return fmt.Errorf("%w: numbered for %s, first employee is in %s", ErrRequesterUnitChanged, code, newCode)
+	}
+	return nil
+}
+
+// TravelRequestChange is one field that differs between two versions of a travel request
+type TravelRequestChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// DiffTravelRequest lists the editable fields that differ between the current and the
+// updated request, employees included. Values are rendered as they appear on the Nota.
+func DiffTravelRequest(current, updated *models.TravelRequest) []TravelRequestChange {
+	fields := []struct {
+		name             string
+		oldValue, newVal string
+	}{
+		{"purpose", current.Purpose, updated.Purpose},
+		{"departure_place", current.DeparturePlace, updated.DeparturePlace},
+		{"destination", current.Destination, updated.Destination},
+		{"destination_type", current.DestinationType, updated.DestinationType},
+		{"destination_tier", current.DestinationTier, updated.DestinationTier},
+		{"departure_date", current.DepartureDate.Format("2006-01-02"), updated.DepartureDate.Format("2006-01-02")},
+		{"return_date", current.ReturnDate.Format("2006-01-02"), updated.ReturnDate.Format("2006-01-02")},
+		{"departure_time", current.DepartureTime, updated.DepartureTime},
+		{"return_time", current.ReturnTime, updated.ReturnTime},
+		{"duration_days", strconv.Itoa(current.DurationDays), strconv.Itoa(updated.DurationDays)},
+		{"transportation", current.Transportation, updated.Transportation},
+		{"total_allowance", strconv.Itoa(current.TotalAllowance), strconv.Itoa(updated.TotalAllowance)},
+		{"employees", describeTravelEmployees(current), describeTravelEmployees(updated)},
+		{"itinerary", describeItinerary(current), describeItinerary(updated)},
+		{"participants", describeParticipants(current), describeParticipants(updated)},
+	}
+
+	var changes []TravelRequestChange
+	for _, f := range fields {
+		if f.oldValue != f.newVal {
+			changes = append(changes, TravelRequestChange{Field: f.name, OldValue: f.oldValue, NewValue: f.newVal})
+		}
+	}
+	return changes
+}
+
+// describeTravelEmployees renders the employees and their allowance, e.g. "198501 Budi (3 x 250000)"
+func describeTravelEmployees(request *models.TravelRequest) string {
+	parts := make([]string, 0, len(request.TravelRequestEmployees))
+	for _, empRel := range request.TravelRequestEmployees {
+		parts = append(parts, fmt.Sprintf("%s %s (%d x %d)",
+			empRel.Employee.NIP, empRel.Employee.Name, empRel.DurationDays, empRel.DailyRate))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// TravelRequestEditor applies edits to travel requests that are not yet approved.
+// Every edit bumps the revision and records each changed field.
+type TravelRequestEditor struct {
+	repo      *repository.Repository
+	workflow  *TravelRequestWorkflow
+	conflicts *TravelConflictService
+	quota     *TravelQuotaService
+}
+
+func NewTravelRequestEditor(repo *repository.Repository) *TravelRequestEditor {
+	return &TravelRequestEditor{
+		repo:      repo,
+		workflow:  NewTravelRequestWorkflow(repo),
+		conflicts: NewTravelConflictService(repo),
+		quota:     NewTravelQuotaService(repo),
+	}
+}
+
+// Edit replaces the editable fields and employees of a request with those of updated.
+// The number is kept, so the first employee may not move the request to another unit.
+// A submitted request goes through its approval chain again.
+// Schedule conflicts and the travel quota are checked in the same transaction, see
+// CheckConflictsTx and CheckRequestTx.
+// Returns the changes made and the quota warnings; an edit that changes nothing does not
+// create a revision.
+func (e *TravelRequestEditor) Edit(id uint, updated *models.TravelRequest, override ConflictOverride, actor, reason string) ([]models.TravelRequestRevision, []QuotaViolation, error) {
+	var revisions []models.TravelRequestRevision
+	var warnings []QuotaViolation
+	err := e.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			return err
+		}
+		if !IsEditableTravelStatus(request.Status) {
+			return fmt.Errorf("%w: status is %s", ErrTravelRequestLocked, request.Status)
+		}
+		if err := tx.Preload("Employee").Where("travel_request_id = ?", id).Order("id ASC").
+			Find(&request.TravelRequestEmployees).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("travel_request_id = ?", id).Order("sequence ASC").Find(&request.Legs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("travel_request_id = ?", id).Order("id ASC").Find(&request.Participants).Error; err != nil {
+			return err
+		}
+
+		changes := DiffTravelRequest(&request, updated)
+		if len(changes) == 0 {
+			return nil
+		}
+		if err := CheckRequesterUnit(request.RequestNumber, updated); err != nil {
+			return err
+		}
+
+		employees := make([]models.Employee, 0, len(updated.TravelRequestEmployees))
+		for _, empRel := range updated.TravelRequestEmployees {
+			employees = append(employees, empRel.Employee)
+		}
+		if err := e.conflicts.CheckConflictsTx(tx, updated, employees, id, override); err != nil {
+			return err
+		}
+		quotaWarnings, err := e.quota.CheckRequestTx(tx, updated, employees, id)
+		if err != nil {
+			return err
+		}
+		warnings = quotaWarnings
+
+		revision := request.Revision + 1
+		err = tx.Model(&request).Updates(map[string]interface{}{
+			"purpose":             updated.Purpose,
+			"departure_place":     updated.DeparturePlace,
+			"destination":         updated.Destination,
+			"destination_type":    updated.DestinationType,
+			"destination_tier":    updated.DestinationTier,
+			"tier_multiplier":     updated.TierMultiplier,
+			"departure_date":      updated.DepartureDate,
+			"return_date":         updated.ReturnDate,
+			"duration_days":       updated.DurationDays,
+			"departure_time":      updated.DepartureTime,
+			"return_time":         updated.ReturnTime,
+			"departure_time_zone": updated.DepartureTimeZone,
+			"return_time_zone":    updated.ReturnTimeZone,
+			"transportation":      updated.Transportation,
+			"total_allowance":     updated.TotalAllowance,
+			"revision":            revision,
+
+			"conflict_override_by":     updated.ConflictOverrideBy,
+			"conflict_override_reason": updated.ConflictOverrideReason,
+			"policy_justification":     updated.PolicyJustification,
+
+			"partial_days":          updated.PartialDays,
+			"departure_day_percent": updated.DepartureDayPercent,
+			"return_day_percent":    updated.ReturnDayPercent,
+			"paid_day_percent":      updated.PaidDayPercent,
+			"weekend_days":          updated.WeekendDays,
+			"holiday_days":          updated.HolidayDays,
+
+			"allowance_currency":      updated.AllowanceCurrency,
+			"allowance_exchange_rate": updated.AllowanceExchangeRate,
+			"allowance_rate_date":     updated.AllowanceRateDate,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update travel request: %w", err)
+		}
+
+		// Replace employees with their recalculated allowance
+		if err := tx.Where("travel_request_id = ?", id).Delete(&models.TravelRequestEmployee{}).Error; err != nil {
+			return fmt.Errorf("failed to remove employees: %w", err)
+		}
+		for _, empRel := range updated.TravelRequestEmployees {
+			row := &models.TravelRequestEmployee{
+				TravelRequestID: id,
+				EmployeeID:      empRel.EmployeeID,
+				DailyRate:       empRel.DailyRate,
+				DurationDays:    empRel.DurationDays,
+				Subtotal:        empRel.Subtotal,
+			}
+			if err := tx.Create(row).Error; err != nil {
+				return fmt.Errorf("failed to link employee: %w", err)
+			}
+		}
+
+		if err := ReplaceParticipantsTx(tx, id, updated.Participants); err != nil {
+			return err
+		}
+
+		// Legs are replaced together with the visit proofs generated from them
+		if len(request.Legs) > 0 || len(updated.Legs) > 0 {
+			if err := ReplaceItineraryTx(tx, id, updated.Legs); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, change := range changes {
+			revisions = append(revisions, models.TravelRequestRevision{
+				TravelRequestID: id,
+				Revision:        revision,
+				Field:           change.Field,
+				OldValue:        change.OldValue,
+				NewValue:        change.NewValue,
+				ChangedBy:       actor,
+				Reason:          reason,
+				ChangedAt:       now,
+			})
+		}
+		if err := tx.Create(&revisions).Error; err != nil {
+			return fmt.Errorf("failed to record revision: %w", err)
+		}
+
+		// Approvers must see the edited request again
+		if request.Status == models.TravelStatusSubmitted {
+			request.TotalAllowance = updated.TotalAllowance
+			return e.workflow.StartApprovalTx(tx, &request)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return revisions, warnings, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestDiffTravelRequest(t *testing.T) {
+	budi := models.Employee{ID: 1, NIP: "198501", Name: "Budi"}
+	current := &models.TravelRequest{
+		Purpose:         "Rapat koordinasi",
+		Destination:     "Malang",
+		DestinationType: "in_province",
+		DepartureDate:   time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		ReturnDate:      time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC),
+		DurationDays:    2,
+		Transportation:  "kereta api",
+		TotalAllowance:  500000,
+		TravelRequestEmployees: []models.TravelRequestEmployee{
+			{EmployeeID: 1, Employee: budi, DailyRate: 250000, DurationDays: 2, Subtotal: 500000},
+		},
+	}
+
+	same := *current
+	if changes := DiffTravelRequest(current, &same); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+
+	updated := *current
+	updated.ReturnDate = time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	updated.DurationDays = 3
+	updated.TotalAllowance = 750000
+	updated.TravelRequestEmployees = []models.TravelRequestEmployee{
+		{EmployeeID: 1, Employee: budi, DailyRate: 250000, DurationDays: 3, Subtotal: 750000},
+	}
+
+	changes := DiffTravelRequest(current, &updated)
+	want := map[string][2]string{
+		"return_date":     {"2025-03-11", "2025-03-12"},
+		"duration_days":   {"2", "3"},
+		"total_allowance": {"500000", "750000"},
+		"employees":       {"198501 Budi (2 x 250000)", "198501 Budi (3 x 250000)"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for _, change := range changes {
+		values, ok := want[change.Field]
+		if !ok {
+			t.Errorf("Unexpected change of %s", change.Field)
+			continue
+		}
+		if change.OldValue != values[0] || change.NewValue != values[1] {
+			t.Errorf("%s: expected %q -> %q, got %q -> %q", change.Field, values[0], values[1], change.OldValue, change.NewValue)
+		}
+	}
+}
+
+func TestIsEditableTravelStatus(t *testing.T) {
+	for _, status := range []string{models.TravelStatusDraft, models.TravelStatusSubmitted} {
+		if !IsEditableTravelStatus(status) {
+			t.Errorf("Expected %s to be editable", status)
+		}
+	}
+	for _, status := range []string{models.TravelStatusApproved, models.TravelStatusInProgress, models.TravelStatusCompleted} {
+		if IsEditableTravelStatus(status) {
+			t.Errorf("Expected %s to be locked", status)
+		}
+	}
+}
+
+func TestCheckRequesterUnit(t *testing.T) {
+	requestNumber := "064/0007/DIB/DPEB/NOTA"
+	withFirstEmployee := func(code string) *models.TravelRequest {
+		return &models.TravelRequest{TravelRequestEmployees: []models.TravelRequestEmployee{
+			{Employee: models.Employee{Name: "Budi", Position: models.Position{Code: code}}},
+			{Employee: models.Employee{Name: "Andi", Position: models.Position{Code: "DTI"}}},
+		}}
+	}
+
+	if err := CheckRequesterUnit(requestNumber, withFirstEmployee("DPEB")); err != nil {
+		t.Errorf("Expected an edit within the unit to pass, got %v", err)
+	}
+	if err := CheckRequesterUnit(requestNumber, withFirstEmployee("DTI")); !errors.Is(err, ErrRequesterUnitChanged) {
+		t.Errorf("Expected ErrRequesterUnitChanged, got %v", err)
+	}
+	if err := CheckRequesterUnit("NOTA-LAMA-12", withFirstEmployee("DTI")); err != nil {
+		t.Errorf("Expected numbers without a unit code to be left alone, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"perjalanan-dinas/backend/internal/models"
+	"perjalanan-dinas/backend/internal/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidTravelStatus     = errors.New("invalid travel request status")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrStatusReasonRequired    = errors.New("reason is required for this status")
+	ErrTravelReportFiled       = errors.New("travel report already exists for this request")
+)
+
+// travelStatusTransitions lists the statuses reachable from each status.
+// rejected, completed and cancelled are final.
+var travelStatusTransitions = map[string][]string{
+	models.TravelStatusDraft:      {models.TravelStatusSubmitted, models.TravelStatusCancelled},
+	models.TravelStatusSubmitted:  {models.TravelStatusApproved, models.TravelStatusRejected, models.TravelStatusCancelled},
+	models.TravelStatusApproved:   {models.TravelStatusInProgress, models.TravelStatusCancelled},
+	models.TravelStatusInProgress: {models.TravelStatusCompleted, models.TravelStatusCancelled},
+}
+
+// IsValidTravelStatus reports whether status is a known travel request status
+func IsValidTravelStatus(status string) bool {
+	switch status {
+	case models.TravelStatusDraft, models.TravelStatusSubmitted, models.TravelStatusApproved,
+		models.TravelStatusRejected, models.TravelStatusInProgress, models.TravelStatusCompleted,
+		models.TravelStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTravelStatus reports whether a travel request may move from one status to another
+func CanTransitionTravelStatus(from, to string) bool {
+	for _, next := range travelStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTravelStatusTransitions returns the statuses reachable from the given status
+func AllowedTravelStatusTransitions(from string) []string {
+	return travelStatusTransitions[from]
+}
+
+// ParseTravelStatusFilter parses a comma separated status filter such as "approved,completed"
+func ParseTravelStatusFilter(filter string) ([]string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	var statuses []string
+	for _, status := range strings.Split(filter, ",") {
+		status = strings.TrimSpace(status)
+		if !IsValidTravelStatus(status) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTravelStatus, status)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+type TravelRequestWorkflow struct {
+	repo       *repository.Repository
+	approvals  *ApprovalService
+	suratTugas *SuratTugasService
+}
+
+func NewTravelRequestWorkflow(repo *repository.Repository) *TravelRequestWorkflow {
+	return &TravelRequestWorkflow{
+		repo:       repo,
+		approvals:  NewApprovalService(repo),
+		suratTugas: NewSuratTugasService(repo),
+	}
+}
+
+// Transition moves a travel request to a new status and records who changed it, when and why
+func (w *TravelRequestWorkflow) Transition(id uint, toStatus, actor, reason string) (*models.TravelRequest, error) {
+	err := w.repo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var request models.TravelRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			return err
+		}
+		return w.TransitionTx(tx, &request, toStatus, actor, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.repo.GetTravelRequestByID(id)
+}
+
+// TransitionTx applies a status transition inside the caller's transaction
+func (w *TravelRequestWorkflow) TransitionTx(tx *gorm.DB, request *models.TravelRequest, toStatus, actor, reason string) error {
+	if !IsValidTravelStatus(toStatus) {
+		return fmt.Errorf("%w: %s", ErrInvalidTravelStatus, toStatus)
+	}
+	if !CanTransitionTravelStatus(request.Status, toStatus) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, request.Status, toStatus)
+	}
+	if (toStatus == models.TravelStatusRejected || toStatus == models.TravelStatusCancelled) && strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: %s", ErrStatusReasonRequired, toStatus)
+	}
+
+	switch toStatus {
+	case models.TravelStatusApproved:
+		// Requests with an approval chain are approved by their last approver
+		pending, err := w.approvals.HasPendingTx(tx, models.ApprovalDocTravelRequest, request.ID)
+		if err != nil {
+			return err
+		}
+		if pending {
+			return ErrApprovalPending
+		}
+	case models.TravelStatusSubmitted:
+		if err := w.StartApprovalTx(tx, request); err != nil {
+			return err
+		}
+	case models.TravelStatusRejected, models.TravelStatusCancelled:
+		if err := w.approvals.SkipPendingTx(tx, models.ApprovalDocTravelRequest, request.ID); err != nil {
+			return err
+		}
+		if toStatus == models.TravelStatusCancelled {
+			if err := w.cancelTx(tx, request, actor, reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	fromStatus := request.Status
+	if err := tx.Model(request).Update("status", toStatus).Error; err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	request.Status = toStatus
+
+	// An approved request gets its surat tugas in the same transaction
+	if toStatus == models.TravelStatusApproved {
+		if _, err := w.suratTugas.IssueTx(tx, request, actor); err != nil {
+			return err
+		}
+	}
+
+	return RecordTravelStatusHistory(tx, request.ID, fromStatus, toStatus, actor, reason)
+}
+
+// IsReportableTravelStatus reports whether a berita acara may be filed for a request in status.
+// Filing it completes the trip, so a completed trip has already filed its berita acara.
+func IsReportableTravelStatus(status string) bool {
+	return status == models.TravelStatusApproved || status == models.TravelStatusInProgress
+}
+
+// CompleteTx marks a trip completed inside the caller's transaction, as when its berita acara is
+// filed. An approved trip that was never marked in progress passes through in_progress first, so
+// its history records both steps. A trip already completed is left as it is.
+func (w *TravelRequestWorkflow) CompleteTx(tx *gorm.DB, request *models.TravelRequest, actor, reason string) error {
+	if request.Status == models.TravelStatusCompleted {
+		return nil
+	}
+	if request.Status == models.TravelStatusApproved {
+		if err := w.TransitionTx(tx, request, models.TravelStatusInProgress, actor, reason); err != nil {
+			return err
+		}
+	}
+	return w.TransitionTx(tx, request, models.TravelStatusCompleted, actor, reason)
+}
+
+// StartApprovalTx builds the approval chain of a submitted request from its first employee's position
+func (w *TravelRequestWorkflow) StartApprovalTx(tx *gorm.DB, request *models.TravelRequest) error {
+	var empRel models.TravelRequestEmployee
+	err := tx.Preload("Employee.Position").
+		Where("travel_request_id = ?", request.ID).
+		Order("id ASC").
+		First(&empRel).Error
+	if err != nil {
+		return fmt.Errorf("failed to get requester: %w", err)
+	}
+
+	_, err = w.approvals.StartTx(tx, models.ApprovalDocTravelRequest, request.ID, empRel.Employee.Position, request.TotalAllowance)
+	return err
+}
+
+// RecordTravelStatusHistory writes one status history entry
+func RecordTravelStatusHistory(tx *gorm.DB, requestID uint, fromStatus, toStatus, actor, reason string) error {
+	history := &models.TravelRequestStatusHistory{
+		TravelRequestID: requestID,
+		FromStatus:      fromStatus,
+		ToStatus:        toStatus,
+		ChangedBy:       actor,
+		Reason:          reason,
+		ChangedAt:       time.Now(),
+	}
+	if err := tx.Create(history).Error; err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
+	return nil
+}
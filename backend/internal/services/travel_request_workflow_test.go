@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"perjalanan-dinas/backend/internal/models"
+)
+
+func TestCanTransitionTravelStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{models.TravelStatusDraft, models.TravelStatusSubmitted, true},
+		{models.TravelStatusSubmitted, models.TravelStatusApproved, true},
+		{models.TravelStatusSubmitted, models.TravelStatusRejected, true},
+		{models.TravelStatusApproved, models.TravelStatusInProgress, true},
+		{models.TravelStatusInProgress, models.TravelStatusCompleted, true},
+		{models.TravelStatusInProgress, models.TravelStatusCancelled, true},
+		{models.TravelStatusDraft, models.TravelStatusApproved, false},
+		{models.TravelStatusSubmitted, models.TravelStatusCompleted, false},
+		{models.TravelStatusRejected, models.TravelStatusApproved, false},
+		{models.TravelStatusCompleted, models.TravelStatusCancelled, false},
+		{models.TravelStatusCancelled, models.TravelStatusSubmitted, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitionTravelStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionTravelStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestParseTravelStatusFilter(t *testing.T) {
+	statuses, err := ParseTravelStatusFilter("approved, completed")
+	if err != nil {
+		t.Fatalf("ParseTravelStatusFilter returned error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0] != "approved" || statuses[1] != "completed" {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+
+	if _, err := ParseTravelStatusFilter("approved,pending"); !errors.Is(err, ErrInvalidTravelStatus) {
+		t.Errorf("Expected ErrInvalidTravelStatus for unknown status, got %v", err)
+	}
+}
+
+func TestIsReportableTravelStatus(t *testing.T) {
+	for _, status := range []string{models.TravelStatusApproved, models.TravelStatusInProgress} {
+		if !IsReportableTravelStatus(status) {
+			t.Errorf("Expected a berita acara to be allowed for %s", status)
+		}
+	}
+	for _, status := range []string{models.TravelStatusDraft, models.TravelStatusSubmitted, models.TravelStatusRejected, models.TravelStatusCompleted, models.TravelStatusCancelled} {
+		if IsReportableTravelStatus(status) {
+			t.Errorf("Expected a berita acara to be refused for %s", status)
+		}
+	}
+	// Filing the report of an approved trip passes through in_progress
+	if !CanTransitionTravelStatus(models.TravelStatusApproved, models.TravelStatusInProgress) ||
+		!CanTransitionTravelStatus(models.TravelStatusInProgress, models.TravelStatusCompleted) {
+		t.Error("Expected approved -> in_progress -> completed to be allowed")
+	}
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Zona waktu tetap tersedia di image tanpa /usr/share/zoneinfo
+)
+
+// DefaultTimeZone is the zone of places without a known time zone
+const DefaultTimeZone = "Asia/Jakarta"
+
+// DomesticCountryCode is the country of the agency; trips to another country are abroad
+const DomesticCountryCode = "ID"
+
+// timeZones caches the loaded locations by IANA name
+var timeZones sync.Map
+
+// JakartaLocation returns the Asia/Jakarta (WIB) time zone used for document dates
+func JakartaLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+// TimeZone loads the IANA time zone of the given name, e.g. Asia/Makassar. An empty or unknown name is
+// Asia/Jakarta (WIB).
+func TimeZone(name string) *time.Location {
+	if name == "" || name == DefaultTimeZone {
+		return JakartaLocation()
+	}
+	if loc, ok := timeZones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return JakartaLocation()
+	}
+	timeZones.Store(name, loc)
+	return loc
+}
+
+// InTimeZone returns the instant t in the named time zone
+func InTimeZone(t time.Time, name string) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(TimeZone(name))
+}
+
+// provinceTimeZones maps the BPS codes of provinces outside WIB to their time zone
+var provinceTimeZones = map[string]string{
+	// WITA
+	"51": "Asia/Makassar", "52": "Asia/Makassar", "53": "Asia/Makassar",
+	"63": "Asia/Makassar", "64": "Asia/Makassar", "65": "Asia/Makassar",
+	"71": "Asia/Makassar", "72": "Asia/Makassar", "73": "Asia/Makassar",
+	"74": "Asia/Makassar", "75": "Asia/Makassar", "76": "Asia/Makassar",
+	// WIT
+	"81": "Asia/Jayapura", "82": "Asia/Jayapura",
+	"91": "Asia/Jayapura", "92": "Asia/Jayapura", "93": "Asia/Jayapura",
+	"94": "Asia/Jayapura", "95": "Asia/Jayapura", "96": "Asia/Jayapura",
+}
+
+// countryTimeZones maps ISO country codes to the time zone of their capital or main business
+// city. Cities of countries spanning several zones set their own zone in the city master.
+var countryTimeZones = map[string]string{
+	"SG": "Asia/Singapore",
+	"MY": "Asia/Kuala_Lumpur",
+	"TH": "Asia/Bangkok",
+	"PH": "Asia/Manila",
+	"VN": "Asia/Ho_Chi_Minh",
+	"MM": "Asia/Yangon",
+	"KH": "Asia/Phnom_Penh",
+	"LA": "Asia/Vientiane",
+	"BN": "Asia/Brunei",
+	"TL": "Asia/Dili",
+	"JP": "Asia/Tokyo",
+	"KR": "Asia/Seoul",
+	"CN": "Asia/Shanghai",
+	"HK": "Asia/Hong_Kong",
+	"TW": "Asia/Taipei",
+	"IN": "Asia/Kolkata",
+	"BD": "Asia/Dhaka",
+	"PK": "Asia/Karachi",
+	"LK": "Asia/Colombo",
+	"AE": "Asia/Dubai",
+	"SA": "Asia/Riyadh",
+	"QA": "Asia/Qatar",
+	"KW": "Asia/Kuwait",
+	"OM": "Asia/Muscat",
+	"GB": "Europe/London",
+	"FR": "Europe/Paris",
+	"DE": "Europe/Berlin",
+	"NL": "Europe/Amsterdam",
+	"BE": "Europe/Brussels",
+	"IT": "Europe/Rome",
+	"ES": "Europe/Madrid",
+	"RU": "Europe/Moscow",
+	"AT": "Europe/Vienna",
+	"CH": "Europe/Zurich",
+	"US": "America/New_York",
+	"CA": "America/Toronto",
+	"MX": "America/Mexico_City",
+	"BR": "America/Sao_Paulo",
+	"AR": "America/Argentina/Buenos_Aires",
+	"AU": "Australia/Sydney",
+	"NZ": "Pacific/Auckland",
+	"EG": "Africa/Cairo",
+	"ZA": "Africa/Johannesburg",
+	"KE": "Africa/Nairobi",
+	"NG": "Africa/Lagos",
+}
+
+// DefaultPlaceTimeZone returns the time zone of a place from its BPS province code or, abroad,
+// its ISO country code. Unknown places are in WIB.
+func DefaultPlaceTimeZone(provinceCode, countryCode string) string {
+	if strings.EqualFold(countryCode, DomesticCountryCode) || countryCode == "" {
+		if zone, ok := provinceTimeZones[provinceCode]; ok {
+			return zone
+		}
+		return DefaultTimeZone
+	}
+	if zone, ok := countryTimeZones[strings.ToUpper(countryCode)]; ok {
+		return zone
+	}
+	return DefaultTimeZone
+}
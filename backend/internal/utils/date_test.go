@@ -0,0 +1,20 @@
+package utils
+
+import "testing"
+
+func TestDefaultPlaceTimeZone(t *testing.T) {
+	cases := []struct {
+		province, country, want string
+	}{
+		{"35", "ID", "Asia/Jakarta"},
+		{"73", "ID", "Asia/Makassar"},
+		{"91", "ID", "Asia/Jayapura"},
+		{"", "JP", "Asia/Tokyo"},
+		{"", "XX", "Asia/Jakarta"},
+	}
+	for _, tc := range cases {
+		if got := DefaultPlaceTimeZone(tc.province, tc.country); got != tc.want {
+			t.Errorf("DefaultPlaceTimeZone(%q, %q) = %q, want %q", tc.province, tc.country, got, tc.want)
+		}
+	}
+}
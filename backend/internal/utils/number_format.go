@@ -10,20 +10,62 @@ func FormatSequence(num int) string {
 	return fmt.Sprintf("%04d", num)
 }
 
-// GenerateRequestNumber formats request number: 064/{seq}/DIB/{code}/NOTA
-func GenerateRequestNumber(seq int, positionCode string) string {
-	return fmt.Sprintf("064/%s/DIB/%s/NOTA", FormatSequence(seq), positionCode)
+// GenerateRequestNumber formats request number: 064/{seq}/DIB/{code}/NOTA/{year}
+// Agendas restart every year, so the year keeps the numbers of different years apart.
+func GenerateRequestNumber(seq int, positionCode string, year int) string {
+	return fmt.Sprintf("064/%s/DIB/%s/NOTA/%d", FormatSequence(seq), positionCode, year)
 }
 
-// GenerateReportNumber formats report number: 064/{seq}/DIB/{code}/NOTA
-func GenerateReportNumber(seq int, positionCode string) string {
-	return fmt.Sprintf("064/%s/DIB/%s/NOTA", FormatSequence(seq), positionCode)
+// GenerateReportNumber formats report number: 064/{seq}/DIB/{code}/NOTA/{year}
+func GenerateReportNumber(seq int, positionCode string, year int) string {
+	return fmt.Sprintf("064/%s/DIB/%s/NOTA/%d", FormatSequence(seq), positionCode, year)
+}
+
+// GenerateClaimNumber formats at-cost claim number: 064/{seq}/DIB/{code}/KLAIM/{year}
+func GenerateClaimNumber(seq int, positionCode string, year int) string {
+	return fmt.Sprintf("064/%s/DIB/%s/KLAIM/%d", FormatSequence(seq), positionCode, year)
+}
+
+// WithRevision appends the revision suffix to a document number: 064/{seq}/DIB/{code}/NOTA/R{rev}
+// Revision 0 is the original document and has no suffix
+func WithRevision(number string, revision int) string {
+	if revision <= 0 {
+		return number
+	}
+	return fmt.Sprintf("%s/R%d", number, revision)
+}
+
+// GenerateSuratTugasNumber formats surat tugas number: 064/{seq}/DIB/{code}/ST/{year}
+func GenerateSuratTugasNumber(seq int, positionCode string, year int) string {
+	return fmt.Sprintf("064/%s/DIB/%s/ST/%d", FormatSequence(seq), positionCode, year)
+}
+
+// GenerateAmendmentNumber formats the number of an amendment letter: {request number}/ADD-{n}
+func GenerateAmendmentNumber(requestNumber string, amendmentNumber int) string {
+	return fmt.Sprintf("%s/ADD-%d", requestNumber, amendmentNumber)
+}
+
+// GenerateCancellationNumber formats the number of a cancellation letter: {request number}/BTL
+func GenerateCancellationNumber(requestNumber string) string {
+	return requestNumber + "/BTL"
+}
+
+// GenerateAdvanceSettlementNumber formats the number of an advance settlement: {request number}/PUM
+func GenerateAdvanceSettlementNumber(requestNumber string) string {
+	return requestNumber + "/PUM"
+}
+
+// GenerateKuitansiNumber formats the number of the kuitansi of an employee on a request:
+// {request number}/KWT-{employee ID}
+func GenerateKuitansiNumber(requestNumber string, employeeID uint) string {
+	return fmt.Sprintf("%s/KWT-%d", requestNumber, employeeID)
 }
 
 // ExtractPositionCodeFromRequestNumber extracts position code from request number
-// Example: "064/0325/DIB/DPEB/NOTA" -> "DPEB"
+// Example: "064/0325/DIB/DPEB/NOTA/2025" -> "DPEB", also for surat tugas (/ST) and claim (/KLAIM)
+// numbers and for numbers given before the year was added
 func ExtractPositionCodeFromRequestNumber(requestNumber string) string {
-	re := regexp.MustCompile(`/DIB/([A-Z]+)/NOTA`)
+	re := regexp.MustCompile(`/DIB/([A-Z]+)/(?:NOTA|ST|KLAIM)`)
 	matches := re.FindStringSubmatch(requestNumber)
 	if len(matches) > 1 {
 		return matches[1]
@@ -115,7 +115,7 @@ export default function PositionCodesPage() {
                     <p className="mt-1 text-sm text-red-600">{errors.code.message}</p>
                   )}
                   <p className="mt-1 text-xs text-gray-500">
-                    Kode ini akan digunakan dalam format nomor: 064/xxxx/DIB/[KODE]/NOTA
+                    Kode ini akan digunakan dalam format nomor: 064/xxxx/DIB/[KODE]/NOTA/[TAHUN]
                   </p>
                 </div>
 
@@ -174,7 +174,7 @@ export default function PositionCodesPage() {
                           </span>
                         </td>
                         <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-600 font-mono">
-                          064/xxxx/DIB/{pc.code}/NOTA
+                          064/xxxx/DIB/{pc.code}/NOTA/[TAHUN]
                         </td>
                         <td className="px-6 py-4 whitespace-nowrap text-sm">
                           <button